@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,11 +18,16 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
 	"github.com/SecurityByDesign/pwmanager/internal/auth"
 	"github.com/SecurityByDesign/pwmanager/internal/config"
 	"github.com/SecurityByDesign/pwmanager/internal/handlers"
+	"github.com/SecurityByDesign/pwmanager/internal/jobs"
 	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
 	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/SecurityByDesign/pwmanager/internal/storage"
 	"github.com/SecurityByDesign/pwmanager/pkg/crypto"
 
 	_ "github.com/SecurityByDesign/pwmanager/docs" // Import generated docs
@@ -109,12 +116,50 @@ func main() {
 	}
 	logger.Info("Connected to Redis")
 
+	quota := repository.Quota{
+		MaxEntrySize:       cfg.Quota.MaxEntrySize,
+		MaxEntriesPerVault: cfg.Quota.MaxEntriesPerVault,
+		MaxVaultsPerUser:   cfg.Quota.MaxVaultsPerUser,
+		MaxUserStorage:     cfg.Quota.MaxUserStorage,
+	}
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
-	vaultRepo := repository.NewVaultRepository(db)
-	entryRepo := repository.NewEntryRepository(db)
+	vaultRepo := repository.NewVaultRepository(db, quota)
+	entryRepo := repository.NewEntryRepository(db, quota)
+	entryVersionRepo := repository.NewEntryVersionRepository(db)
+	syncRepo := repository.NewSyncRepository(db)
 	auditRepo := repository.NewAuditRepository(db)
+	auditOutboxRepo := repository.NewAuditOutboxRepository(db)
+	attachmentRepo := repository.NewAttachmentRepository(db, quota)
+	folderRepo := repository.NewFolderRepository(db)
+	tagRepo := repository.NewTagRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	autofillRepo := repository.NewAutofillRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	shareRepo := repository.NewShareRepository(db)
+	emergencyRepo := repository.NewEmergencyAccessRepository(db)
 	mfaRepo := repository.NewMFARepository(db)
+	usageRepo := repository.NewUsageRepository(db)
+
+	// Initialize blob storage for attachments
+	var blobStore storage.BlobStore
+	switch cfg.Storage.Backend {
+	case "s3":
+		blobStore, err = storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.Storage.S3Endpoint,
+			Region:    cfg.Storage.S3Region,
+			Bucket:    cfg.Storage.S3Bucket,
+			AccessKey: cfg.Storage.S3AccessKey,
+			SecretKey: cfg.Storage.S3SecretKey,
+			PathStyle: cfg.Storage.S3PathStyle,
+		})
+	default:
+		blobStore, err = storage.NewLocalStore(cfg.Storage.LocalPath)
+	}
+	if err != nil {
+		logger.Fatal("Failed to initialize blob storage", zap.Error(err))
+	}
 
 	// Initialize session manager
 	sessionManager := auth.NewSessionManager(
@@ -132,21 +177,143 @@ func main() {
 		KeyLength:   cfg.Argon2.KeyLength,
 	}
 
+	// Initialize audit checkpoint signing key (checkpoints are disabled without it)
+	var auditSigningKey ed25519.PrivateKey
+	var auditPublicKey ed25519.PublicKey
+	if cfg.Audit.SigningKey != "" {
+		auditSigningKey, err = audit.ParseSigningKey(cfg.Audit.SigningKey)
+		if err != nil {
+			logger.Fatal("Invalid AUDIT_SIGNING_KEY", zap.Error(err))
+		}
+		auditPublicKey = auditSigningKey.Public().(ed25519.PublicKey)
+		logger.Info("Audit checkpoint signing enabled", zap.String("public_key", hex.EncodeToString(auditPublicKey)))
+	} else {
+		logger.Warn("AUDIT_SIGNING_KEY not set, audit checkpoints are disabled")
+	}
+
+	// Initialize audit sinks (forwarding is disabled without any)
+	var auditSinks []audit.Sink
+	if cfg.Audit.SyslogAddress != "" {
+		syslogSink, err := audit.NewSyslogSink(audit.SyslogConfig{
+			Network: cfg.Audit.SyslogNetwork,
+			Address: cfg.Audit.SyslogAddress,
+			CAFile:  cfg.Audit.SyslogCAFile,
+		})
+		if err != nil {
+			logger.Fatal("Failed to initialize syslog audit sink", zap.Error(err))
+		}
+		auditSinks = append(auditSinks, syslogSink)
+	}
+	if cfg.Audit.WebhookURL != "" {
+		webhookSink, err := audit.NewWebhookSink(audit.WebhookConfig{
+			URL:    cfg.Audit.WebhookURL,
+			Secret: cfg.Audit.WebhookSecret,
+		})
+		if err != nil {
+			logger.Fatal("Failed to initialize webhook audit sink", zap.Error(err))
+		}
+		auditSinks = append(auditSinks, webhookSink)
+	}
+
+	auditSinkNames := make([]string, 0, len(auditSinks))
+	for _, sink := range auditSinks {
+		auditSinkNames = append(auditSinkNames, sink.Name())
+	}
+	if err := auditOutboxRepo.SetSinks(context.Background(), auditSinkNames); err != nil {
+		logger.Fatal("Failed to register audit sinks", zap.Error(err))
+	}
+	logger.Info("Audit forwarding configured", zap.Strings("sinks", auditSinkNames))
+
+	// Every audit event type must map to exactly one well-formed action
+	auditCatalog, err := audit.DefaultCatalog()
+	if err != nil {
+		logger.Fatal("Invalid audit event catalog", zap.Error(err))
+	}
+
+	// Stored audit events are pushed to users' live streams across replicas
+	auditStream := audit.NewStream(redisClient, logger)
+
+	// Initialize the asynchronous audit writer; it is flushed on shutdown
+	auditWriter := audit.NewWriter(auditStream.Store(auditRepo), auditRepo, audit.WriterConfig{
+		QueueSize:     cfg.Audit.QueueSize,
+		BatchSize:     cfg.Audit.BatchSize,
+		FlushInterval: time.Duration(cfg.Audit.FlushInterval) * time.Millisecond,
+		MaxRetries:    cfg.Audit.WriteRetries,
+		SpillPath:     cfg.Audit.SpillPath,
+	}, logger)
+	go auditWriter.Run()
+
+	// Security middleware events are aggregated so floods cannot flood the audit trail
+	securityAuditor := audit.NewThrottledRecorder(auditWriter,
+		time.Duration(cfg.Audit.SecurityWindow)*time.Second, cfg.Audit.SecurityMaxKeys)
+
+	pageLimits := pagination.Limits{
+		Default: cfg.Pagination.DefaultLimit,
+		Max:     cfg.Pagination.MaxLimit,
+	}
+
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(userRepo, mfaRepo, auditRepo, sessionManager, argon2Params, cfg.Security.MasterEncryptionKey, cfg, logger)
-	vaultHandler := handlers.NewVaultHandler(vaultRepo, entryRepo, auditRepo, logger)
-	entryHandler := handlers.NewEntryHandler(entryRepo, vaultRepo, auditRepo, logger)
-	auditHandler := handlers.NewAuditHandler(auditRepo, logger)
+	authHandler := handlers.NewAuthHandler(userRepo, mfaRepo, auditWriter, sessionManager, argon2Params, cfg.Security.MasterEncryptionKey, cfg, logger)
+	vaultHandler := handlers.NewVaultHandler(vaultRepo, entryRepo, auditWriter, pageLimits, logger)
+	entryHandler := handlers.NewEntryHandler(entryRepo, vaultRepo, auditWriter, pageLimits, logger)
+	entryBatchHandler := handlers.NewEntryBatchHandler(entryRepo, auditWriter, cfg.Batch.MaxItems, logger)
+	entryVersionHandler := handlers.NewEntryVersionHandler(entryVersionRepo, entryRepo, vaultRepo, auditWriter, logger)
+	auditHandler := handlers.NewAuditHandler(auditRepo, auditWriter, auditStream, auditCatalog, pageLimits, auditPublicKey, logger)
+	syncHandler := handlers.NewSyncHandler(syncRepo, entryRepo, auditWriter, pageLimits, logger)
+	trashRetention := time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour
+	folderHandler := handlers.NewFolderHandler(folderRepo, entryRepo, vaultRepo, auditWriter, logger)
+	tagHandler := handlers.NewTagHandler(tagRepo, entryRepo, vaultRepo, auditWriter, logger)
+	searchHandler := handlers.NewSearchHandler(searchRepo, entryRepo, vaultRepo, auditWriter, cfg.Search, logger)
+	autofillHandler := handlers.NewAutofillHandler(autofillRepo, entryRepo, vaultRepo, auditWriter, logger)
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenRepo, auditWriter, logger)
+	shareHandler := handlers.NewShareHandler(shareRepo, auditWriter, argon2Params, cfg.Share, logger)
+	emergencyHandler := handlers.NewEmergencyAccessHandler(emergencyRepo, vaultRepo, entryRepo, auditWriter, pageLimits, logger)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentRepo, entryRepo, vaultRepo, auditWriter, blobStore,
+		cfg.Attachment.MaxSize, logger)
+	usageHandler := handlers.NewUsageHandler(usageRepo, quota, logger)
+	trashHandler := handlers.NewTrashHandler(vaultRepo, entryRepo, auditWriter, trashRetention, logger)
+
+	// Start background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	trashPurger := jobs.NewTrashPurger(vaultRepo, entryRepo, attachmentRepo, auditWriter, blobStore, trashRetention,
+		time.Duration(cfg.Trash.PurgeInterval)*time.Second, logger)
+	go trashPurger.Run(jobsCtx)
+
+	sharePurger := jobs.NewSharePurger(shareRepo, auditWriter, time.Duration(cfg.Share.PurgeInterval)*time.Second, logger)
+	go sharePurger.Run(jobsCtx)
+
+	emergencyReleaser := jobs.NewEmergencyAccessReleaser(emergencyRepo, auditWriter,
+		time.Duration(cfg.Emergency.CheckInterval)*time.Second, logger)
+	go emergencyReleaser.Run(jobsCtx)
+
+	go securityAuditor.Run(jobsCtx)
+
+	// Stopping the stream ends open event streams so the server can shut down
+	go auditStream.Run(jobsCtx)
+
+	if auditSigningKey != nil {
+		auditCheckpointer := jobs.NewAuditCheckpointer(auditRepo, auditSigningKey,
+			time.Duration(cfg.Audit.CheckpointInterval)*time.Second, logger)
+		go auditCheckpointer.Run(jobsCtx)
+	}
+
+	if len(auditSinks) > 0 {
+		auditDispatcher := jobs.NewAuditDispatcher(auditOutboxRepo, auditSinks, cfg.Audit.OutboxBatchSize,
+			time.Duration(cfg.Audit.OutboxInterval)*time.Second, logger)
+		go auditDispatcher.Run(jobsCtx)
+	}
 
 	// Setup Gin
 	gin.SetMode(cfg.Server.GinMode)
 	router := gin.New()
 
 	// Global middleware
-	router.Use(middleware.RecoveryMiddleware(logger))
+	router.Use(middleware.RecoveryMiddleware(logger, securityAuditor))
 	router.Use(middleware.LoggingMiddleware(logger))
 	router.Use(middleware.CORSMiddleware(cfg.CORS.AllowedOrigins))
-	router.Use(middleware.RateLimitMiddleware(redisClient, cfg.RateLimit.RequestsPerMinute, "limiter_global"))
+	router.Use(middleware.RateLimitMiddleware(redisClient, cfg.RateLimit.RequestsPerMinute, "limiter_global", securityAuditor))
 
 	// Swagger Documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -161,7 +328,7 @@ func main() {
 	{
 		// Auth routes (public)
 		auth := api.Group("/auth")
-		auth.Use(middleware.StrictRateLimitMiddleware(redisClient, cfg.RateLimit.AuthRequestsPerMinute, "limiter_auth"))
+		auth.Use(middleware.StrictRateLimitMiddleware(redisClient, cfg.RateLimit.AuthRequestsPerMinute, "limiter_auth", securityAuditor))
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
@@ -169,14 +336,14 @@ func main() {
 
 		// Auth routes (protected)
 		authProtected := api.Group("/auth")
-		authProtected.Use(middleware.AuthMiddleware(sessionManager))
+		authProtected.Use(middleware.AuthMiddleware(sessionManager, securityAuditor))
 		{
 			authProtected.GET("/me", authHandler.Me)
 			authProtected.GET("/csrf", authHandler.GetCSRFToken)
 
 			// CSRF protected routes
 			authCSRF := authProtected.Group("")
-			authCSRF.Use(middleware.CSRFMiddleware())
+			authCSRF.Use(middleware.CSRFMiddleware(securityAuditor))
 			{
 				authCSRF.POST("/logout", authHandler.Logout)
 				authCSRF.POST("/change-password", authHandler.ChangePassword)
@@ -186,10 +353,25 @@ func main() {
 			}
 		}
 
+		// Share link retrieval (public, rate limited per client)
+		publicShares := api.Group("/public/shares")
+		publicShares.Use(middleware.StrictRateLimitMiddleware(redisClient, cfg.Share.AccessRequestsPerMinute, "limiter_share", securityAuditor))
+		{
+			publicShares.GET("/:id", shareHandler.Info)
+			publicShares.POST("/:id", shareHandler.Access)
+		}
+
+		// Autofill lookup (API token with autofill scope)
+		autofill := api.Group("/autofill")
+		autofill.Use(middleware.APITokenMiddleware(apiTokenRepo, models.ScopeAutofill))
+		{
+			autofill.POST("/lookup", autofillHandler.Lookup)
+		}
+
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(sessionManager))
-		protected.Use(middleware.CSRFMiddleware())
+		protected.Use(middleware.AuthMiddleware(sessionManager, securityAuditor))
+		protected.Use(middleware.CSRFMiddleware(securityAuditor))
 		{
 			// Vault routes
 			vaults := protected.Group("/vaults")
@@ -199,10 +381,20 @@ func main() {
 				vaults.GET("/:id", vaultHandler.Get)
 				vaults.PUT("/:id", vaultHandler.Update)
 				vaults.DELETE("/:id", vaultHandler.Delete)
+				vaults.POST("/:id/rotate-key", vaultHandler.RotateKey)
+				vaults.POST("/:id/kdf", vaultHandler.UpgradeKDF)
 
 				// Entry routes nested under vaults
 				vaults.POST("/:id/entries", entryHandler.Create)
 				vaults.GET("/:id/entries", entryHandler.List)
+				vaults.POST("/:id/entries/batch", entryBatchHandler.Apply)
+
+				// Folder and tag routes nested under vaults
+				vaults.POST("/:id/folders", folderHandler.Create)
+				vaults.GET("/:id/folders", folderHandler.List)
+				vaults.POST("/:id/tags", tagHandler.Create)
+				vaults.GET("/:id/tags", tagHandler.List)
+				vaults.POST("/:id/search", searchHandler.Search)
 			}
 
 			// Entry routes (by ID)
@@ -211,6 +403,92 @@ func main() {
 				entries.GET("/:id", entryHandler.Get)
 				entries.PUT("/:id", entryHandler.Update)
 				entries.DELETE("/:id", entryHandler.Delete)
+				entries.POST("/:id/move", entryHandler.Move)
+				entries.POST("/:id/copy", entryHandler.Copy)
+				entries.PUT("/:id/favorite", entryHandler.SetFavorite)
+				entries.PUT("/:id/folder", folderHandler.MoveEntry)
+				entries.PUT("/:id/tags", tagHandler.SetEntryTags)
+				entries.PUT("/:id/search-tokens", searchHandler.SetEntryTokens)
+				entries.PUT("/:id/autofill", autofillHandler.SetEntryHashes)
+
+				// Entry version history
+				entries.GET("/:id/versions", entryVersionHandler.List)
+				entries.GET("/:id/versions/:version", entryVersionHandler.Get)
+				entries.POST("/:id/versions/:version/restore", entryVersionHandler.Restore)
+
+				// Attachment routes nested under entries
+				entries.POST("/:id/attachments", attachmentHandler.Upload)
+				entries.GET("/:id/attachments", attachmentHandler.List)
+			}
+
+			// Folder routes (by ID)
+			folders := protected.Group("/folders")
+			{
+				folders.PUT("/:id", folderHandler.Rename)
+				folders.PUT("/:id/parent", folderHandler.Move)
+				folders.DELETE("/:id", folderHandler.Delete)
+			}
+
+			// Tag routes (by ID)
+			tags := protected.Group("/tags")
+			{
+				tags.DELETE("/:id", tagHandler.Delete)
+			}
+
+			// Attachment routes (by ID)
+			attachments := protected.Group("/attachments")
+			{
+				attachments.GET("/:id", attachmentHandler.Download)
+				attachments.DELETE("/:id", attachmentHandler.Delete)
+			}
+
+			// Share link management
+			shares := protected.Group("/shares")
+			{
+				shares.GET("", shareHandler.List)
+				shares.POST("", shareHandler.Create)
+				shares.DELETE("/:id", shareHandler.Revoke)
+			}
+
+			// Emergency access
+			emergency := protected.Group("/emergency-access")
+			{
+				emergency.POST("", emergencyHandler.Invite)
+				emergency.GET("/granted", emergencyHandler.ListGranted)
+				emergency.GET("/trusted", emergencyHandler.ListTrusted)
+				emergency.PUT("/:id", emergencyHandler.Update)
+				emergency.DELETE("/:id", emergencyHandler.Delete)
+				emergency.POST("/:id/accept", emergencyHandler.Accept)
+				emergency.POST("/:id/request", emergencyHandler.RequestRecovery)
+				emergency.POST("/:id/approve", emergencyHandler.ApproveRecovery)
+				emergency.POST("/:id/reject", emergencyHandler.RejectRecovery)
+				emergency.GET("/:id/key", emergencyHandler.GetKey)
+				emergency.GET("/:id/vaults", emergencyHandler.ListVaults)
+				emergency.GET("/:id/vaults/:vault_id/entries", emergencyHandler.ListEntries)
+			}
+
+			// API token management
+			tokens := protected.Group("/tokens")
+			{
+				tokens.GET("", apiTokenHandler.List)
+				tokens.POST("", apiTokenHandler.Create)
+				tokens.DELETE("/:id", apiTokenHandler.Revoke)
+			}
+
+			// Delta sync
+			protected.GET("/sync", syncHandler.Sync)
+
+			// Storage usage
+			protected.GET("/usage", usageHandler.Get)
+
+			// Trash routes
+			trash := protected.Group("/trash")
+			{
+				trash.GET("", trashHandler.List)
+				trash.POST("/vaults/:id/restore", trashHandler.RestoreVault)
+				trash.DELETE("/vaults/:id", trashHandler.PurgeVault)
+				trash.POST("/entries/:id/restore", trashHandler.RestoreEntry)
+				trash.DELETE("/entries/:id", trashHandler.PurgeEntry)
 			}
 
 			// Audit routes
@@ -218,6 +496,18 @@ func main() {
 			{
 				audit.GET("/logs", auditHandler.List)
 				audit.GET("/logs/:id", auditHandler.Get)
+				audit.GET("/export", auditHandler.Export)
+				audit.GET("/stream", auditHandler.Stream)
+				audit.GET("/schema", auditHandler.Schema)
+			}
+
+			// Admin routes
+			admin := protected.Group("/admin")
+			admin.Use(middleware.AdminMiddleware(userRepo))
+			{
+				admin.GET("/audit/verify", auditHandler.Verify)
+				admin.GET("/audit/export", auditHandler.ExportAll)
+				admin.GET("/audit/metrics", auditHandler.Metrics)
 			}
 		}
 	}
@@ -255,13 +545,26 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
+	// Stop background jobs
+	stopJobs()
+
 	// Graceful shutdown with 5 second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown", zap.Error(err))
+		logger.Error("Server forced to shutdown", zap.Error(err))
+	}
+
+	// Flush queued audit events after the last request has finished
+	securityAuditor.Flush()
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFlush()
+
+	if err := auditWriter.Close(flushCtx); err != nil {
+		logger.Error("Failed to flush audit events", zap.Error(err))
 	}
+	logger.Info("Audit writer flushed", zap.Any("metrics", auditWriter.Metrics()))
 
 	logger.Info("Server exited")
 }
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+)
+
+func main() {
+	// Load .env file if it exists
+	_ = godotenv.Load()
+
+	// Get command from arguments
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+
+	switch command {
+	case "verify":
+		os.Exit(verify())
+
+	default:
+		log.Printf("Unknown command: %s\n", command)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+// verify walks the audit hash chain, prints the report as JSON and returns
+// the exit code: 0 if the chain is intact, 2 if it is broken
+func verify() int {
+	databaseURL := getEnv("DATABASE_URL")
+	if databaseURL == "" {
+		log.Fatal("DATABASE_URL environment variable is required")
+	}
+
+	publicKey, err := verificationKey()
+	if err != nil {
+		log.Fatalf("Invalid verification key: %v", err)
+	}
+	if publicKey == nil {
+		log.Println("Warning: no AUDIT_PUBLIC_KEY or AUDIT_SIGNING_KEY set, checkpoint signatures are not verified")
+	}
+
+	db, err := sqlx.Connect("postgres", databaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	result, err := audit.Verify(context.Background(), repository.NewAuditRepository(db), publicKey)
+	if err != nil {
+		log.Fatalf("Failed to verify audit chain: %v", err)
+	}
+
+	report, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode report: %v", err)
+	}
+	fmt.Println(string(report))
+
+	if !result.Valid {
+		return 2
+	}
+	return 0
+}
+
+// verificationKey returns the checkpoint public key from AUDIT_PUBLIC_KEY,
+// or derives it from AUDIT_SIGNING_KEY. It returns nil if neither is set.
+func verificationKey() (ed25519.PublicKey, error) {
+	if hexKey := getEnv("AUDIT_PUBLIC_KEY"); hexKey != "" {
+		return audit.ParsePublicKey(hexKey)
+	}
+
+	if hexSeed := getEnv("AUDIT_SIGNING_KEY"); hexSeed != "" {
+		signingKey, err := audit.ParseSigningKey(hexSeed)
+		if err != nil {
+			return nil, err
+		}
+		return signingKey.Public().(ed25519.PublicKey), nil
+	}
+
+	return nil, nil
+}
+
+func printUsage() {
+	fmt.Println("Usage: audit <command>")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  verify     Walk the audit hash chain and report the first break (exit code 2 if broken)")
+	fmt.Println()
+	fmt.Println("Environment variables:")
+	fmt.Println("  DATABASE_URL       PostgreSQL connection string (required)")
+	fmt.Println("  AUDIT_PUBLIC_KEY   Hex-encoded Ed25519 public key for checkpoint signatures")
+	fmt.Println("  AUDIT_SIGNING_KEY  Signing key seed; used to derive the public key if AUDIT_PUBLIC_KEY is not set")
+}
+
+// getEnv gets an environment variable.
+// It also checks for key + "_FILE" to read from a file (useful for Docker secrets)
+func getEnv(key string) string {
+	// Check for _FILE variant
+	if filePath := os.Getenv(key + "_FILE"); filePath != "" {
+		content, err := os.ReadFile(filePath)
+		if err == nil {
+			// Trim whitespace (newlines) that might be in the file
+			return string(bytes.TrimSpace(content))
+		}
+		log.Printf("Failed to read file %s for env %s: %v", filePath, key, err)
+	}
+
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+)
+
+func main() {
+	// Load .env file if it exists
+	_ = godotenv.Load()
+
+	// Get command from arguments
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+
+	switch command {
+	case "purge-audit":
+		// Delete audit logs past the retention period
+		if err := purgeAudit(); err != nil {
+			log.Fatalf("Failed to purge audit logs: %v", err)
+		}
+
+	case "partition-audit":
+		// Create the audit log partitions of the coming months
+		if err := partitionAudit(); err != nil {
+			log.Fatalf("Failed to create audit partitions: %v", err)
+		}
+
+	case "audit-schema":
+		// Print the JSON Schema of audit events for log consumers
+		if err := printAuditSchema(); err != nil {
+			log.Fatalf("Failed to print audit event schema: %v", err)
+		}
+
+	default:
+		log.Printf("Unknown command: %s\n", command)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+// purgeAudit drops the monthly audit log partitions that ended more than
+// AUDIT_RETENTION_DAYS ago, so logs are kept for at least the retention period.
+// Each partition is first archived to a compressed file in AUDIT_ARCHIVE_DIR
+// if it is set. It must run as a member of the pwmanager_maintenance
+// role; the database rejects dropping partitions from any other role. The new
+// chain head is checkpointed when a signing key is set.
+func purgeAudit() error {
+	databaseURL := getEnv("MAINTENANCE_DATABASE_URL")
+	if databaseURL == "" {
+		return fmt.Errorf("MAINTENANCE_DATABASE_URL environment variable is required")
+	}
+
+	retentionDays := 365
+	if value := getEnv("AUDIT_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			return fmt.Errorf("AUDIT_RETENTION_DAYS must be at least 1")
+		}
+		retentionDays = days
+	}
+	archiveDir := getEnv("AUDIT_ARCHIVE_DIR")
+
+	db, err := sqlx.Connect("postgres", databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx := context.Background()
+	auditRepo := repository.NewAuditRepository(db)
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	partitions, err := auditRepo.ListPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var expired []models.AuditPartition
+	for _, partition := range partitions {
+		if !partition.To.After(cutoff) {
+			expired = append(expired, partition)
+		}
+	}
+	if len(expired) == 0 {
+		log.Printf("No audit partitions ended before %s\n", cutoff.UTC().Format(time.RFC3339))
+		return nil
+	}
+
+	var archived map[string]int64
+	if archiveDir != "" {
+		if err := os.MkdirAll(archiveDir, 0o700); err != nil {
+			return fmt.Errorf("failed to create archive directory: %w", err)
+		}
+
+		archived = make(map[string]int64, len(expired))
+		for _, partition := range expired {
+			path := filepath.Join(archiveDir, partition.Name+".ndjson.gz")
+			rows, err := archivePartition(ctx, auditRepo, partition, path)
+			if err != nil {
+				return err
+			}
+			archived[partition.Name] = rows
+			log.Printf("Archived %d audit logs of %s to %s\n", rows, partition.Name, path)
+		}
+	}
+
+	dropped, rows, err := auditRepo.DropPartitions(ctx, expired, archived, "job/maintenance")
+	if err != nil {
+		return err
+	}
+	for _, partition := range dropped {
+		log.Printf("Dropped audit partition %s\n", partition.Name)
+	}
+	if len(dropped) < len(expired) {
+		log.Printf("Kept %d expired audit partitions; they hold logs chained after logs of a newer partition and are dropped together with it\n",
+			len(expired)-len(dropped))
+	}
+	log.Printf("Purged %d audit logs before %s\n", rows, cutoff.UTC().Format(time.RFC3339))
+
+	hexSeed := getEnv("AUDIT_SIGNING_KEY")
+	if rows == 0 || hexSeed == "" {
+		return nil
+	}
+
+	signingKey, err := audit.ParseSigningKey(hexSeed)
+	if err != nil {
+		return fmt.Errorf("invalid AUDIT_SIGNING_KEY: %w", err)
+	}
+
+	head, err := auditRepo.GetChainHead(ctx)
+	if err != nil || head == nil {
+		return err
+	}
+
+	checkpoint := audit.NewCheckpoint(signingKey, head.ID, head.Hash)
+	if err := auditRepo.CreateCheckpoint(ctx, checkpoint); err != nil {
+		return err
+	}
+	log.Printf("Created audit checkpoint %d at log %d\n", checkpoint.ID, checkpoint.LogID)
+
+	return nil
+}
+
+// partitionAudit creates the monthly audit log partitions for the current
+// month and the next AUDIT_PARTITION_AHEAD months. An audit log whose month
+// has no partition is rejected, so it must run more often than monthly, e.g.
+// daily. Like purge-audit it must run as a member of the pwmanager_maintenance
+// role; the API role may not create partitions.
+func partitionAudit() error {
+	databaseURL := getEnv("MAINTENANCE_DATABASE_URL")
+	if databaseURL == "" {
+		return fmt.Errorf("MAINTENANCE_DATABASE_URL environment variable is required")
+	}
+
+	monthsAhead := 3
+	if value := getEnv("AUDIT_PARTITION_AHEAD"); value != "" {
+		months, err := strconv.Atoi(value)
+		if err != nil || months < 1 {
+			return fmt.Errorf("AUDIT_PARTITION_AHEAD must be at least 1")
+		}
+		monthsAhead = months
+	}
+
+	db, err := sqlx.Connect("postgres", databaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	auditRepo := repository.NewAuditRepository(db)
+	created, err := auditRepo.CreatePartitions(context.Background(), time.Now(), monthsAhead)
+	for _, name := range created {
+		log.Printf("Created audit partition %s\n", name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(created) == 0 {
+		log.Printf("Audit partitions exist for the next %d months\n", monthsAhead)
+	}
+
+	return nil
+}
+
+// archivePartition writes the audit logs of a partition to a compressed archive at path
+func archivePartition(ctx context.Context, auditRepo *repository.AuditRepository, partition models.AuditPartition, path string) (int64, error) {
+	archive, err := audit.CreateArchive(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := auditRepo.ExportPartition(ctx, partition, archive.Write); err != nil {
+		archive.Abort()
+		return 0, fmt.Errorf("failed to archive %s: %w", partition.Name, err)
+	}
+
+	if err := archive.Commit(); err != nil {
+		return 0, err
+	}
+
+	return archive.Rows(), nil
+}
+
+// printAuditSchema writes the JSON Schema of audit logs and their details to stdout
+func printAuditSchema() error {
+	catalog, err := audit.DefaultCatalog()
+	if err != nil {
+		return err
+	}
+
+	var schema bytes.Buffer
+	if err := json.Indent(&schema, catalog.Schema(), "", "  "); err != nil {
+		return fmt.Errorf("failed to format schema: %w", err)
+	}
+	schema.WriteByte('\n')
+
+	_, err = schema.WriteTo(os.Stdout)
+	return err
+}
+
+func printUsage() {
+	fmt.Println("Usage: maintenance <command>")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  purge-audit      Archive and drop audit log partitions older than the retention period")
+	fmt.Println("  partition-audit  Create the audit log partitions of the coming months")
+	fmt.Println("  audit-schema     Print the JSON Schema of audit logs and the details of each action")
+	fmt.Println()
+	fmt.Println("Environment variables:")
+	fmt.Println("  MAINTENANCE_DATABASE_URL  Connection string of a pwmanager_maintenance member (required)")
+	fmt.Println("  AUDIT_RETENTION_DAYS      Days to keep audit logs (default: 365)")
+	fmt.Println("  AUDIT_ARCHIVE_DIR         Directory for compressed archives of dropped partitions; empty drops without archiving")
+	fmt.Println("  AUDIT_SIGNING_KEY         Checkpoint signing key; the new chain head is checkpointed if set")
+	fmt.Println("  AUDIT_PARTITION_AHEAD     Months of audit log partitions created ahead of time (default: 3)")
+}
+
+// getEnv gets an environment variable.
+// It also checks for key + "_FILE" to read from a file (useful for Docker secrets)
+func getEnv(key string) string {
+	// Check for _FILE variant
+	if filePath := os.Getenv(key + "_FILE"); filePath != "" {
+		content, err := os.ReadFile(filePath)
+		if err == nil {
+			// Trim whitespace (newlines) that might be in the file
+			return string(bytes.TrimSpace(content))
+		}
+		log.Printf("Failed to read file %s for env %s: %v", filePath, key, err)
+	}
+
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"log"
 	"os"
@@ -10,6 +11,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/joho/godotenv"
+	"github.com/lib/pq"
 )
 
 func main() {
@@ -84,6 +86,13 @@ func main() {
 			log.Printf("Current version: %d\n", version)
 		}
 
+	case "roles":
+		// Create database roles and grants (re-run after every migration)
+		if err := setupRoles(databaseURL); err != nil {
+			log.Fatalf("Failed to set up roles: %v", err)
+		}
+		log.Println("Roles and grants applied successfully")
+
 	case "drop":
 		// Drop everything in database
 		if err := m.Drop(); err != nil {
@@ -106,11 +115,127 @@ func printUsage() {
 	fmt.Println("  down       Rollback all migrations")
 	fmt.Println("  force <v>  Force database version (for fixing dirty state)")
 	fmt.Println("  version    Show current migration version")
+	fmt.Println("  roles      Create the least-privilege API and maintenance roles and grants")
 	fmt.Println("  drop       Drop all tables (destructive!)")
 	fmt.Println()
 	fmt.Println("Environment variables:")
 	fmt.Println("  DATABASE_URL     PostgreSQL connection string (required)")
 	fmt.Println("  MIGRATIONS_DIR   Path to migrations directory (default: file://migrations)")
+	fmt.Println("  APP_DB_USER, APP_DB_PASSWORD                  Login role for the API (roles command, optional)")
+	fmt.Println("  MAINTENANCE_DB_USER, MAINTENANCE_DB_PASSWORD  Login role for the maintenance command (roles command, optional)")
+}
+
+// Group roles referenced by the append-only audit triggers
+const (
+	appRole         = "pwmanager_app"
+	maintenanceRole = "pwmanager_maintenance"
+)
+
+// roleGrants give the API full access to application tables but only INSERT
+// and SELECT on the audit trail; only the maintenance role may delete from it.
+// The maintenance role also enqueues its own events and drops purged deliveries in the outbox.
+// Audit log partitions are only accessed through audit_logs; only the
+// maintenance role creates them ahead of time and drops them on retention.
+// Grants on existing tables only, so the command is re-run after migrations.
+var roleGrants = []string{
+	"GRANT USAGE ON SCHEMA public TO " + appRole + ", " + maintenanceRole,
+	"GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO " + appRole,
+	"REVOKE UPDATE, DELETE, TRUNCATE ON audit_logs, audit_checkpoints FROM " + appRole,
+	"REVOKE ALL ON schema_migrations FROM " + appRole,
+	"GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO " + appRole + ", " + maintenanceRole,
+	"GRANT SELECT, INSERT, DELETE ON audit_logs, audit_checkpoints TO " + maintenanceRole,
+	"GRANT SELECT ON audit_sinks TO " + maintenanceRole,
+	"GRANT SELECT, INSERT, DELETE ON audit_outbox TO " + maintenanceRole,
+	`DO $$
+	DECLARE partition regclass;
+	BEGIN
+		FOR partition IN SELECT inhrelid::regclass FROM pg_inherits WHERE inhparent = 'audit_logs'::regclass LOOP
+			EXECUTE format('REVOKE ALL ON %s FROM ` + appRole + `, ` + maintenanceRole + `', partition);
+		END LOOP;
+	END $$`,
+	"REVOKE ALL ON FUNCTION create_audit_log_partition(DATE), drop_audit_log_partition(TEXT) FROM " + appRole,
+	"GRANT EXECUTE ON FUNCTION create_audit_log_partition(DATE) TO " + maintenanceRole,
+	"GRANT EXECUTE ON FUNCTION drop_audit_log_partition(TEXT) TO " + maintenanceRole,
+}
+
+// setupRoles creates the group roles, applies the grants and creates or
+// updates the optional login roles configured in the environment
+func setupRoles(databaseURL string) error {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, role := range []string{appRole, maintenanceRole} {
+		if err := ensureRole(tx, role, ""); err != nil {
+			return err
+		}
+	}
+
+	for _, grant := range roleGrants {
+		if _, err := tx.Exec(grant); err != nil {
+			return fmt.Errorf("%s: %w", grant, err)
+		}
+	}
+
+	logins := []struct{ userKey, passwordKey, group string }{
+		{"APP_DB_USER", "APP_DB_PASSWORD", appRole},
+		{"MAINTENANCE_DB_USER", "MAINTENANCE_DB_PASSWORD", maintenanceRole},
+	}
+	for _, login := range logins {
+		user := getEnv(login.userKey)
+		if user == "" {
+			continue
+		}
+		password := getEnv(login.passwordKey)
+		if password == "" {
+			return fmt.Errorf("%s is required when %s is set", login.passwordKey, login.userKey)
+		}
+		if err := ensureRole(tx, user, password); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("GRANT " + login.group + " TO " + pq.QuoteIdentifier(user)); err != nil {
+			return fmt.Errorf("failed to grant %s to %s: %w", login.group, user, err)
+		}
+		log.Printf("Login role %s is a member of %s\n", user, login.group)
+	}
+
+	return tx.Commit()
+}
+
+// ensureRole creates a role if it does not exist. With a password the role can
+// log in and its password is updated; without one it is a group role.
+func ensureRole(tx *sql.Tx, name, password string) error {
+	var exists bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)", name).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up role %s: %w", name, err)
+	}
+
+	verb := "CREATE"
+	if exists {
+		if password == "" {
+			return nil
+		}
+		verb = "ALTER"
+	}
+
+	statement := verb + " ROLE " + pq.QuoteIdentifier(name) + " NOLOGIN"
+	if password != "" {
+		statement = verb + " ROLE " + pq.QuoteIdentifier(name) + " LOGIN PASSWORD " + pq.QuoteLiteral(password)
+	}
+
+	if _, err := tx.Exec(statement); err != nil {
+		return fmt.Errorf("failed to %s role %s: %w", verb, name, err)
+	}
+
+	return nil
 }
 
 // getEnv gets an environment variable.
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// archiveRecord is an archived audit log. Unlike exports, archives keep the
+// chain hashes so the archived rows can still be verified offline.
+type archiveRecord struct {
+	*models.AuditLog
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// ArchiveWriter writes audit logs to a gzip-compressed NDJSON archive. The
+// archive is written to a temporary file and only appears under its path once
+// committed, so an interrupted archive is never mistaken for a complete one.
+type ArchiveWriter struct {
+	path    string
+	file    *os.File
+	gzip    *gzip.Writer
+	encoder *json.Encoder
+	rows    int64
+}
+
+// CreateArchive starts an archive at path, replacing any existing archive on commit
+func CreateArchive(path string) (*ArchiveWriter, error) {
+	file, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit archive: %w", err)
+	}
+
+	zw := gzip.NewWriter(file)
+	return &ArchiveWriter{
+		path:    path,
+		file:    file,
+		gzip:    zw,
+		encoder: json.NewEncoder(zw),
+	}, nil
+}
+
+// Write appends an audit log to the archive
+func (a *ArchiveWriter) Write(log *models.AuditLog) error {
+	record := archiveRecord{
+		AuditLog: log,
+		PrevHash: hex.EncodeToString(log.PrevHash),
+		Hash:     hex.EncodeToString(log.Hash),
+	}
+	if err := a.encoder.Encode(record); err != nil {
+		return fmt.Errorf("failed to write audit archive: %w", err)
+	}
+	a.rows++
+	return nil
+}
+
+// Rows returns the number of audit logs written
+func (a *ArchiveWriter) Rows() int64 {
+	return a.rows
+}
+
+// Commit completes the archive and moves it to its path once it is on disk
+func (a *ArchiveWriter) Commit() error {
+	err := a.gzip.Close()
+	if err == nil {
+		err = a.file.Sync()
+	}
+	if closeErr := a.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(a.path+".tmp", a.path)
+	}
+	if err != nil {
+		_ = os.Remove(a.path + ".tmp")
+		return fmt.Errorf("failed to complete audit archive: %w", err)
+	}
+	return nil
+}
+
+// Abort discards an archive that was not committed
+func (a *ArchiveWriter) Abort() {
+	_ = a.file.Close()
+	_ = os.Remove(a.path + ".tmp")
+}
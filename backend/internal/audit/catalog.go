@@ -0,0 +1,284 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+)
+
+// actionPattern is the form of audit actions: a lowercase resource and verb
+var actionPattern = regexp.MustCompile(`^[a-z][a-z_]*\.[a-z][a-z_]*$`)
+
+var (
+	uuidType = reflect.TypeOf(uuid.UUID{})
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// Catalog is the registry of audit event types. It maps every action to the
+// one event type recording it and describes the details of each as JSON Schema.
+type Catalog struct {
+	events  map[models.AuditAction]reflect.Type
+	actions []models.AuditAction
+	schema  json.RawMessage
+}
+
+var (
+	defaultCatalog    *Catalog
+	defaultCatalogErr error
+	defaultCatalogOne sync.Once
+)
+
+// DefaultCatalog returns the catalog of models.AuditEvents. An error means
+// the event types are inconsistent, so the API must not start.
+func DefaultCatalog() (*Catalog, error) {
+	defaultCatalogOne.Do(func() {
+		defaultCatalog, defaultCatalogErr = NewCatalog(models.AuditEvents())
+	})
+	return defaultCatalog, defaultCatalogErr
+}
+
+// NewCatalog creates a catalog of events, given as zero values. Every action
+// must be well-formed and recorded by exactly one struct event type whose
+// fields can be described as JSON Schema.
+func NewCatalog(events []models.AuditEvent) (*Catalog, error) {
+	c := &Catalog{events: make(map[models.AuditAction]reflect.Type, len(events))}
+	defs := make(map[string]interface{}, len(events))
+
+	for _, event := range events {
+		if event == nil {
+			return nil, errors.New("audit event catalog contains nil")
+		}
+		action := event.AuditAction()
+		t := reflect.TypeOf(event)
+
+		if !actionPattern.MatchString(string(action)) {
+			return nil, fmt.Errorf("invalid audit action %q of %s", action, t)
+		}
+		if existing, ok := c.events[action]; ok {
+			return nil, fmt.Errorf("audit action %s is recorded by both %s and %s", action, existing, t)
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("audit event %s for %s is not a struct", t, action)
+		}
+
+		schema, err := typeSchema(t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe audit event %s: %w", t, err)
+		}
+
+		c.events[action] = t
+		c.actions = append(c.actions, action)
+		defs[string(action)] = schema
+	}
+
+	sort.Slice(c.actions, func(i, j int) bool { return c.actions[i] < c.actions[j] })
+
+	schema, err := c.buildSchema(defs)
+	if err != nil {
+		return nil, err
+	}
+	c.schema = schema
+
+	return c, nil
+}
+
+// Actions returns the registered actions in sorted order
+func (c *Catalog) Actions() []models.AuditAction {
+	return append([]models.AuditAction(nil), c.actions...)
+}
+
+// Known reports whether action is registered
+func (c *Catalog) Known(action string) bool {
+	_, ok := c.events[models.AuditAction(action)]
+	return ok
+}
+
+// Validate checks that event is the registered event type of its action
+func (c *Catalog) Validate(event models.AuditEvent) error {
+	if event == nil {
+		return errors.New("audit event is nil")
+	}
+	action := event.AuditAction()
+	t, ok := c.events[action]
+	if !ok {
+		return fmt.Errorf("unknown audit action %q", action)
+	}
+	if reflect.TypeOf(event) != t {
+		return fmt.Errorf("audit action %s is recorded by %s, not %T", action, t, event)
+	}
+	return nil
+}
+
+// Decode parses the details of a stored log of action into its event type.
+// Details with fields the event type does not declare are rejected.
+func (c *Catalog) Decode(action string, details json.RawMessage) (models.AuditEvent, error) {
+	t, ok := c.events[models.AuditAction(action)]
+	if !ok {
+		return nil, fmt.Errorf("unknown audit action %q", action)
+	}
+
+	event := reflect.New(t)
+	if len(bytes.TrimSpace(details)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(details))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(event.Interface()); err != nil {
+			return nil, fmt.Errorf("failed to decode %s details: %w", action, err)
+		}
+	}
+	return event.Elem().Interface().(models.AuditEvent), nil
+}
+
+// Schema returns the JSON Schema of audit logs as returned by the API, with
+// the details of each action described under $defs
+func (c *Catalog) Schema() json.RawMessage {
+	return c.schema
+}
+
+// buildSchema describes an audit log whose details match the definition of its action
+func (c *Catalog) buildSchema(defs map[string]interface{}) (json.RawMessage, error) {
+	actions := make([]string, 0, len(c.actions))
+	conditions := make([]interface{}, 0, len(c.actions))
+	for _, action := range c.actions {
+		actions = append(actions, string(action))
+		conditions = append(conditions, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"action": map[string]interface{}{"const": action}},
+			},
+			"then": map[string]interface{}{
+				"properties": map[string]interface{}{"details": map[string]interface{}{"$ref": "#/$defs/" + string(action)}},
+			},
+		})
+	}
+
+	schema := map[string]interface{}{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "Audit log",
+		"description": "An audit log as returned by the API and the event stream. The details of each action are described under $defs; details are omitted for actions without fields.",
+		"type":        "object",
+		"properties": map[string]interface{}{
+			"id":         map[string]interface{}{"type": "integer"},
+			"user_id":    map[string]interface{}{"type": "string", "format": "uuid"},
+			"action":     map[string]interface{}{"enum": actions},
+			"ip_address": map[string]interface{}{"type": "string"},
+			"user_agent": map[string]interface{}{"type": "string"},
+			"details":    map[string]interface{}{"type": "object"},
+			"timestamp":  map[string]interface{}{"type": "string", "format": "date-time"},
+		},
+		"required": []string{"id", "action", "ip_address", "user_agent", "timestamp"},
+		"allOf":    conditions,
+		"$defs":    defs,
+	}
+
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit event schema: %w", err)
+	}
+	return encoded, nil
+}
+
+// typeSchema describes the JSON encoding of t
+func typeSchema(t reflect.Type) (map[string]interface{}, error) {
+	switch t {
+	case uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}, nil
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Ptr:
+		elem, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(elem), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil, fmt.Errorf("unsupported type %s", t)
+		}
+		items, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullable(map[string]interface{}{"type": "array", "items": items}), nil
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		required := []string{}
+		if err := structFields(t, properties, &required); err != nil {
+			return nil, err
+		}
+		sort.Strings(required)
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// structFields adds the JSON fields of struct t to properties, inlining
+// embedded structs like encoding/json. Fields without omitempty are required.
+func structFields(t reflect.Type, properties map[string]interface{}, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := structFields(field.Type, properties, required); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := properties[name]; ok {
+			return fmt.Errorf("duplicate field %s in %s", name, t)
+		}
+
+		schema, err := typeSchema(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		properties[name] = schema
+
+		if !strings.Contains(","+options+",", ",omitempty,") {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// nullable extends schema to also allow null
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+}
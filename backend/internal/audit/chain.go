@@ -0,0 +1,111 @@
+// Package audit implements the tamper-evident hash chain over audit_logs.
+//
+// Every row stores the SHA-256 hash of its canonical content together with the
+// hash of the previous row, so rewriting or removing a row breaks the chain for
+// every later row. Signed checkpoints pin the chain head periodically, which
+// also detects truncation of the most recent rows.
+//
+// The package also records events asynchronously through Writer, forwards them
+// to external sinks and encodes them for export as CSV, NDJSON or CEF.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// HashSize is the size of chain hashes in bytes
+const HashSize = sha256.Size
+
+// GenesisHash is the previous hash of the first chained row
+var GenesisHash = make([]byte, HashSize)
+
+// canonicalLog fixes the field order and encoding of the hashed content
+type canonicalLog struct {
+	ID        int64           `json:"id"`
+	PrevHash  string          `json:"prev_hash"`
+	UserID    *string         `json:"user_id"`
+	Action    string          `json:"action"`
+	IPAddress string          `json:"ip_address"`
+	UserAgent string          `json:"user_agent"`
+	Details   json.RawMessage `json:"details"`
+	Timestamp string          `json:"timestamp"`
+}
+
+// Hash computes the chain hash of an audit log row from its content and log.PrevHash.
+// Details, IP address and timestamp are normalised so the hash survives the
+// round trip through JSONB, INET and TIMESTAMPTZ columns.
+func Hash(log *models.AuditLog) ([]byte, error) {
+	details, err := CanonicalDetails(log.Details)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID *string
+	if log.UserID != nil {
+		id := log.UserID.String()
+		userID = &id
+	}
+
+	content, err := json.Marshal(canonicalLog{
+		ID:        log.ID,
+		PrevHash:  hex.EncodeToString(log.PrevHash),
+		UserID:    userID,
+		Action:    log.Action,
+		IPAddress: CanonicalIP(log.IPAddress),
+		UserAgent: log.UserAgent,
+		Details:   details,
+		Timestamp: CanonicalTime(log.Timestamp),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit log: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	return sum[:], nil
+}
+
+// CanonicalDetails re-encodes details with sorted keys and without insignificant
+// whitespace; numbers keep their textual form. Empty details encode as null.
+func CanonicalDetails(details json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(details)) == 0 {
+		return json.RawMessage("null"), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(details))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode audit details: %w", err)
+	}
+
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit details: %w", err)
+	}
+
+	return canonical, nil
+}
+
+// CanonicalIP formats an IP address the same way regardless of its input form.
+// Values that are not IP addresses are returned unchanged.
+func CanonicalIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	return addr.String()
+}
+
+// CanonicalTime formats a timestamp in UTC at the microsecond precision of Postgres
+func CanonicalTime(t time.Time) string {
+	return t.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano)
+}
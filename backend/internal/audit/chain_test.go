@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+)
+
+// testLog returns a chainable audit log with the given ID
+func testLog(id int64) *models.AuditLog {
+	userID := uuid.MustParse("6f1c2a8e-7f0e-4a39-9d6a-2b1f1c3e4d5a")
+	return &models.AuditLog{
+		ID:        id,
+		UserID:    &userID,
+		Action:    string(models.ActionEntryUpdated),
+		IPAddress: "192.0.2.10",
+		UserAgent: "pwmanager-test",
+		Details:   json.RawMessage(`{"vault_id":"9b0c9d4e-5a1f-4c57-8e2d-3f6a7b8c9d0e","version":2}`),
+		Timestamp: time.Date(2024, 5, 1, 10, 0, int(id), 123456000, time.UTC),
+	}
+}
+
+// testChain builds n chained logs with IDs first to first+n-1, linked to prev
+func testChain(t *testing.T, first int64, n int, prev []byte) []*models.AuditLog {
+	t.Helper()
+
+	logs := make([]*models.AuditLog, n)
+	for i := range logs {
+		log := testLog(first + int64(i))
+		log.PrevHash = prev
+		hash, err := Hash(log)
+		if err != nil {
+			t.Fatalf("Hash: %v", err)
+		}
+		log.Hash = hash
+		prev = hash
+		logs[i] = log
+	}
+	return logs
+}
+
+func TestHashSurvivesDatabaseRoundTrip(t *testing.T) {
+	log := testLog(1)
+	log.PrevHash = GenesisHash
+	want, err := Hash(log)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if len(want) != HashSize {
+		t.Fatalf("hash has %d bytes, want %d", len(want), HashSize)
+	}
+
+	// JSONB reorders keys and drops whitespace, INET and TIMESTAMPTZ
+	// normalise the address and drop sub-microsecond precision and the zone
+	stored := *log
+	stored.Details = json.RawMessage(`{ "version": 2, "vault_id": "9b0c9d4e-5a1f-4c57-8e2d-3f6a7b8c9d0e" }`)
+	stored.Timestamp = log.Timestamp.Add(789 * time.Nanosecond).In(time.FixedZone("CEST", 2*60*60))
+	got, err := Hash(&stored)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("hash changed after the database round trip")
+	}
+
+	v6 := *log
+	v6.IPAddress = "2001:0db8:0000:0000:0000:0000:0000:0001"
+	compact := *log
+	compact.IPAddress = "2001:db8::1"
+	h1, _ := Hash(&v6)
+	h2, _ := Hash(&compact)
+	if !bytes.Equal(h1, h2) {
+		t.Error("hash depends on the IPv6 address notation")
+	}
+}
+
+func TestHashCoversEveryField(t *testing.T) {
+	base := testLog(1)
+	base.PrevHash = GenesisHash
+	want, err := Hash(base)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	otherUser := uuid.New()
+	changes := map[string]func(*models.AuditLog){
+		"id":         func(l *models.AuditLog) { l.ID = 2 },
+		"prev hash":  func(l *models.AuditLog) { l.PrevHash = bytes.Repeat([]byte{1}, HashSize) },
+		"user":       func(l *models.AuditLog) { l.UserID = &otherUser },
+		"no user":    func(l *models.AuditLog) { l.UserID = nil },
+		"action":     func(l *models.AuditLog) { l.Action = string(models.ActionEntryDeleted) },
+		"ip address": func(l *models.AuditLog) { l.IPAddress = "192.0.2.11" },
+		"user agent": func(l *models.AuditLog) { l.UserAgent = "curl" },
+		"details":    func(l *models.AuditLog) { l.Details = json.RawMessage(`{"version":3}`) },
+		"no details": func(l *models.AuditLog) { l.Details = nil },
+		"timestamp":  func(l *models.AuditLog) { l.Timestamp = l.Timestamp.Add(time.Microsecond) },
+	}
+
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			log := *base
+			change(&log)
+			got, err := Hash(&log)
+			if err != nil {
+				t.Fatalf("Hash: %v", err)
+			}
+			if bytes.Equal(got, want) {
+				t.Error("hash did not change")
+			}
+		})
+	}
+}
+
+func TestCanonicalDetails(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "null"},
+		{"  ", "null"},
+		{`{"b":1,"a":{"d":[1,2],"c":null}}`, `{"a":{"c":null,"d":[1,2]},"b":1}`},
+		{`{"n":12345678901234567890}`, `{"n":12345678901234567890}`},
+		{`{"f":1.50}`, `{"f":1.50}`},
+	}
+
+	for _, tt := range tests {
+		got, err := CanonicalDetails(json.RawMessage(tt.in))
+		if err != nil {
+			t.Fatalf("CanonicalDetails(%q): %v", tt.in, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("CanonicalDetails(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := CanonicalDetails(json.RawMessage(`{"a":`)); err == nil {
+		t.Error("CanonicalDetails accepted invalid JSON")
+	}
+}
@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// ParseSigningKey parses a hex-encoded 32-byte Ed25519 seed
+func ParseSigningKey(hexSeed string) (ed25519.PrivateKey, error) {
+	seed, err := hex.DecodeString(hexSeed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be a %d-char hex string", 2*ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// ParsePublicKey parses a hex-encoded Ed25519 public key
+func ParsePublicKey(hexKey string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(hexKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be a %d-char hex string", 2*ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// checkpointMessage returns the bytes covered by a checkpoint signature
+func checkpointMessage(checkpoint *models.AuditCheckpoint) []byte {
+	return []byte(fmt.Sprintf("pwmanager-audit-checkpoint\n%d\n%x\n%s",
+		checkpoint.LogID, checkpoint.Hash, CanonicalTime(checkpoint.CreatedAt)))
+}
+
+// NewCheckpoint creates a signed checkpoint for the chain head logID/hash
+func NewCheckpoint(key ed25519.PrivateKey, logID int64, hash []byte) *models.AuditCheckpoint {
+	checkpoint := &models.AuditCheckpoint{
+		LogID:     logID,
+		Hash:      hash,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	checkpoint.Signature = ed25519.Sign(key, checkpointMessage(checkpoint))
+	return checkpoint
+}
+
+// VerifyCheckpoint reports whether the checkpoint was signed by the holder of publicKey
+func VerifyCheckpoint(publicKey ed25519.PublicKey, checkpoint *models.AuditCheckpoint) bool {
+	return ed25519.Verify(publicKey, checkpointMessage(checkpoint), checkpoint.Signature)
+}
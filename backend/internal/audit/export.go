@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// Export formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatCEF    = "cef"
+)
+
+// ExportFormats lists the supported export formats; the first is the default
+var ExportFormats = []string{FormatCSV, FormatNDJSON, FormatCEF}
+
+// CEF header fields identifying this application as the event source
+const (
+	cefVendor  = "SecurityByDesign"
+	cefProduct = "pwmanager"
+	cefVersion = "1.0"
+)
+
+// Encoder writes audit logs in an export format
+type Encoder interface {
+	// Encode writes a single audit log
+	Encode(log *models.AuditLog) error
+	// Close writes anything still pending; it does not close the underlying writer
+	Close() error
+}
+
+// NewEncoder returns an encoder writing audit logs to w in the given format
+func NewEncoder(w io.Writer, format string) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w), nil
+	case FormatNDJSON:
+		return &ndjsonEncoder{encoder: json.NewEncoder(w)}, nil
+	case FormatCEF:
+		return &cefEncoder{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ContentType returns the MIME type of an export format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// exportDetails returns the canonical details of a log, or "" if it has none
+func exportDetails(log *models.AuditLog) (string, error) {
+	details, err := CanonicalDetails(log.Details)
+	if err != nil || string(details) == "null" {
+		return "", err
+	}
+	return string(details), nil
+}
+
+// csvEncoder writes one row per log after a header row
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+var csvHeader = []string{"id", "timestamp", "user_id", "action", "ip_address", "user_agent", "details"}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	e := &csvEncoder{w: csv.NewWriter(w)}
+	_ = e.w.Write(csvHeader)
+	return e
+}
+
+func (e *csvEncoder) Encode(log *models.AuditLog) error {
+	var userID string
+	if log.UserID != nil {
+		userID = log.UserID.String()
+	}
+
+	details, err := exportDetails(log)
+	if err != nil {
+		return err
+	}
+
+	return e.w.Write([]string{
+		strconv.FormatInt(log.ID, 10),
+		CanonicalTime(log.Timestamp),
+		userID,
+		csvSafe(log.Action),
+		csvSafe(log.IPAddress),
+		csvSafe(log.UserAgent),
+		csvSafe(details),
+	})
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvSafe neutralises values that spreadsheet applications would evaluate as
+// formulas; user agents in particular are chosen by the client
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ndjsonEncoder writes one JSON object per line
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(log *models.AuditLog) error {
+	return e.encoder.Encode(log)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+// cefEncoder writes one ArcSight Common Event Format line per log
+type cefEncoder struct {
+	w io.Writer
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, "=", `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func (e *cefEncoder) Encode(log *models.AuditLog) error {
+	details, err := exportDetails(log)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefVendor, cefProduct, cefVersion,
+		cefHeaderEscaper.Replace(log.Action), cefHeaderEscaper.Replace(log.Action), Severity(log.Action))
+
+	extension := [][2]string{
+		{"externalId", strconv.FormatInt(log.ID, 10)},
+		{"rt", strconv.FormatInt(log.Timestamp.UnixMilli(), 10)},
+		{"act", log.Action},
+		{"src", log.IPAddress},
+		{"requestClientApplication", log.UserAgent},
+	}
+	if details != "" {
+		extension = append(extension, [2]string{"cs1Label", "details"}, [2]string{"cs1", details})
+	}
+	if log.UserID != nil {
+		extension = append(extension, [2]string{"suid", log.UserID.String()})
+	}
+
+	separator := ""
+	for _, field := range extension {
+		if field[1] == "" {
+			continue
+		}
+		b.WriteString(separator)
+		separator = " "
+		b.WriteString(field[0])
+		b.WriteByte('=')
+		b.WriteString(cefExtensionEscaper.Replace(field[1]))
+	}
+	b.WriteByte('\n')
+
+	_, err = io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *cefEncoder) Close() error {
+	return nil
+}
+
+// Severity rates an action on the CEF scale from 0 to 10: security events and
+// failed or denied attempts are 5, destructive and revoking actions 3, everything else 1
+func Severity(action string) int {
+	switch {
+	case strings.HasPrefix(action, "security."), strings.HasSuffix(action, "failed"), strings.HasSuffix(action, "denied"):
+		return 5
+	case strings.HasSuffix(action, ".deleted"), strings.HasSuffix(action, ".purged"),
+		strings.HasSuffix(action, ".revoked"), strings.HasSuffix(action, ".disabled"):
+		return 3
+	default:
+		return 1
+	}
+}
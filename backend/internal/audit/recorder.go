@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+)
+
+// Recorder records audit events. Recording never fails the caller; events
+// that cannot be stored are reported through the recorder's own metrics and logs.
+type Recorder interface {
+	// Record records an event under its action; an empty ipAddress is stored as NULL (e.g. for background jobs)
+	Record(ctx context.Context, userID *uuid.UUID, ipAddress, userAgent string, event models.AuditEvent)
+}
+
+// ErrRejected marks store errors that retrying cannot fix, e.g. a log that
+// violates a constraint or falls outside every partition
+var ErrRejected = errors.New("audit log rejected")
+
+// Store appends audit logs to the hash chain
+type Store interface {
+	// CreateBatch appends logs in order, assigning their IDs and hashes.
+	// Errors wrapping ErrRejected are permanent for at least one of the logs.
+	CreateBatch(ctx context.Context, logs []*models.AuditLog) error
+}
+
+// PartitionStore lists the monthly partitions audit logs are stored in
+type PartitionStore interface {
+	// ListPartitions returns the partitions, oldest first
+	ListPartitions(ctx context.Context) ([]models.AuditPartition, error)
+}
+
+// NewLog builds an unsaved audit log stamped with the current time.
+// An ipAddress that is not an IP address, e.g. from a forged X-Forwarded-For
+// header, is not stored since the INET column would reject the whole batch.
+// Text Postgres rejects, invalid UTF-8 and NUL characters, is replaced in the
+// user agent and in string details for the same reason.
+// The event must be registered in DefaultCatalog; events without fields are
+// stored without details.
+func NewLog(userID *uuid.UUID, ipAddress, userAgent string, event models.AuditEvent) (*models.AuditLog, error) {
+	catalog, err := DefaultCatalog()
+	if err != nil {
+		return nil, err
+	}
+	if err := catalog.Validate(event); err != nil {
+		return nil, err
+	}
+
+	if _, err := netip.ParseAddr(ipAddress); err != nil {
+		ipAddress = ""
+	}
+
+	detailsJSON, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal details: %w", err)
+	}
+	detailsJSON, err = sanitizeDetails(detailsJSON)
+	if err != nil {
+		return nil, err
+	}
+	if string(detailsJSON) == "{}" {
+		detailsJSON = nil
+	}
+
+	return &models.AuditLog{
+		UserID:    userID,
+		Action:    string(event.AuditAction()),
+		IPAddress: ipAddress,
+		UserAgent: sanitizeText(userAgent),
+		Details:   detailsJSON,
+		Timestamp: time.Now().UTC().Truncate(time.Microsecond),
+	}, nil
+}
+
+// sanitizeText replaces invalid UTF-8 and removes NUL characters, which
+// Postgres rejects in text and jsonb values
+func sanitizeText(s string) string {
+	return strings.ReplaceAll(strings.ToValidUTF8(s, "\uFFFD"), "\x00", "")
+}
+
+// sanitizeDetails applies sanitizeText to the strings and keys of encoded details
+func sanitizeDetails(details json.RawMessage) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(details))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode details: %w", err)
+	}
+
+	sanitized, err := json.Marshal(sanitizeValue(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal details: %w", err)
+	}
+	return sanitized, nil
+}
+
+// sanitizeValue applies sanitizeText to the strings of a decoded JSON value
+func sanitizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return sanitizeText(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = sanitizeValue(v[i])
+		}
+		return v
+	case map[string]interface{}:
+		sanitized := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			sanitized[sanitizeText(key)] = sanitizeValue(item)
+		}
+		return sanitized
+	default:
+		return value
+	}
+}
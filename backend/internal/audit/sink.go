@@ -0,0 +1,19 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// Sink forwards audit logs to an external system such as a SIEM.
+// Deliveries go through the audit outbox and are retried until they succeed,
+// so receivers may see an event more than once and should de-duplicate by log ID.
+type Sink interface {
+	// Name identifies the sink in the outbox; it must be stable across restarts
+	Name() string
+	// Send delivers a single audit log
+	Send(ctx context.Context, log *models.AuditLog) error
+	// Close releases any connection held by the sink
+	Close() error
+}
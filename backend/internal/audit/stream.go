@@ -0,0 +1,201 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// streamChannelPrefix prefixes the Redis pub/sub channel of each user's audit events
+const streamChannelPrefix = "audit:events:"
+
+// streamBuffer is the number of events buffered per subscriber. A subscriber
+// that falls further behind is disconnected; it can resume from the last event
+// it received.
+const streamBuffer = 64
+
+// maxStreamsPerUser caps the concurrent subscriptions of a user on one replica
+const maxStreamsPerUser = 5
+
+// Stream errors
+var (
+	ErrTooManyStreams = errors.New("too many audit event streams")
+	ErrStreamClosed   = errors.New("audit event stream closed")
+)
+
+// Stream pushes users' audit events to live subscribers across API replicas.
+// Logs are published to a Redis channel per user once they are stored. Every
+// replica holds a single pattern subscription and forwards each event to its
+// own subscribers of that user. Delivery is best effort: events published
+// while Redis is unavailable only appear in the stored logs.
+type Stream struct {
+	client *redis.Client
+	logger *zap.Logger
+
+	mu          sync.Mutex
+	closed      bool
+	subscribers map[uuid.UUID]map[chan *models.AuditLog]struct{}
+}
+
+// NewStream creates an audit event stream; call Run to start forwarding events
+func NewStream(client *redis.Client, logger *zap.Logger) *Stream {
+	return &Stream{
+		client:      client,
+		logger:      logger,
+		subscribers: make(map[uuid.UUID]map[chan *models.AuditLog]struct{}),
+	}
+}
+
+// Store wraps next so that logs are published once they are stored
+func (s *Stream) Store(next Store) Store {
+	return &publishingStore{next: next, stream: s}
+}
+
+// publishingStore publishes logs after storing them, so streamed events carry their IDs
+type publishingStore struct {
+	next   Store
+	stream *Stream
+}
+
+// CreateBatch implements Store
+func (p *publishingStore) CreateBatch(ctx context.Context, logs []*models.AuditLog) error {
+	if err := p.next.CreateBatch(ctx, logs); err != nil {
+		return err
+	}
+	p.stream.Publish(ctx, logs)
+	return nil
+}
+
+// Publish publishes the logs of users to their channels. Failures are only
+// logged since the logs are already stored.
+func (s *Stream) Publish(ctx context.Context, logs []*models.AuditLog) {
+	pipe := s.client.Pipeline()
+	for _, log := range logs {
+		if log.UserID == nil {
+			continue
+		}
+		payload, err := json.Marshal(log)
+		if err != nil {
+			s.logger.Error("failed to encode audit event", zap.Int64("log_id", log.ID), zap.Error(err))
+			continue
+		}
+		pipe.Publish(ctx, streamChannelPrefix+log.UserID.String(), payload)
+	}
+	if pipe.Len() == 0 {
+		return
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn("failed to publish audit events", zap.Error(err))
+	}
+}
+
+// Run forwards published events to the subscribers of this replica until ctx
+// is cancelled, and then closes all subscriptions. The Redis client
+// re-subscribes after connection failures.
+func (s *Stream) Run(ctx context.Context) {
+	pubsub := s.client.PSubscribe(ctx, streamChannelPrefix+"*")
+	defer func() { _ = pubsub.Close() }()
+	defer s.close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			s.deliver(msg)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for a user's events. The channel is closed
+// when the subscriber falls behind or the stream stops; call unsubscribe when done.
+func (s *Stream) Subscribe(userID uuid.UUID) (<-chan *models.AuditLog, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, nil, ErrStreamClosed
+	}
+	if len(s.subscribers[userID]) >= maxStreamsPerUser {
+		return nil, nil, ErrTooManyStreams
+	}
+
+	events := make(chan *models.AuditLog, streamBuffer)
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = make(map[chan *models.AuditLog]struct{})
+	}
+	s.subscribers[userID][events] = struct{}{}
+
+	return events, func() { s.unsubscribe(userID, events) }, nil
+}
+
+// unsubscribe removes a subscriber and closes its channel unless that already happened
+func (s *Stream) unsubscribe(userID uuid.UUID, events chan *models.AuditLog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[userID][events]; !ok {
+		return
+	}
+	delete(s.subscribers[userID], events)
+	if len(s.subscribers[userID]) == 0 {
+		delete(s.subscribers, userID)
+	}
+	close(events)
+}
+
+// deliver forwards a published event to the subscribers of its user,
+// disconnecting those whose buffer is full
+func (s *Stream) deliver(msg *redis.Message) {
+	userID, err := uuid.Parse(strings.TrimPrefix(msg.Channel, streamChannelPrefix))
+	if err != nil {
+		return
+	}
+
+	log := &models.AuditLog{}
+	if err := json.Unmarshal([]byte(msg.Payload), log); err != nil {
+		s.logger.Warn("failed to decode audit event", zap.String("channel", msg.Channel), zap.Error(err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for events := range s.subscribers[userID] {
+		select {
+		case events <- log:
+		default:
+			delete(s.subscribers[userID], events)
+			close(events)
+		}
+	}
+	if len(s.subscribers[userID]) == 0 {
+		delete(s.subscribers, userID)
+	}
+}
+
+// close closes all subscriptions and rejects new ones
+func (s *Stream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for userID, subscribers := range s.subscribers {
+		for events := range subscribers {
+			close(events)
+		}
+		delete(s.subscribers, userID)
+	}
+}
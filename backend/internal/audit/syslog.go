@@ -0,0 +1,199 @@
+package audit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// syslogFacility is the RFC 5424 "log audit" facility
+const syslogFacility = 13
+
+// syslogSDID identifies the structured data element; 32473 is the private
+// enterprise number reserved for documentation (RFC 5612)
+const syslogSDID = "audit@32473"
+
+// syslogTimeout bounds connecting and writing when the context has no deadline
+const syslogTimeout = 10 * time.Second
+
+// SyslogConfig holds the settings of a syslog receiver
+type SyslogConfig struct {
+	Network string // udp, tcp or tls
+	Address string // host:port
+	CAFile  string // PEM bundle trusted for tls, the system roots if empty
+	AppName string // APP-NAME of the messages
+}
+
+// SyslogSink sends audit logs as RFC 5424 messages. TCP and TLS use octet
+// counting framing (RFC 6587) over a persistent connection that is re-opened
+// after a failure.
+type SyslogSink struct {
+	cfg       SyslogConfig
+	hostname  string
+	tlsConfig *tls.Config
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink creates a syslog sink; it connects on the first message
+func NewSyslogSink(cfg SyslogConfig) (*SyslogSink, error) {
+	host, _, err := net.SplitHostPort(cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog address %q: %w", cfg.Address, err)
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = cefProduct
+	}
+
+	s := &SyslogSink{cfg: cfg, hostname: "-"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		s.hostname = hostname
+	}
+
+	switch cfg.Network {
+	case "udp", "tcp":
+	case "tls":
+		s.tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read syslog CA file: %w", err)
+			}
+			roots := x509.NewCertPool()
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("syslog CA file contains no certificates")
+			}
+			s.tlsConfig.RootCAs = roots
+		}
+	default:
+		return nil, fmt.Errorf("invalid syslog network %q: must be udp, tcp or tls", cfg.Network)
+	}
+
+	return s, nil
+}
+
+// Name implements Sink
+func (s *SyslogSink) Name() string {
+	return "syslog"
+}
+
+// Send implements Sink
+func (s *SyslogSink) Send(ctx context.Context, log *models.AuditLog) error {
+	msg, err := s.format(log)
+	if err != nil {
+		return err
+	}
+	if s.cfg.Network != "udp" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(syslogTimeout)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.dial(ctx); err != nil {
+			return err
+		}
+	}
+
+	_ = s.conn.SetWriteDeadline(deadline)
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("failed to write syslog message: %w", err)
+	}
+
+	return nil
+}
+
+// Close implements Sink
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// dial opens the connection to the receiver
+func (s *SyslogSink) dial(ctx context.Context) error {
+	var err error
+	dialer := &net.Dialer{Timeout: syslogTimeout}
+
+	switch s.cfg.Network {
+	case "tls":
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: s.tlsConfig}
+		s.conn, err = tlsDialer.DialContext(ctx, "tcp", s.cfg.Address)
+	default:
+		s.conn, err = dialer.DialContext(ctx, s.cfg.Network, s.cfg.Address)
+	}
+	if err != nil {
+		s.conn = nil
+		return fmt.Errorf("failed to connect to syslog receiver: %w", err)
+	}
+
+	return nil
+}
+
+// sdEscaper escapes structured data parameter values (RFC 5424 section 6.3.3)
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "]", `\]`)
+
+// format renders an audit log as an RFC 5424 message whose MSG is the log as
+// JSON, marked as UTF-8 by a byte order mark
+func (s *SyslogSink) format(log *models.AuditLog) (string, error) {
+	body, err := json.Marshal(log)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit log: %w", err)
+	}
+
+	var severity int
+	switch Severity(log.Action) {
+	case 5:
+		severity = 4 // warning
+	case 3:
+		severity = 5 // notice
+	default:
+		severity = 6 // informational
+	}
+
+	msgID := log.Action
+	if len(msgID) > 32 {
+		msgID = msgID[:32]
+	}
+
+	params := []string{`id="` + strconv.FormatInt(log.ID, 10) + `"`}
+	if log.UserID != nil {
+		params = append(params, `user_id="`+log.UserID.String()+`"`)
+	}
+	if log.IPAddress != "" {
+		params = append(params, `ip="`+sdEscaper.Replace(log.IPAddress)+`"`)
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s - %s [%s %s] \ufeff%s",
+		syslogFacility*8+severity,
+		log.Timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname, s.cfg.AppName, msgID,
+		syslogSDID, strings.Join(params, " "),
+		body,
+	), nil
+}
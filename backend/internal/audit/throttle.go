@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+)
+
+// ThrottledRecorder aggregates repeated events so that a flood of identical
+// events, e.g. from an attack, cannot flood the audit trail. Within a window
+// the first occurrence of an event is recorded immediately; further identical
+// occurrences are only counted and recorded as one summary event carrying the
+// number of suppressed occurrences when the window ends. Events are identical
+// if their user, IP address and event are equal. Only events implementing
+// models.AggregatableAuditEvent are throttled; others are passed through.
+type ThrottledRecorder struct {
+	next    Recorder
+	window  time.Duration
+	maxKeys int
+
+	mu       sync.Mutex
+	events   map[string]*throttledEvent
+	overflow map[models.AuditAction]int // events dropped because maxKeys distinct events were tracked
+}
+
+// throttledEvent tracks an event within its window
+type throttledEvent struct {
+	userID     *uuid.UUID
+	ipAddress  string
+	userAgent  string
+	event      models.AggregatableAuditEvent
+	started    time.Time
+	suppressed int
+}
+
+// NewThrottledRecorder creates a recorder that aggregates identical events per
+// window before passing them to next, tracking at most maxKeys distinct events
+func NewThrottledRecorder(next Recorder, window time.Duration, maxKeys int) *ThrottledRecorder {
+	return &ThrottledRecorder{
+		next:     next,
+		window:   window,
+		maxKeys:  maxKeys,
+		events:   make(map[string]*throttledEvent),
+		overflow: make(map[models.AuditAction]int),
+	}
+}
+
+// Record implements Recorder
+func (r *ThrottledRecorder) Record(ctx context.Context, userID *uuid.UUID, ipAddress, userAgent string, event models.AuditEvent) {
+	aggregatable, ok := event.(models.AggregatableAuditEvent)
+	if !ok {
+		r.next.Record(ctx, userID, ipAddress, userAgent, event)
+		return
+	}
+	key := throttleKey(userID, ipAddress, aggregatable)
+
+	r.mu.Lock()
+	if tracked, ok := r.events[key]; ok {
+		tracked.suppressed++
+		r.mu.Unlock()
+		return
+	}
+	if len(r.events) >= r.maxKeys {
+		r.overflow[event.AuditAction()]++
+		r.mu.Unlock()
+		return
+	}
+	r.events[key] = &throttledEvent{
+		userID:    userID,
+		ipAddress: ipAddress,
+		userAgent: userAgent,
+		event:     aggregatable,
+		started:   time.Now(),
+	}
+	r.mu.Unlock()
+
+	r.next.Record(ctx, userID, ipAddress, userAgent, event)
+}
+
+// Run records the summaries of ended windows on every window until ctx is cancelled
+func (r *ThrottledRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.flush(now.Add(-r.window))
+		}
+	}
+}
+
+// Flush records the summaries of all windows, ended or not
+func (r *ThrottledRecorder) Flush() {
+	r.flush(time.Now())
+}
+
+// flush records and forgets the events whose window started at or before cutoff
+func (r *ThrottledRecorder) flush(cutoff time.Time) {
+	r.mu.Lock()
+	var ended []*throttledEvent
+	for key, event := range r.events {
+		if !event.started.After(cutoff) {
+			delete(r.events, key)
+			if event.suppressed > 0 {
+				ended = append(ended, event)
+			}
+		}
+	}
+	overflow := r.overflow
+	r.overflow = make(map[models.AuditAction]int)
+	r.mu.Unlock()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for _, event := range ended {
+		summary := event.event.Aggregate(event.suppressed, event.started.UTC().Truncate(time.Second), now)
+		r.next.Record(ctx, event.userID, event.ipAddress, event.userAgent, summary)
+	}
+
+	for action, count := range overflow {
+		r.next.Record(ctx, nil, "", "", models.SecurityEventsSuppressedEvent{
+			EventAction: action,
+			Suppressed:  count,
+			Reason:      "too_many_distinct_events",
+		})
+	}
+}
+
+// throttleKey identifies identical events; the action is part of the key since
+// the security events of different actions encode alike
+func throttleKey(userID *uuid.UUID, ipAddress string, event models.AggregatableAuditEvent) string {
+	var user string
+	if userID != nil {
+		user = userID.String()
+	}
+	encoded, _ := json.Marshal(event)
+	return string(event.AuditAction()) + "|" + user + "|" + ipAddress + "|" + string(encoded)
+}
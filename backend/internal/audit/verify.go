@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// ChainSource provides the audit trail for verification
+type ChainSource interface {
+	// GetCheckpoints returns all checkpoints ordered by ID
+	GetCheckpoints(ctx context.Context) ([]*models.AuditCheckpoint, error)
+	// WalkChain calls fn for every audit log in ID order until fn returns an error
+	WalkChain(ctx context.Context, fn func(*models.AuditLog) error) error
+	// GetPurgeAnchor returns the ID and hash of the last row removed by retention
+	GetPurgeAnchor(ctx context.Context) (int64, []byte, error)
+}
+
+// errChainBroken stops the walk at the first break
+var errChainBroken = errors.New("audit chain broken")
+
+// Verify walks the whole audit trail and reports the first break.
+// Rows before the first chained row are counted as legacy rows. After a
+// retention purge the first row links to the last purged row instead of the genesis hash. Checkpoint
+// signatures are checked when publicKey is set; every checkpoint must match
+// the hash of the row it pins, so truncated or rewritten history is detected.
+func Verify(ctx context.Context, source ChainSource, publicKey ed25519.PublicKey) (*models.AuditVerification, error) {
+	result := &models.AuditVerification{VerifiedAt: time.Now().UTC()}
+
+	checkpoints, err := source.GetCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make(map[int64][]*models.AuditCheckpoint, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		if publicKey != nil && !VerifyCheckpoint(publicKey, checkpoint) {
+			result.FirstBreak = &models.AuditChainBreak{CheckpointID: checkpoint.ID, Reason: "invalid checkpoint signature"}
+			return result, nil
+		}
+		pinned[checkpoint.LogID] = append(pinned[checkpoint.LogID], checkpoint)
+		result.LastCheckpointID = checkpoint.ID
+	}
+	result.SignaturesVerified = publicKey != nil
+
+	purgedThrough, anchor, err := source.GetPurgeAnchor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.PurgedThroughID = purgedThrough
+
+	var prev []byte
+	fail := func(log *models.AuditLog, checkpointID int64, reason string) error {
+		result.FirstBreak = &models.AuditChainBreak{LogID: log.ID, CheckpointID: checkpointID, Reason: reason}
+		return errChainBroken
+	}
+
+	err = source.WalkChain(ctx, func(log *models.AuditLog) error {
+		result.LastLogID = log.ID
+
+		if log.Hash == nil {
+			if prev == nil {
+				result.LegacyRows++
+				return nil
+			}
+			return fail(log, 0, "row is not chained")
+		}
+
+		expected := prev
+		if expected == nil {
+			expected = GenesisHash
+			if anchor != nil && log.ID > purgedThrough && bytes.Equal(log.PrevHash, anchor) {
+				expected = anchor
+			}
+		}
+		if !bytes.Equal(log.PrevHash, expected) {
+			return fail(log, 0, "previous hash does not match the preceding row")
+		}
+
+		hash, err := Hash(log)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hash, log.Hash) {
+			return fail(log, 0, "row content does not match its hash")
+		}
+
+		for _, checkpoint := range pinned[log.ID] {
+			if !bytes.Equal(checkpoint.Hash, log.Hash) {
+				return fail(log, checkpoint.ID, "checkpoint does not match the row hash")
+			}
+			result.CheckedCheckpoints++
+		}
+		delete(pinned, log.ID)
+
+		prev = log.Hash
+		result.CheckedRows++
+		return nil
+	})
+	if errors.Is(err, errChainBroken) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Checkpoints left over pin rows that no longer exist
+	for _, checkpoint := range checkpoints {
+		if _, missing := pinned[checkpoint.LogID]; missing {
+			result.FirstBreak = &models.AuditChainBreak{LogID: checkpoint.LogID, CheckpointID: checkpoint.ID, Reason: "checkpointed row is missing"}
+			return result, nil
+		}
+	}
+
+	result.Valid = true
+	return result, nil
+}
@@ -0,0 +1,285 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// chainSource is an in-memory ChainSource
+type chainSource struct {
+	logs          []*models.AuditLog
+	checkpoints   []*models.AuditCheckpoint
+	purgedThrough int64
+	anchor        []byte
+}
+
+func (s *chainSource) GetCheckpoints(ctx context.Context) ([]*models.AuditCheckpoint, error) {
+	return s.checkpoints, nil
+}
+
+func (s *chainSource) WalkChain(ctx context.Context, fn func(*models.AuditLog) error) error {
+	for _, log := range s.logs {
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *chainSource) GetPurgeAnchor(ctx context.Context) (int64, []byte, error) {
+	return s.purgedThrough, s.anchor, nil
+}
+
+// testKey returns a fixed Ed25519 signing key
+func testKey(t *testing.T, seed byte) ed25519.PrivateKey {
+	t.Helper()
+
+	key, err := ParseSigningKey(hex.EncodeToString(make([]byte, ed25519.SeedSize-1)) + fmt.Sprintf("%02x", seed))
+	if err != nil {
+		t.Fatalf("ParseSigningKey: %v", err)
+	}
+	return key
+}
+
+// checkpoint signs a checkpoint with ID id pinning log
+func checkpoint(key ed25519.PrivateKey, id int64, log *models.AuditLog) *models.AuditCheckpoint {
+	c := NewCheckpoint(key, log.ID, log.Hash)
+	c.ID = id
+	return c
+}
+
+// rehash recomputes the hash of a modified log, as an attacker with write
+// access to the table would
+func rehash(t *testing.T, log *models.AuditLog) {
+	t.Helper()
+
+	hash, err := Hash(log)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	log.Hash = hash
+}
+
+func TestVerify(t *testing.T) {
+	key := testKey(t, 1)
+	publicKey := key.Public().(ed25519.PublicKey)
+
+	tests := []struct {
+		name string
+		// source returns the audit trail, built from a valid chain of five rows
+		source       func(t *testing.T, logs []*models.AuditLog) *chainSource
+		wantLogID    int64
+		wantCheckID  int64
+		wantReason   string
+		wantChecked  int64
+		wantPinned   int
+		wantPurgedTo int64
+	}{
+		{
+			name: "valid chain",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				return &chainSource{logs: logs, checkpoints: []*models.AuditCheckpoint{
+					checkpoint(key, 1, logs[2]), checkpoint(key, 2, logs[4]),
+				}}
+			},
+			wantChecked: 5,
+			wantPinned:  2,
+		},
+		{
+			name: "legacy rows before the chain",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				legacy := []*models.AuditLog{testLog(-1), testLog(0)}
+				return &chainSource{logs: append(legacy, logs...)}
+			},
+			wantChecked: 5,
+		},
+		{
+			name: "tampered row",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				logs[1].Details = json.RawMessage(`{"vault_id":"9b0c9d4e-5a1f-4c57-8e2d-3f6a7b8c9d0e","version":3}`)
+				return &chainSource{logs: logs}
+			},
+			wantLogID:  2,
+			wantReason: "row content does not match its hash",
+		},
+		{
+			name: "tampered and rehashed row",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				logs[1].UserAgent = "forged"
+				rehash(t, logs[1])
+				return &chainSource{logs: logs}
+			},
+			wantLogID:  3,
+			wantReason: "previous hash does not match the preceding row",
+		},
+		{
+			name: "rewritten head caught by its checkpoint",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				pin := checkpoint(key, 1, logs[4])
+				logs[4].Action = string(models.ActionEntryDeleted)
+				rehash(t, logs[4])
+				return &chainSource{logs: logs, checkpoints: []*models.AuditCheckpoint{pin}}
+			},
+			wantLogID:   5,
+			wantCheckID: 1,
+			wantReason:  "checkpoint does not match the row hash",
+		},
+		{
+			name: "deleted row",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				return &chainSource{logs: append(logs[:2:2], logs[3:]...)}
+			},
+			wantLogID:  4,
+			wantReason: "previous hash does not match the preceding row",
+		},
+		{
+			name: "deleted first row",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				return &chainSource{logs: logs[1:]}
+			},
+			wantLogID:  2,
+			wantReason: "previous hash does not match the preceding row",
+		},
+		{
+			name: "truncated tail caught by its checkpoint",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				return &chainSource{logs: logs[:3], checkpoints: []*models.AuditCheckpoint{checkpoint(key, 1, logs[4])}}
+			},
+			wantLogID:   5,
+			wantCheckID: 1,
+			wantReason:  "checkpointed row is missing",
+		},
+		{
+			name: "reordered rows",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				logs[1], logs[2] = logs[2], logs[1]
+				return &chainSource{logs: logs}
+			},
+			wantLogID:  3,
+			wantReason: "previous hash does not match the preceding row",
+		},
+		{
+			name: "unchained row after the chain started",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				logs[3].PrevHash, logs[3].Hash = nil, nil
+				return &chainSource{logs: logs}
+			},
+			wantLogID:  4,
+			wantReason: "row is not chained",
+		},
+		{
+			name: "purge anchor after dropping partitions",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				// DropPartitions removes the rows and checkpoints up to the
+				// boundary and appends an audit.purged event to the chain
+				purged := testLog(6)
+				purged.Action = string(models.ActionAuditPurged)
+				purged.UserID = nil
+				purged.Details = json.RawMessage(fmt.Sprintf(`{"through_id":2,"through_hash":%q,"rows":2}`, hex.EncodeToString(logs[1].Hash)))
+				purged.PrevHash = logs[4].Hash
+				rehash(t, purged)
+
+				return &chainSource{
+					logs:          append(logs[2:], purged),
+					checkpoints:   []*models.AuditCheckpoint{checkpoint(key, 2, logs[3])},
+					purgedThrough: 2,
+					anchor:        logs[1].Hash,
+				}
+			},
+			wantChecked:  4,
+			wantPinned:   1,
+			wantPurgedTo: 2,
+		},
+		{
+			name: "rows dropped without a purge anchor",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				return &chainSource{logs: logs[2:]}
+			},
+			wantLogID:  3,
+			wantReason: "previous hash does not match the preceding row",
+		},
+		{
+			name: "purge anchor not matching the first row",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				return &chainSource{logs: logs[3:], purgedThrough: 2, anchor: logs[1].Hash}
+			},
+			wantLogID:    4,
+			wantReason:   "previous hash does not match the preceding row",
+			wantPurgedTo: 2,
+		},
+		{
+			name: "bad checkpoint signature",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				forged := checkpoint(testKey(t, 2), 2, logs[4])
+				return &chainSource{logs: logs, checkpoints: []*models.AuditCheckpoint{checkpoint(key, 1, logs[2]), forged}}
+			},
+			wantCheckID: 2,
+			wantReason:  "invalid checkpoint signature",
+		},
+		{
+			name: "checkpoint hash changed after signing",
+			source: func(t *testing.T, logs []*models.AuditLog) *chainSource {
+				pin := checkpoint(key, 1, logs[4])
+				pin.Hash = logs[3].Hash
+				return &chainSource{logs: logs, checkpoints: []*models.AuditCheckpoint{pin}}
+			},
+			wantCheckID: 1,
+			wantReason:  "invalid checkpoint signature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.source(t, testChain(t, 1, 5, GenesisHash))
+
+			result, err := Verify(context.Background(), source, publicKey)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if result.PurgedThroughID != tt.wantPurgedTo {
+				t.Errorf("purged through %d, want %d", result.PurgedThroughID, tt.wantPurgedTo)
+			}
+
+			if tt.wantReason == "" {
+				if !result.Valid || result.FirstBreak != nil {
+					t.Fatalf("chain reported broken: %+v", result.FirstBreak)
+				}
+				if result.CheckedRows != tt.wantChecked || result.CheckedCheckpoints != tt.wantPinned {
+					t.Errorf("checked %d rows and %d checkpoints, want %d and %d",
+						result.CheckedRows, result.CheckedCheckpoints, tt.wantChecked, tt.wantPinned)
+				}
+				if !result.SignaturesVerified {
+					t.Error("signatures not reported as verified")
+				}
+				return
+			}
+
+			if result.Valid || result.FirstBreak == nil {
+				t.Fatal("chain reported valid")
+			}
+			want := models.AuditChainBreak{LogID: tt.wantLogID, CheckpointID: tt.wantCheckID, Reason: tt.wantReason}
+			if *result.FirstBreak != want {
+				t.Errorf("first break = %+v, want %+v", *result.FirstBreak, want)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutPublicKeySkipsSignatures(t *testing.T) {
+	logs := testChain(t, 1, 3, GenesisHash)
+	forged := checkpoint(testKey(t, 2), 1, logs[2])
+
+	result, err := Verify(context.Background(), &chainSource{logs: logs, checkpoints: []*models.AuditCheckpoint{forged}}, nil)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !result.Valid || result.SignaturesVerified || result.CheckedCheckpoints != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+// Webhook request headers
+const (
+	headerWebhookEventID   = "X-Audit-Event-ID"
+	headerWebhookTimestamp = "X-Audit-Timestamp"
+	headerWebhookSignature = "X-Audit-Signature"
+)
+
+// WebhookConfig holds the settings of an HTTP webhook receiver
+type WebhookConfig struct {
+	URL    string
+	Secret string // HMAC-SHA256 key shared with the receiver
+}
+
+// WebhookSink posts each audit log as JSON. The X-Audit-Signature header is
+// "sha256=" followed by the hex HMAC-SHA256 of "<X-Audit-Timestamp>.<body>",
+// so receivers can authenticate the request and reject stale replays.
+type WebhookSink struct {
+	cfg    WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookSink creates a webhook sink
+func NewWebhookSink(cfg WebhookConfig) (*WebhookSink, error) {
+	endpoint, err := url.Parse(cfg.URL)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q", cfg.URL)
+	}
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("webhook secret is required")
+	}
+
+	return &WebhookSink{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name implements Sink
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Send implements Sink; any response other than 2xx is a failed delivery
+func (s *WebhookSink) Send(ctx context.Context, log *models.AuditLog) error {
+	body, err := json.Marshal(log)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit log: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(headerWebhookEventID, strconv.FormatInt(log.ID, 10))
+	req.Header.Set(headerWebhookTimestamp, timestamp)
+	req.Header.Set(headerWebhookSignature, "sha256="+s.sign(timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Close implements Sink
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// sign computes the hex HMAC-SHA256 of the timestamp and body
+func (s *WebhookSink) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
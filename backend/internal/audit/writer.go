@@ -0,0 +1,520 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// writeTimeout bounds a single batch insert
+const writeTimeout = 5 * time.Second
+
+// WriterConfig holds the settings of the asynchronous audit writer
+type WriterConfig struct {
+	QueueSize     int           // events buffered in memory
+	BatchSize     int           // maximum events per insert
+	FlushInterval time.Duration // maximum time an event waits for its batch
+	MaxRetries    int           // insert attempts before a batch is spilled
+	SpillPath     string        // NDJSON file for events that cannot be inserted, empty drops them
+}
+
+// deadLetterPath returns the NDJSON file for spilled events that can never be
+// inserted, kept next to the spill file for manual recovery
+func (c WriterConfig) deadLetterPath() string {
+	return c.SpillPath + ".dead"
+}
+
+// Writer records audit events asynchronously. Events are queued in memory and
+// inserted in batches by a single goroutine. A batch that still fails after
+// MaxRetries attempts, and any event arriving while the queue is full, is
+// appended to the spill file and replayed once inserts succeed again.
+// Events are only lost if they can be neither inserted nor spilled, or if
+// the store rejects them permanently; those are counted as rejected.
+// Spilled events keep their timestamp, so before replay each is checked
+// against the partitions: events older than every partition, whose month was
+// purged, are moved to the dead-letter file, and events of a later month
+// without a partition are moved to the current time.
+type Writer struct {
+	store      Store
+	partitions PartitionStore
+	cfg        WriterConfig
+	logger     *zap.Logger
+
+	mu     sync.RWMutex // guards closed against sends on the closed queue
+	closed bool
+	queue  chan *models.AuditLog
+	done   chan struct{}
+
+	spillMu  sync.Mutex
+	spilling atomic.Bool // the spill file may hold events
+
+	recorded      atomic.Uint64
+	written       atomic.Uint64
+	spilled       atomic.Uint64
+	replayed      atomic.Uint64
+	dropped       atomic.Uint64
+	rejected      atomic.Uint64
+	quarantined   atomic.Uint64
+	restamped     atomic.Uint64
+	failedBatches atomic.Uint64
+}
+
+// NewWriter creates an audit writer; call Run to start writing and Close to flush it.
+// partitions may be nil if the store is not partitioned.
+func NewWriter(store Store, partitions PartitionStore, cfg WriterConfig, logger *zap.Logger) *Writer {
+	w := &Writer{
+		store:      store,
+		partitions: partitions,
+		cfg:        cfg,
+		logger:     logger,
+		queue:      make(chan *models.AuditLog, cfg.QueueSize),
+		done:       make(chan struct{}),
+	}
+	if cfg.SpillPath != "" {
+		for _, path := range []string{cfg.SpillPath, cfg.SpillPath + ".replay"} {
+			if _, err := os.Stat(path); err == nil {
+				w.spilling.Store(true)
+			}
+		}
+	}
+	return w
+}
+
+// Record implements Recorder; it never blocks on the database
+func (w *Writer) Record(ctx context.Context, userID *uuid.UUID, ipAddress, userAgent string, event models.AuditEvent) {
+	log, err := NewLog(userID, ipAddress, userAgent, event)
+	if err != nil {
+		// An event type missing from the catalog is a programming error; it
+		// must show up in the metrics and not only in the logs
+		w.rejected.Add(1)
+		w.dropped.Add(1)
+		w.logger.Error("rejected audit event, it is lost",
+			zap.String("event", fmt.Sprintf("%T", event)), zap.Uint64("rejected", w.rejected.Load()), zap.Error(err))
+		return
+	}
+	w.recorded.Add(1)
+
+	w.mu.RLock()
+	if !w.closed {
+		select {
+		case w.queue <- log:
+			w.mu.RUnlock()
+			return
+		default:
+		}
+	}
+	w.mu.RUnlock()
+
+	// The queue is full or already closed
+	w.spill([]*models.AuditLog{log})
+}
+
+// Run writes queued events until Close is called. Spilled events from a
+// previous run are replayed first.
+func (w *Writer) Run() {
+	defer close(w.done)
+
+	w.replay()
+
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.AuditLog, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case log, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, log)
+			if len(batch) < w.cfg.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if w.spilling.Load() {
+				w.replay()
+			}
+		}
+
+		w.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+// Close stops accepting events into the queue and waits until the queued
+// events are written or spilled, or ctx expires
+func (w *Writer) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("audit writer did not finish flushing: %w", ctx.Err())
+	}
+}
+
+// Metrics reports the queue state and event counters
+func (w *Writer) Metrics() models.AuditWriterMetrics {
+	return models.AuditWriterMetrics{
+		QueueDepth:    len(w.queue),
+		QueueCapacity: cap(w.queue),
+		Recorded:      w.recorded.Load(),
+		Written:       w.written.Load(),
+		Spilled:       w.spilled.Load(),
+		Replayed:      w.replayed.Load(),
+		Dropped:       w.dropped.Load(),
+		Rejected:      w.rejected.Load(),
+		Quarantined:   w.quarantined.Load(),
+		Restamped:     w.restamped.Load(),
+		FailedBatches: w.failedBatches.Load(),
+		SpillPending:  w.spilling.Load(),
+	}
+}
+
+// flush writes a batch and spills the events that could not be inserted
+func (w *Writer) flush(batch []*models.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+
+	written, failed, err := w.write(batch)
+	w.written.Add(uint64(written))
+	if len(failed) > 0 {
+		w.logger.Error("failed to write audit events", zap.Int("events", len(failed)), zap.Error(err))
+		w.spill(failed)
+	}
+}
+
+// write inserts a batch, retrying with backoff, and returns the number of
+// events inserted and the events that could not be inserted yet. A batch that
+// still fails is split and its events are inserted one at a time, so a single
+// event the database rejects does not keep the rest of the batch out. Events
+// rejected permanently are dropped rather than returned, since retrying them
+// would fail forever. Splitting stops at the first timeout, since the
+// database is then unreachable rather than rejecting an event.
+func (w *Writer) write(batch []*models.AuditLog) (int, []*models.AuditLog, error) {
+	err := w.insert(batch)
+	if err == nil {
+		return len(batch), nil, nil
+	}
+
+	written := 0
+	var failed []*models.AuditLog
+	var lastErr error
+	for i, log := range batch {
+		logErr := err
+		if len(batch) > 1 {
+			ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+			logErr = w.store.CreateBatch(ctx, []*models.AuditLog{log})
+			cancel()
+		}
+
+		switch {
+		case logErr == nil:
+			written++
+		case errors.Is(logErr, ErrRejected):
+			w.rejected.Add(1)
+			w.drop([]*models.AuditLog{log}, logErr)
+		case errors.Is(logErr, context.DeadlineExceeded):
+			return written, append(failed, batch[i:]...), logErr
+		default:
+			failed = append(failed, log)
+			lastErr = logErr
+		}
+	}
+	return written, failed, lastErr
+}
+
+// insert stores a batch, making up to MaxRetries attempts. Permanent
+// rejections are returned at once.
+func (w *Writer) insert(batch []*models.AuditLog) error {
+	var err error
+	delay := 100 * time.Millisecond
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+		err = w.store.CreateBatch(ctx, batch)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		w.failedBatches.Add(1)
+		if attempt >= w.cfg.MaxRetries || errors.Is(err, ErrRejected) {
+			return err
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// spill appends events to the spill file, or drops them if spilling is
+// disabled or fails
+func (w *Writer) spill(logs []*models.AuditLog) {
+	if w.cfg.SpillPath == "" {
+		w.drop(logs, errors.New("no spill file configured"))
+		return
+	}
+
+	if err := w.appendSpill(logs); err != nil {
+		w.drop(logs, err)
+		return
+	}
+	w.spilled.Add(uint64(len(logs)))
+}
+
+// appendSpill appends events to the spill file as NDJSON
+func (w *Writer) appendSpill(logs []*models.AuditLog) error {
+	w.spillMu.Lock()
+	defer w.spillMu.Unlock()
+
+	if err := appendNDJSON(w.cfg.SpillPath, logs); err != nil {
+		return err
+	}
+
+	w.spilling.Store(true)
+	return nil
+}
+
+// appendNDJSON appends events to a file as NDJSON, creating it if needed
+func appendNDJSON(path string, logs []*models.AuditLog) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, log := range logs {
+		if err := encoder.Encode(log); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(buf.Bytes())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// drop counts and logs events that are lost
+func (w *Writer) drop(logs []*models.AuditLog, err error) {
+	w.dropped.Add(uint64(len(logs)))
+	for _, log := range logs {
+		w.logger.Error("dropped audit event",
+			zap.String("action", log.Action), zap.Time("timestamp", log.Timestamp), zap.Error(err))
+	}
+}
+
+// replay inserts spilled events in batches. The spill file is first moved
+// aside so recording is not blocked meanwhile; events that still cannot be
+// inserted are appended to the spill file again, so none is inserted twice.
+// Replay stops at the first batch of which no event can be inserted.
+func (w *Writer) replay() {
+	if w.cfg.SpillPath == "" || !w.spilling.Load() {
+		return
+	}
+
+	// A replay file left by an interrupted replay is processed before new spills
+	replayPath := w.cfg.SpillPath + ".replay"
+	w.spillMu.Lock()
+	if _, err := os.Stat(replayPath); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(w.cfg.SpillPath, replayPath); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				w.spilling.Store(false)
+			} else {
+				w.logger.Error("failed to move audit spill file", zap.Error(err))
+			}
+			w.spillMu.Unlock()
+			return
+		}
+	}
+	// Events spilled from now on are picked up by the next replay
+	_, err := os.Stat(w.cfg.SpillPath)
+	w.spilling.Store(err == nil)
+	w.spillMu.Unlock()
+
+	logs, err := readSpill(replayPath)
+	if err != nil {
+		w.logger.Error("failed to read audit spill file", zap.Error(err))
+		w.spilling.Store(true)
+		return
+	}
+
+	logs, err = w.checkPartitions(logs)
+	if err != nil {
+		// Keep the replay file; it is processed again on the next replay
+		w.logger.Error("failed to check audit partitions for spilled events", zap.Error(err))
+		w.spilling.Store(true)
+		return
+	}
+
+	var pending []*models.AuditLog
+	var writeErr error
+	for start := 0; start < len(logs); start += w.cfg.BatchSize {
+		end := min(start+w.cfg.BatchSize, len(logs))
+		written, failed, err := w.write(logs[start:end])
+		w.replayed.Add(uint64(written))
+		if len(failed) > 0 {
+			pending = append(pending, failed...)
+			writeErr = err
+		}
+		if written == 0 && len(failed) > 0 {
+			// Nothing could be inserted; keep the rest for the next replay
+			pending = append(pending, logs[end:]...)
+			break
+		}
+	}
+
+	if len(pending) > 0 {
+		w.logger.Warn("failed to replay spilled audit events", zap.Int("pending", len(pending)), zap.Error(writeErr))
+		if err := w.appendSpill(pending); err != nil {
+			// Keep the replay file; it is processed again on the next replay
+			w.logger.Error("failed to spill audit events again", zap.Error(err))
+			w.spilling.Store(true)
+			return
+		}
+	}
+
+	if err := os.Remove(replayPath); err != nil {
+		w.logger.Error("failed to remove audit replay file", zap.Error(err))
+	}
+}
+
+// checkPartitions returns the spilled events that can be inserted. Events
+// older than the oldest partition belong to a month purged by retention; no
+// partition can be recreated there without breaking the purge anchor, so they
+// are moved to the dead-letter file instead of failing on every replay.
+// Events of a later month without a partition, which the API may not create,
+// are moved to the current time with their original timestamp in the details.
+func (w *Writer) checkPartitions(logs []*models.AuditLog) ([]*models.AuditLog, error) {
+	if w.partitions == nil || len(logs) == 0 {
+		return logs, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	partitions, err := w.partitions.ListPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	existing := make(map[time.Time]bool, len(partitions))
+	for _, partition := range partitions {
+		existing[monthOf(partition.From)] = true
+	}
+
+	var kept, dead []*models.AuditLog
+	for _, log := range logs {
+		month := monthOf(log.Timestamp)
+		switch {
+		case existing[month]:
+			kept = append(kept, log)
+		case len(partitions) > 0 && month.Before(partitions[0].From):
+			dead = append(dead, log)
+		default:
+			original := log.Timestamp
+			if err := restamp(log, now); err != nil {
+				dead = append(dead, log)
+				continue
+			}
+			w.restamped.Add(1)
+			w.logger.Warn("moved spilled audit event without partition to the current time",
+				zap.String("action", log.Action), zap.Time("timestamp", original))
+			kept = append(kept, log)
+		}
+	}
+
+	if len(dead) > 0 {
+		if err := appendNDJSON(w.cfg.deadLetterPath(), dead); err != nil {
+			return nil, err
+		}
+		w.quarantined.Add(uint64(len(dead)))
+		w.logger.Error("moved spilled audit events of purged partitions to the dead-letter file",
+			zap.Int("events", len(dead)), zap.String("path", w.cfg.deadLetterPath()))
+	}
+
+	return kept, nil
+}
+
+// restamp moves a log to t, keeping its first original timestamp in the details
+func restamp(log *models.AuditLog, t time.Time) error {
+	var details map[string]json.RawMessage
+	if len(bytes.TrimSpace(log.Details)) > 0 {
+		if err := json.Unmarshal(log.Details, &details); err != nil {
+			return err
+		}
+	}
+	if details == nil {
+		details = map[string]json.RawMessage{}
+	}
+
+	if _, ok := details["original_timestamp"]; !ok {
+		original, err := json.Marshal(CanonicalTime(log.Timestamp))
+		if err != nil {
+			return err
+		}
+		details["original_timestamp"] = original
+	}
+
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	log.Details = raw
+	log.Timestamp = t
+	return nil
+}
+
+// monthOf returns the start of the UTC calendar month containing t, the lower
+// bound of the partition t is stored in
+func monthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// readSpill reads the events of a spill file, skipping corrupt lines
+func readSpill(path string) ([]*models.AuditLog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var logs []*models.AuditLog
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		log := &models.AuditLog{}
+		if err := json.Unmarshal(scanner.Bytes(), log); err != nil {
+			continue
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, scanner.Err()
+}
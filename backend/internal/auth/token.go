@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// APITokenPrefix marks API tokens so they are recognisable in logs and secret scanners
+const APITokenPrefix = "pwm_"
+
+// GenerateAPIToken generates a new API token and the hash to store for it
+func GenerateAPIToken() (string, []byte, error) {
+	bytes := make([]byte, 32) // 256 bits
+	if _, err := rand.Read(bytes); err != nil {
+		return "", nil, err
+	}
+	token := APITokenPrefix + hex.EncodeToString(bytes)
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken hashes an API token for storage and lookup. Tokens carry 256
+// bits of entropy, so a fast unsalted hash is sufficient.
+func HashAPIToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
@@ -11,16 +12,26 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	Redis     RedisConfig
-	Security  SecurityConfig
-	Session   SessionConfig
-	RateLimit RateLimitConfig
-	CORS      CORSConfig
-	TLS       TLSConfig
-	Argon2    Argon2Config
-	Logging   LoggingConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	Security   SecurityConfig
+	Session    SessionConfig
+	RateLimit  RateLimitConfig
+	CORS       CORSConfig
+	TLS        TLSConfig
+	Argon2     Argon2Config
+	Logging    LoggingConfig
+	Trash      TrashConfig
+	Pagination PaginationConfig
+	Batch      BatchConfig
+	Storage    StorageConfig
+	Attachment AttachmentConfig
+	Quota      QuotaConfig
+	Search     SearchConfig
+	Share      ShareConfig
+	Emergency  EmergencyAccessConfig
+	Audit      AuditConfig
 }
 
 // ServerConfig holds server-specific configuration
@@ -85,6 +96,88 @@ type LoggingConfig struct {
 	Level string
 }
 
+// TrashConfig holds soft delete retention configuration
+type TrashConfig struct {
+	RetentionDays int // days before trashed items are purged
+	PurgeInterval int // in seconds
+}
+
+// PaginationConfig holds list endpoint page size configuration
+type PaginationConfig struct {
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// BatchConfig holds batch entry operation configuration
+type BatchConfig struct {
+	MaxItems int // maximum operations per batch request
+}
+
+// StorageConfig holds blob storage configuration
+type StorageConfig struct {
+	Backend     string // "local" or "s3"
+	LocalPath   string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool
+}
+
+// AttachmentConfig holds attachment size limits; attachments count against QuotaConfig.MaxUserStorage
+type AttachmentConfig struct {
+	MaxSize int64 // in bytes, per attachment
+}
+
+// QuotaConfig holds vault and entry storage limits
+type QuotaConfig struct {
+	MaxEntrySize       int   // in bytes, per entry ciphertext
+	MaxEntriesPerVault int   // entries per vault, including trashed ones
+	MaxVaultsPerUser   int   // vaults per user, including trashed ones
+	MaxUserStorage     int64 // in bytes, entries, revisions and attachments per user
+}
+
+// SearchConfig holds blind index search limits
+type SearchConfig struct {
+	MaxEntryTokens int // maximum tokens stored per entry
+	MaxQueryTokens int // maximum tokens per search request
+	MaxResults     int // maximum entries returned per search
+}
+
+// ShareConfig holds share link limits
+type ShareConfig struct {
+	MaxSize                 int // in bytes, per share ciphertext
+	MaxTTLHours             int
+	AccessRequestsPerMinute int // per client for anonymous retrieval
+	PurgeInterval           int // in seconds
+}
+
+// EmergencyAccessConfig holds emergency access job configuration
+type EmergencyAccessConfig struct {
+	CheckInterval int // in seconds, how often expired wait periods are released
+}
+
+// AuditConfig holds audit trail integrity and forwarding configuration
+type AuditConfig struct {
+	SigningKey         string // hex-encoded Ed25519 seed for checkpoints, empty disables checkpoints
+	CheckpointInterval int    // in seconds, how often the chain head is signed
+	SyslogAddress      string // host:port of the syslog receiver, empty disables the syslog sink
+	SyslogNetwork      string // udp, tcp or tls
+	SyslogCAFile       string // PEM bundle trusted for tls, the system roots if empty
+	WebhookURL         string // empty disables the webhook sink
+	WebhookSecret      string // HMAC key signing webhook requests
+	OutboxInterval     int    // in seconds, how often pending deliveries are dispatched
+	OutboxBatchSize    int    // deliveries claimed per sink at a time
+	QueueSize          int    // audit events buffered in memory before spilling
+	BatchSize          int    // maximum audit events per insert
+	FlushInterval      int    // in milliseconds, maximum time an event waits for its batch
+	WriteRetries       int    // insert attempts before a batch is spilled
+	SpillPath          string // file for events that cannot be inserted, empty drops them; events of purged partitions go to SpillPath.dead
+	SecurityWindow     int    // in seconds, window in which repeated security events are aggregated
+	SecurityMaxKeys    int    // distinct security events tracked per window before they are only counted
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists (ignore error in production)
@@ -133,6 +226,68 @@ func Load() (*Config, error) {
 		Logging: LoggingConfig{
 			Level: getEnv("LOG_LEVEL", "info"),
 		},
+		Trash: TrashConfig{
+			RetentionDays: getEnvAsInt("TRASH_RETENTION_DAYS", 30),
+			PurgeInterval: getEnvAsInt("TRASH_PURGE_INTERVAL", 3600),
+		},
+		Pagination: PaginationConfig{
+			DefaultLimit: getEnvAsInt("PAGINATION_DEFAULT_LIMIT", 50),
+			MaxLimit:     getEnvAsInt("PAGINATION_MAX_LIMIT", 200),
+		},
+		Batch: BatchConfig{
+			MaxItems: getEnvAsInt("BATCH_MAX_ITEMS", 1000),
+		},
+		Storage: StorageConfig{
+			Backend:     getEnv("STORAGE_BACKEND", "local"),
+			LocalPath:   getEnv("STORAGE_LOCAL_PATH", "./data/attachments"),
+			S3Endpoint:  getEnv("S3_ENDPOINT", ""),
+			S3Region:    getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:    getEnv("S3_BUCKET", ""),
+			S3AccessKey: getEnv("S3_ACCESS_KEY", ""),
+			S3SecretKey: getEnv("S3_SECRET_KEY", ""),
+			S3PathStyle: getEnvAsBool("S3_PATH_STYLE", true),
+		},
+		Attachment: AttachmentConfig{
+			MaxSize: int64(getEnvAsInt("ATTACHMENT_MAX_SIZE", 10*1024*1024)),
+		},
+		Quota: QuotaConfig{
+			MaxEntrySize:       getEnvAsInt("QUOTA_MAX_ENTRY_SIZE", 64*1024),
+			MaxEntriesPerVault: getEnvAsInt("QUOTA_MAX_ENTRIES_PER_VAULT", 10000),
+			MaxVaultsPerUser:   getEnvAsInt("QUOTA_MAX_VAULTS_PER_USER", 100),
+			MaxUserStorage:     int64(getEnvAsInt("QUOTA_MAX_USER_STORAGE", 200*1024*1024)),
+		},
+		Search: SearchConfig{
+			MaxEntryTokens: getEnvAsInt("SEARCH_MAX_ENTRY_TOKENS", 512),
+			MaxQueryTokens: getEnvAsInt("SEARCH_MAX_QUERY_TOKENS", 64),
+			MaxResults:     getEnvAsInt("SEARCH_MAX_RESULTS", 200),
+		},
+		Share: ShareConfig{
+			MaxSize:                 getEnvAsInt("SHARE_MAX_SIZE", 64*1024),
+			MaxTTLHours:             getEnvAsInt("SHARE_MAX_TTL_HOURS", 30*24),
+			AccessRequestsPerMinute: getEnvAsInt("SHARE_ACCESS_REQUESTS_PER_MINUTE", 10),
+			PurgeInterval:           getEnvAsInt("SHARE_PURGE_INTERVAL", 3600),
+		},
+		Emergency: EmergencyAccessConfig{
+			CheckInterval: getEnvAsInt("EMERGENCY_ACCESS_CHECK_INTERVAL", 300),
+		},
+		Audit: AuditConfig{
+			SigningKey:         getEnv("AUDIT_SIGNING_KEY", ""),
+			CheckpointInterval: getEnvAsInt("AUDIT_CHECKPOINT_INTERVAL", 3600),
+			SyslogAddress:      getEnv("AUDIT_SYSLOG_ADDRESS", ""),
+			SyslogNetwork:      getEnv("AUDIT_SYSLOG_NETWORK", "udp"),
+			SyslogCAFile:       getEnv("AUDIT_SYSLOG_CA_FILE", ""),
+			WebhookURL:         getEnv("AUDIT_WEBHOOK_URL", ""),
+			WebhookSecret:      getEnv("AUDIT_WEBHOOK_SECRET", ""),
+			OutboxInterval:     getEnvAsInt("AUDIT_OUTBOX_INTERVAL", 5),
+			OutboxBatchSize:    getEnvAsInt("AUDIT_OUTBOX_BATCH_SIZE", 100),
+			QueueSize:          getEnvAsInt("AUDIT_QUEUE_SIZE", 10000),
+			BatchSize:          getEnvAsInt("AUDIT_BATCH_SIZE", 100),
+			FlushInterval:      getEnvAsInt("AUDIT_FLUSH_INTERVAL_MS", 200),
+			WriteRetries:       getEnvAsInt("AUDIT_WRITE_RETRIES", 3),
+			SpillPath:          getEnv("AUDIT_SPILL_PATH", "./data/audit-spill.ndjson"),
+			SecurityWindow:     getEnvAsInt("AUDIT_SECURITY_WINDOW", 60),
+			SecurityMaxKeys:    getEnvAsInt("AUDIT_SECURITY_MAX_KEYS", 10000),
+		},
 	}
 
 	// Validate required configuration
@@ -163,6 +318,75 @@ func (c *Config) Validate() error {
 	if len(c.Security.MasterEncryptionKey) < 32 {
 		return fmt.Errorf("MASTER_ENCRYPTION_KEY must be at least 32 characters")
 	}
+	if c.Trash.RetentionDays < 1 {
+		return fmt.Errorf("TRASH_RETENTION_DAYS must be at least 1")
+	}
+	if c.Trash.PurgeInterval < 1 {
+		return fmt.Errorf("TRASH_PURGE_INTERVAL must be at least 1")
+	}
+	if c.Pagination.DefaultLimit < 1 || c.Pagination.MaxLimit < c.Pagination.DefaultLimit {
+		return fmt.Errorf("PAGINATION_DEFAULT_LIMIT must be at least 1 and not exceed PAGINATION_MAX_LIMIT")
+	}
+	if c.Batch.MaxItems < 1 {
+		return fmt.Errorf("BATCH_MAX_ITEMS must be at least 1")
+	}
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.LocalPath == "" {
+			return fmt.Errorf("STORAGE_LOCAL_PATH is required for the local storage backend")
+		}
+	case "s3":
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" || c.Storage.S3AccessKey == "" || c.Storage.S3SecretKey == "" {
+			return fmt.Errorf("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required for the s3 storage backend")
+		}
+	default:
+		return fmt.Errorf("STORAGE_BACKEND must be local or s3")
+	}
+	if c.Attachment.MaxSize < 1 || c.Quota.MaxUserStorage < c.Attachment.MaxSize {
+		return fmt.Errorf("ATTACHMENT_MAX_SIZE must be at least 1 and not exceed QUOTA_MAX_USER_STORAGE")
+	}
+	if c.Quota.MaxEntrySize < 1 || c.Quota.MaxUserStorage < int64(c.Quota.MaxEntrySize) {
+		return fmt.Errorf("QUOTA_MAX_ENTRY_SIZE must be at least 1 and not exceed QUOTA_MAX_USER_STORAGE")
+	}
+	if c.Quota.MaxEntriesPerVault < 1 || c.Quota.MaxVaultsPerUser < 1 {
+		return fmt.Errorf("QUOTA_MAX_ENTRIES_PER_VAULT and QUOTA_MAX_VAULTS_PER_USER must be at least 1")
+	}
+	if c.Search.MaxEntryTokens < 1 || c.Search.MaxQueryTokens < 1 || c.Search.MaxResults < 1 {
+		return fmt.Errorf("SEARCH_MAX_ENTRY_TOKENS, SEARCH_MAX_QUERY_TOKENS and SEARCH_MAX_RESULTS must be at least 1")
+	}
+	if c.Share.MaxSize < 1 || c.Share.MaxTTLHours < 1 || c.Share.AccessRequestsPerMinute < 1 || c.Share.PurgeInterval < 1 {
+		return fmt.Errorf("SHARE_MAX_SIZE, SHARE_MAX_TTL_HOURS, SHARE_ACCESS_REQUESTS_PER_MINUTE and SHARE_PURGE_INTERVAL must be at least 1")
+	}
+	if c.Emergency.CheckInterval < 1 {
+		return fmt.Errorf("EMERGENCY_ACCESS_CHECK_INTERVAL must be at least 1")
+	}
+	if c.Audit.SigningKey != "" {
+		if key, err := hex.DecodeString(c.Audit.SigningKey); err != nil || len(key) != 32 {
+			return fmt.Errorf("AUDIT_SIGNING_KEY must be a 64-char hex string (32 bytes)")
+		}
+	}
+	if c.Audit.CheckpointInterval < 1 {
+		return fmt.Errorf("AUDIT_CHECKPOINT_INTERVAL must be at least 1")
+	}
+	if c.Audit.SyslogAddress != "" && c.Audit.SyslogNetwork != "udp" && c.Audit.SyslogNetwork != "tcp" && c.Audit.SyslogNetwork != "tls" {
+		return fmt.Errorf("AUDIT_SYSLOG_NETWORK must be udp, tcp or tls")
+	}
+	if c.Audit.WebhookURL != "" && len(c.Audit.WebhookSecret) < 32 {
+		return fmt.Errorf("AUDIT_WEBHOOK_SECRET must be at least 32 characters when AUDIT_WEBHOOK_URL is set")
+	}
+	if c.Audit.OutboxInterval < 1 || c.Audit.OutboxBatchSize < 1 {
+		return fmt.Errorf("AUDIT_OUTBOX_INTERVAL and AUDIT_OUTBOX_BATCH_SIZE must be at least 1")
+	}
+	if c.Audit.QueueSize < 1 || c.Audit.FlushInterval < 1 || c.Audit.WriteRetries < 1 {
+		return fmt.Errorf("AUDIT_QUEUE_SIZE, AUDIT_FLUSH_INTERVAL_MS and AUDIT_WRITE_RETRIES must be at least 1")
+	}
+	// Each row takes 9 bind parameters, Postgres allows 65535 per statement
+	if c.Audit.BatchSize < 1 || c.Audit.BatchSize > 1000 {
+		return fmt.Errorf("AUDIT_BATCH_SIZE must be between 1 and 1000")
+	}
+	if c.Audit.SecurityWindow < 1 || c.Audit.SecurityMaxKeys < 1 {
+		return fmt.Errorf("AUDIT_SECURITY_WINDOW and AUDIT_SECURITY_MAX_KEYS must be at least 1")
+	}
 	if c.TLS.Enabled {
 		if c.TLS.CertPath == "" || c.TLS.KeyPath == "" {
 			return fmt.Errorf("TLS_CERT_PATH and TLS_KEY_PATH are required when TLS is enabled")
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/auth"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// APITokenHandler handles API token management requests
+type APITokenHandler struct {
+	tokenRepo *repository.APITokenRepository
+	auditor   audit.Recorder
+	logger    *zap.Logger
+}
+
+// NewAPITokenHandler creates a new API token handler
+func NewAPITokenHandler(
+	tokenRepo *repository.APITokenRepository,
+	auditor audit.Recorder,
+	logger *zap.Logger,
+) *APITokenHandler {
+	return &APITokenHandler{
+		tokenRepo: tokenRepo,
+		auditor:   auditor,
+		logger:    logger,
+	}
+}
+
+// Create creates a new API token
+// @Summary      Create API token
+// @Description  Create a scoped bearer token for non-browser clients; the token is returned only once
+// @Tags         tokens
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.APITokenCreateRequest  true  "API Token Creation Request"
+// @Success      201      {object}  models.APITokenCreateResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /tokens [post]
+func (h *APITokenHandler) Create(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.APITokenCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		t := time.Now().Add(time.Duration(*req.ExpiresInDays) * 24 * time.Hour)
+		expiresAt = &t
+	}
+
+	secret, tokenHash, err := auth.GenerateAPIToken()
+	if err != nil {
+		h.logger.Error("failed to generate API token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	token, err := h.tokenRepo.Create(c.Request.Context(), userID, req.Name, tokenHash, req.Scopes, expiresAt)
+	if err != nil {
+		h.logger.Error("failed to create API token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.APITokenCreatedEvent{
+			TokenID: token.ID,
+			Name:    token.Name,
+			Scopes:  req.Scopes,
+		})
+
+	c.JSON(http.StatusCreated, models.APITokenCreateResponse{
+		APITokenResponse: token.ToResponse(),
+		Token:            secret,
+	})
+}
+
+// List lists the user's API tokens
+// @Summary      List API tokens
+// @Description  Get all API tokens of the current user, without their secrets
+// @Tags         tokens
+// @Produce      json
+// @Success      200  {array}   models.APITokenResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /tokens [get]
+func (h *APITokenHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tokens, err := h.tokenRepo.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list API tokens", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	responses := make([]models.APITokenResponse, len(tokens))
+	for i, token := range tokens {
+		responses[i] = token.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// Revoke revokes an API token
+// @Summary      Revoke API token
+// @Description  Revoke an API token; it can no longer be used
+// @Tags         tokens
+// @Produce      json
+// @Param        id   path      string  true  "Token ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /tokens/{id} [delete]
+func (h *APITokenHandler) Revoke(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token ID"})
+		return
+	}
+
+	if err := h.tokenRepo.Revoke(c.Request.Context(), tokenID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "token not found"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.APITokenRevokedEvent{
+			TokenID: tokenID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "token revoked successfully"})
+}
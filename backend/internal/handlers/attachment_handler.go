@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/SecurityByDesign/pwmanager/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Attachment metadata headers
+const (
+	headerAttachmentName  = "X-Attachment-Name"
+	headerAttachmentNonce = "X-Attachment-Nonce"
+	maxEncryptedNameBytes = 1024
+)
+
+// AttachmentHandler handles encrypted file attachment requests
+type AttachmentHandler struct {
+	attachmentRepo *repository.AttachmentRepository
+	entryRepo      *repository.EntryRepository
+	vaultRepo      *repository.VaultRepository
+	auditor        audit.Recorder
+	store          storage.BlobStore
+	maxSize        int64
+	logger         *zap.Logger
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(
+	attachmentRepo *repository.AttachmentRepository,
+	entryRepo *repository.EntryRepository,
+	vaultRepo *repository.VaultRepository,
+	auditor audit.Recorder,
+	store storage.BlobStore,
+	maxSize int64,
+	logger *zap.Logger,
+) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentRepo: attachmentRepo,
+		entryRepo:      entryRepo,
+		vaultRepo:      vaultRepo,
+		auditor:        auditor,
+		store:          store,
+		maxSize:        maxSize,
+		logger:         logger,
+	}
+}
+
+// Upload streams a client-encrypted file into the blob store
+// @Summary      Upload attachment
+// @Description  Upload a client-encrypted file as the raw request body. Content-Length is required and counted against the per-user storage quota, which also covers entries and their revisions. The encrypted file name and content nonce are passed as headers.
+// @Tags         attachments
+// @Accept       application/octet-stream
+// @Produce      json
+// @Param        id                  path      string  true  "Entry ID"
+// @Param        X-Attachment-Name   header    string  true  "Hex-encoded encrypted file name"
+// @Param        X-Attachment-Nonce  header    string  true  "Hex-encoded 12-byte content nonce"
+// @Success      201  {object}  models.AttachmentResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      411  {object}  map[string]string
+// @Failure      413  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /entries/{id}/attachments [post]
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	entry, ok := h.ownedEntry(c, userID, entryID)
+	if !ok {
+		return
+	}
+
+	size := c.Request.ContentLength
+	if size <= 0 {
+		c.JSON(http.StatusLengthRequired, gin.H{"error": "Content-Length is required"})
+		return
+	}
+	if size > h.maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("attachment too large: maximum is %d bytes", h.maxSize)})
+		return
+	}
+
+	encryptedName, err := hex.DecodeString(c.GetHeader(headerAttachmentName))
+	if err != nil || len(encryptedName) == 0 || len(encryptedName) > maxEncryptedNameBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + headerAttachmentName + ": must be hex string"})
+		return
+	}
+
+	nonce, err := hex.DecodeString(c.GetHeader(headerAttachmentNonce))
+	if err != nil || len(nonce) != 12 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + headerAttachmentNonce + ": must be 24-char hex string (12 bytes)"})
+		return
+	}
+
+	attachment, err := h.attachmentRepo.Reserve(c.Request.Context(), userID, entryID, encryptedName, nonce, size)
+	if err != nil {
+		if respondQuotaError(c, err) {
+			return
+		}
+		h.logger.Error("failed to reserve attachment", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Stream the body; never read more than the declared length
+	body := &countingReader{r: http.MaxBytesReader(c.Writer, c.Request.Body, size)}
+	if err := h.store.Put(c.Request.Context(), attachment.StorageKey, body, size); err != nil {
+		h.discard(c, attachment)
+		if body.n < size {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "upload incomplete"})
+			return
+		}
+		h.logger.Error("failed to store attachment", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if err := h.attachmentRepo.MarkCompleted(c.Request.Context(), attachment.ID); err != nil {
+		h.logger.Error("failed to complete attachment", zap.Error(err))
+		h.discard(c, attachment)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.AttachmentUploadedEvent{
+			AttachmentRef: models.AttachmentRef{
+				AttachmentID: attachment.ID,
+				EntryID:      entryID,
+				VaultID:      entry.VaultID,
+			},
+			Size: size,
+		})
+
+	c.JSON(http.StatusCreated, attachment.ToResponse())
+}
+
+// List lists the attachments of an entry
+// @Summary      List attachments
+// @Description  Get the metadata of all attachments of an entry
+// @Tags         attachments
+// @Produce      json
+// @Param        id   path      string  true  "Entry ID"
+// @Success      200  {array}   models.AttachmentResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /entries/{id}/attachments [get]
+func (h *AttachmentHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	if _, ok := h.ownedEntry(c, userID, entryID); !ok {
+		return
+	}
+
+	attachments, err := h.attachmentRepo.GetByEntryID(c.Request.Context(), entryID)
+	if err != nil {
+		h.logger.Error("failed to list attachments", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	responses := make([]models.AttachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		responses[i] = attachment.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// Download streams an encrypted attachment
+// @Summary      Download attachment
+// @Description  Download the encrypted file; the encrypted name and content nonce are returned as headers
+// @Tags         attachments
+// @Produce      application/octet-stream
+// @Param        id   path      string  true  "Attachment ID"
+// @Success      200  {file}    binary
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /attachments/{id} [get]
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	attachment, entry, ok := h.ownedAttachment(c, userID)
+	if !ok {
+		return
+	}
+
+	blob, err := h.store.Get(c.Request.Context(), attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+			return
+		}
+		h.logger.Error("failed to open attachment", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer func() { _ = blob.Close() }()
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.AttachmentDownloadedEvent{
+			AttachmentID: attachment.ID,
+			EntryID:      entry.ID,
+			VaultID:      entry.VaultID,
+		})
+
+	c.DataFromReader(http.StatusOK, attachment.Size, "application/octet-stream", blob, map[string]string{
+		headerAttachmentName:  hex.EncodeToString(attachment.EncryptedName),
+		headerAttachmentNonce: hex.EncodeToString(attachment.Nonce),
+		"Cache-Control":       "no-store",
+	})
+}
+
+// Delete deletes an attachment
+// @Summary      Delete attachment
+// @Description  Delete an attachment and its stored blob
+// @Tags         attachments
+// @Produce      json
+// @Param        id   path      string  true  "Attachment ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /attachments/{id} [delete]
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	attachment, entry, ok := h.ownedAttachment(c, userID)
+	if !ok {
+		return
+	}
+
+	// Detach first; if removing the blob fails the purge job retries it
+	if err := h.attachmentRepo.Detach(c.Request.Context(), attachment.ID); err != nil {
+		h.logger.Error("failed to delete attachment", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	h.discard(c, attachment)
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.AttachmentDeletedEvent{
+			AttachmentID: attachment.ID,
+			EntryID:      entry.ID,
+			VaultID:      entry.VaultID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "attachment deleted successfully"})
+}
+
+// ownedEntry loads a live entry and checks that its vault belongs to the user
+func (h *AttachmentHandler) ownedEntry(c *gin.Context, userID, entryID uuid.UUID) (*models.VaultEntry, bool) {
+	entry, err := h.entryRepo.GetByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return nil, false
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// ownedAttachment loads the attachment from the id parameter and checks access through its entry
+func (h *AttachmentHandler) ownedAttachment(c *gin.Context, userID uuid.UUID) (*models.Attachment, *models.VaultEntry, bool) {
+	attachmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid attachment ID"})
+		return nil, nil, false
+	}
+
+	attachment, err := h.attachmentRepo.GetByID(c.Request.Context(), attachmentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+		return nil, nil, false
+	}
+
+	entry, ok := h.ownedEntry(c, userID, *attachment.EntryID)
+	if !ok {
+		return nil, nil, false
+	}
+
+	return attachment, entry, true
+}
+
+// discard removes the blob and metadata of a failed or deleted upload.
+// Failures are left to the purge job.
+func (h *AttachmentHandler) discard(c *gin.Context, attachment *models.Attachment) {
+	if err := h.store.Delete(c.Request.Context(), attachment.StorageKey); err != nil {
+		h.logger.Warn("failed to delete attachment blob", zap.String("attachment_id", attachment.ID.String()), zap.Error(err))
+		return
+	}
+	if err := h.attachmentRepo.Delete(c.Request.Context(), attachment.ID); err != nil {
+		h.logger.Warn("failed to delete attachment", zap.String("attachment_id", attachment.ID.String()), zap.Error(err))
+	}
+}
+
+// countingReader counts the bytes read from the request body
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
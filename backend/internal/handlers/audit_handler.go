@@ -1,43 +1,80 @@
 package handlers
 
 import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
 	"github.com/SecurityByDesign/pwmanager/internal/middleware"
 	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
 	"github.com/SecurityByDesign/pwmanager/internal/repository"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // AuditHandler handles audit log requests
 type AuditHandler struct {
-	auditRepo *repository.AuditRepository
-	logger    *zap.Logger
+	auditRepo   *repository.AuditRepository
+	auditWriter *audit.Writer
+	stream      *audit.Stream
+	catalog     *audit.Catalog
+	pageLimits  pagination.Limits
+	publicKey   ed25519.PublicKey // Checkpoint verification key, nil if checkpoints are disabled
+	logger      *zap.Logger
 }
 
 // NewAuditHandler creates a new audit handler
 func NewAuditHandler(
 	auditRepo *repository.AuditRepository,
+	auditWriter *audit.Writer,
+	stream *audit.Stream,
+	catalog *audit.Catalog,
+	pageLimits pagination.Limits,
+	publicKey ed25519.PublicKey,
 	logger *zap.Logger,
 ) *AuditHandler {
 	return &AuditHandler{
-		auditRepo: auditRepo,
-		logger:    logger,
+		auditRepo:   auditRepo,
+		auditWriter: auditWriter,
+		stream:      stream,
+		catalog:     catalog,
+		pageLimits:  pageLimits,
+		publicKey:   publicKey,
+		logger:      logger,
 	}
 }
 
 // List retrieves audit logs for the current user
 // @Summary      List audit logs
-// @Description  Get audit logs for the authenticated user
+// @Description  Get audit logs for the authenticated user, optionally filtered. Action filters may end in ".*" to match a prefix such as vault.*; total counts all logs matching the filter.
 // @Tags         audit
 // @Produce      json
-// @Param        limit   query      int     false  "Number of logs to return"
-// @Param        offset  query      int     false  "Offset for pagination"
-// @Param        sort    query      string  false  "Sort order"
-// @Success      200     {array}    models.AuditLog
-// @Failure      401     {object}   map[string]string
-// @Failure      500     {object}   map[string]string
+// @Param        action    query      []string  false  "Action or action prefix (repeatable or comma-separated)"  collectionFormat(multi)
+// @Param        from      query      string    false  "Only logs at or after this time (RFC 3339)"
+// @Param        to        query      string    false  "Only logs before this time (RFC 3339)"
+// @Param        vault_id  query      string    false  "Only logs about this vault"
+// @Param        entry_id  query      string    false  "Only logs about this entry"
+// @Param        ip        query      string    false  "Only logs from this IP address or CIDR network"
+// @Param        cursor    query      string    false  "Cursor returned as next_cursor by the previous page"
+// @Param        limit     query      int       false  "Page size (capped at the server maximum)"
+// @Param        sort      query      string    false  "Sort column: timestamp (default)"
+// @Param        order     query      string    false  "Sort order: desc (default) or asc"
+// @Success      200       {object}   models.AuditLogListResponse
+// @Failure      400       {object}   map[string]string
+// @Failure      401       {object}   map[string]string
+// @Failure      500       {object}   map[string]string
 // @Router       /audit/logs [get]
 func (h *AuditHandler) List(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
@@ -46,24 +83,49 @@ func (h *AuditHandler) List(c *gin.Context) {
 		return
 	}
 
-	// Default pagination values
-	limit := 500
-	offset := 0
+	filter, err := h.parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := parsePage(c, h.pageLimits, repository.AuditSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Get audit logs for user
-	logs, err := h.auditRepo.GetByUserID(c.Request.Context(), userID, limit, offset)
+	logs, err := h.auditRepo.GetByUserID(c.Request.Context(), userID, filter, page)
 	if err != nil {
 		h.logger.Error("failed to list audit logs", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	total, err := h.auditRepo.CountByUserID(c.Request.Context(), userID, filter)
+	if err != nil {
+		h.logger.Error("failed to count audit logs", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
 	// Return empty array if no logs
 	if logs == nil {
 		logs = []*models.AuditLog{}
 	}
 
-	c.JSON(http.StatusOK, logs)
+	var nextCursor string
+	if len(logs) > page.Limit {
+		logs = logs[:page.Limit]
+		nextCursor = auditCursor(logs[len(logs)-1], page)
+	}
+
+	c.JSON(http.StatusOK, models.AuditLogListResponse{
+		Items:      logs,
+		NextCursor: nextCursor,
+		Total:      total,
+	})
 }
 
 // Get retrieves a single audit log by ID
@@ -111,3 +173,381 @@ func (h *AuditHandler) Get(c *gin.Context) {
 
 	c.JSON(http.StatusOK, log)
 }
+
+// Verify walks the audit hash chain and reports the first break
+// @Summary      Verify audit trail
+// @Description  Recompute the hash chain over all audit logs, check the signed checkpoints and report the first break (admin only)
+// @Tags         admin
+// @Produce      json
+// @Success      200     {object}   models.AuditVerification
+// @Failure      401     {object}   map[string]string
+// @Failure      403     {object}   map[string]string
+// @Failure      500     {object}   map[string]string
+// @Router       /admin/audit/verify [get]
+func (h *AuditHandler) Verify(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := audit.Verify(c.Request.Context(), h.auditRepo, h.publicKey)
+	if err != nil {
+		h.logger.Error("failed to verify audit chain", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !result.Valid {
+		h.logger.Warn("audit chain broken", zap.Any("break", result.FirstBreak))
+	}
+
+	event := models.AuditVerifiedEvent{
+		Valid:       result.Valid,
+		CheckedRows: result.CheckedRows,
+	}
+	if result.FirstBreak != nil {
+		event.BreakLogID = result.FirstBreak.LogID
+		event.BreakReason = result.FirstBreak.Reason
+	}
+
+	// Audit log
+	h.auditWriter.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(), event)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Export streams the current user's audit logs as a file
+// @Summary      Export audit logs
+// @Description  Stream the authenticated user's audit logs in chronological order as CSV, NDJSON or ArcSight CEF. Accepts the same filters as the list endpoint.
+// @Tags         audit
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      text/plain
+// @Param        format    query      string    false  "Export format: csv (default), ndjson or cef"
+// @Param        action    query      []string  false  "Action or action prefix (repeatable or comma-separated)"  collectionFormat(multi)
+// @Param        from      query      string    false  "Only logs at or after this time (RFC 3339)"
+// @Param        to        query      string    false  "Only logs before this time (RFC 3339)"
+// @Param        vault_id  query      string    false  "Only logs about this vault"
+// @Param        entry_id  query      string    false  "Only logs about this entry"
+// @Param        ip        query      string    false  "Only logs from this IP address or CIDR network"
+// @Success      200       {file}     file
+// @Failure      400       {object}   map[string]string
+// @Failure      401       {object}   map[string]string
+// @Failure      500       {object}   map[string]string
+// @Router       /audit/export [get]
+func (h *AuditHandler) Export(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	h.export(c, userID, &userID)
+}
+
+// ExportAll streams the audit logs of all users as a file
+// @Summary      Export global audit trail
+// @Description  Stream the audit logs of all users, including system events, in chronological order as CSV, NDJSON or ArcSight CEF (admin only). Accepts the same filters as the list endpoint.
+// @Tags         admin
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      text/plain
+// @Param        format    query      string    false  "Export format: csv (default), ndjson or cef"
+// @Param        action    query      []string  false  "Action or action prefix (repeatable or comma-separated)"  collectionFormat(multi)
+// @Param        from      query      string    false  "Only logs at or after this time (RFC 3339)"
+// @Param        to        query      string    false  "Only logs before this time (RFC 3339)"
+// @Param        vault_id  query      string    false  "Only logs about this vault"
+// @Param        entry_id  query      string    false  "Only logs about this entry"
+// @Param        ip        query      string    false  "Only logs from this IP address or CIDR network"
+// @Success      200       {file}     file
+// @Failure      400       {object}   map[string]string
+// @Failure      401       {object}   map[string]string
+// @Failure      403       {object}   map[string]string
+// @Failure      500       {object}   map[string]string
+// @Router       /admin/audit/export [get]
+func (h *AuditHandler) ExportAll(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	h.export(c, userID, nil)
+}
+
+// export streams the audit logs matching the request filters, restricted to
+// owner's logs unless owner is nil. Output is buffered so that a failure before
+// the first rows are sent still produces an error response; later failures can
+// only truncate the stream.
+func (h *AuditHandler) export(c *gin.Context, userID uuid.UUID, owner *uuid.UUID) {
+	filter, err := h.parseAuditFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", audit.ExportFormats[0])
+	out := bufio.NewWriterSize(c.Writer, 32*1024)
+	encoder, err := audit.NewEncoder(out, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	c.Header("Content-Type", audit.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+
+	var rows int64
+	err = h.auditRepo.Export(c.Request.Context(), owner, filter, func(log *models.AuditLog) error {
+		rows++
+		return encoder.Encode(log)
+	})
+	if err == nil {
+		err = encoder.Close()
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		h.logger.Error("failed to export audit logs", zap.Error(err), zap.Int64("rows", rows))
+		if !c.Writer.Written() {
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	scope := "own"
+	if owner == nil {
+		scope = "all"
+	}
+
+	// Audit log
+	h.auditWriter.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.AuditExportedEvent{
+			Format: format,
+			Scope:  scope,
+			Rows:   rows,
+		})
+}
+
+// Audit event stream settings
+const (
+	auditStreamBackfill    = 100              // missed events sent to a resuming client
+	auditStreamHeartbeat   = 25 * time.Second // keeps proxies from closing idle streams
+	auditStreamMaxDuration = 30 * time.Minute // the client reconnects, re-checking its session
+)
+
+// Stream pushes the current user's new audit events as Server-Sent Events
+// @Summary      Stream audit events
+// @Description  Push the authenticated user's audit events as they are stored, as Server-Sent Events of type "audit" whose data is the audit log and whose id is its ID. A client reconnecting with the Last-Event-ID header first receives up to 100 events it missed. The stream ends after 30 minutes; EventSource clients reconnect automatically.
+// @Tags         audit
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header     int     false  "ID of the last event received"
+// @Success      200            {string}   string  "Event stream"
+// @Failure      400            {object}   map[string]string
+// @Failure      401            {object}   map[string]string
+// @Failure      429            {object}   map[string]string
+// @Failure      503            {object}   map[string]string
+// @Router       /audit/stream [get]
+func (h *AuditHandler) Stream(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var lastID int64
+	if header := c.GetHeader("Last-Event-ID"); header != "" {
+		lastID, err = strconv.ParseInt(header, 10, 64)
+		if err != nil || lastID < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	// Subscribe before the backfill so no event falls in between
+	events, unsubscribe, err := h.stream.Subscribe(userID)
+	if err != nil {
+		if errors.Is(err, audit.ErrTooManyStreams) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many open audit event streams"})
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "audit event stream unavailable"})
+		return
+	}
+	defer unsubscribe()
+
+	var missed []*models.AuditLog
+	if lastID > 0 {
+		missed, err = h.auditRepo.GetByUserIDAfter(c.Request.Context(), userID, lastID, auditStreamBackfill)
+		if err != nil {
+			h.logger.Error("failed to get missed audit events", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Live events may repeat backfilled ones; IDs are not ordered across replicas,
+	// so duplicates are recognised by ID rather than skipping lower IDs
+	sent := make(map[int64]struct{}, len(missed))
+	for _, log := range missed {
+		if err := writeAuditEvent(c.Writer, log); err != nil {
+			return
+		}
+		sent[log.ID] = struct{}{}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(auditStreamHeartbeat)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(auditStreamMaxDuration)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case log, ok := <-events:
+			// Closed when this client fell behind or the server is shutting down
+			if !ok {
+				return
+			}
+			if _, ok := sent[log.ID]; ok {
+				continue
+			}
+			if err := writeAuditEvent(c.Writer, log); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeAuditEvent writes an audit log as a Server-Sent Event
+func writeAuditEvent(w io.Writer, log *models.AuditLog) error {
+	data, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: audit\ndata: %s\n\n", log.ID, data)
+	return err
+}
+
+// Schema describes audit logs and the details of every action
+// @Summary      Audit event schema
+// @Description  Get the JSON Schema (draft 2020-12) of audit logs as returned by the list, export and stream endpoints. The details of each action are defined under $defs, keyed by action.
+// @Tags         audit
+// @Produce      json
+// @Success      200     {object}   map[string]interface{}
+// @Failure      401     {object}   map[string]string
+// @Router       /audit/schema [get]
+func (h *AuditHandler) Schema(c *gin.Context) {
+	c.Data(http.StatusOK, "application/schema+json", h.catalog.Schema())
+}
+
+// Metrics reports the state of the asynchronous audit writer
+// @Summary      Audit writer metrics
+// @Description  Get the audit writer queue depth and the counts of written, spilled and dropped events since startup (admin only)
+// @Tags         admin
+// @Produce      json
+// @Success      200     {object}   models.AuditWriterMetrics
+// @Failure      401     {object}   map[string]string
+// @Failure      403     {object}   map[string]string
+// @Router       /admin/audit/metrics [get]
+func (h *AuditHandler) Metrics(c *gin.Context) {
+	c.JSON(http.StatusOK, h.auditWriter.Metrics())
+}
+
+// maxAuditActionFilters caps the number of action filters per request
+const maxAuditActionFilters = 20
+
+// auditActionPattern matches an action such as vault.created or a prefix such as vault.*
+var auditActionPattern = regexp.MustCompile(`^[a-z_]+(\.[a-z_]+)*(\.\*)?$`)
+
+// parseAuditFilter reads the optional audit log filters from the query string.
+// Actions that are not prefixes must be in the event catalog.
+func (h *AuditHandler) parseAuditFilter(c *gin.Context) (repository.AuditFilter, error) {
+	var filter repository.AuditFilter
+
+	for _, v := range c.QueryArray("action") {
+		for _, action := range strings.Split(v, ",") {
+			action = strings.TrimSpace(action)
+			if action == "" {
+				continue
+			}
+			if !auditActionPattern.MatchString(action) {
+				return filter, fmt.Errorf("invalid action: %s", action)
+			}
+			if !strings.HasSuffix(action, ".*") && !h.catalog.Known(action) {
+				return filter, fmt.Errorf("unknown action: %s", action)
+			}
+			filter.Actions = append(filter.Actions, action)
+		}
+	}
+	if len(filter.Actions) > maxAuditActionFilters {
+		return filter, fmt.Errorf("too many action filters: maximum is %d", maxAuditActionFilters)
+	}
+
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, errors.New("invalid from: must be an RFC 3339 timestamp")
+		}
+		filter.From = &from
+	}
+
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, errors.New("invalid to: must be an RFC 3339 timestamp")
+		}
+		filter.To = &to
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, errors.New("from must be before to")
+	}
+
+	if v := c.Query("vault_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, errors.New("invalid vault_id")
+		}
+		filter.VaultID = &id
+	}
+
+	if v := c.Query("entry_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, errors.New("invalid entry_id")
+		}
+		filter.EntryID = &id
+	}
+
+	if v := c.Query("ip"); v != "" {
+		if addr, err := netip.ParseAddr(v); err == nil {
+			filter.IP = addr.String()
+		} else if prefix, err := netip.ParsePrefix(v); err == nil {
+			filter.IP = prefix.Masked().String()
+		} else {
+			return filter, errors.New("invalid ip: must be an IP address or CIDR network")
+		}
+	}
+
+	return filter, nil
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
 	"github.com/SecurityByDesign/pwmanager/internal/auth"
 	"github.com/SecurityByDesign/pwmanager/internal/config"
 	"github.com/SecurityByDesign/pwmanager/internal/middleware"
@@ -25,7 +26,7 @@ import (
 type AuthHandler struct {
 	userRepo       *repository.UserRepository
 	mfaRepo        *repository.MFARepository
-	auditRepo      *repository.AuditRepository
+	auditor        audit.Recorder
 	sessionManager *auth.SessionManager
 	argon2Params   *crypto.Argon2Params
 	encryptionKey  string
@@ -37,7 +38,7 @@ type AuthHandler struct {
 func NewAuthHandler(
 	userRepo *repository.UserRepository,
 	mfaRepo *repository.MFARepository,
-	auditRepo *repository.AuditRepository,
+	auditor audit.Recorder,
 	sessionManager *auth.SessionManager,
 	argon2Params *crypto.Argon2Params,
 	encryptionKey string,
@@ -47,7 +48,7 @@ func NewAuthHandler(
 	return &AuthHandler{
 		userRepo:       userRepo,
 		mfaRepo:        mfaRepo,
-		auditRepo:      auditRepo,
+		auditor:        auditor,
 		sessionManager: sessionManager,
 		argon2Params:   argon2Params,
 		encryptionKey:  encryptionKey,
@@ -105,8 +106,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &user.ID, models.ActionUserRegistered,
-		middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+	h.auditor.Record(c.Request.Context(), &user.ID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.UserRegisteredEvent{})
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "user registered successfully",
@@ -138,10 +139,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	if err != nil {
 		// Don't reveal whether user exists
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-		_ = h.auditRepo.Create(c.Request.Context(), nil, models.ActionLoginFailed,
-			middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-				"email":  req.Email,
-				"reason": "user_not_found",
+		h.auditor.Record(c.Request.Context(), nil, middleware.GetClientIP(c), c.Request.UserAgent(),
+			models.LoginFailedEvent{
+				Email:  req.Email,
+				Reason: "user_not_found",
 			})
 		return
 	}
@@ -156,9 +157,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	if !valid {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
-		_ = h.auditRepo.Create(c.Request.Context(), &user.ID, models.ActionLoginFailed,
-			middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-				"reason": "invalid_password",
+		h.auditor.Record(c.Request.Context(), &user.ID, middleware.GetClientIP(c), c.Request.UserAgent(),
+			models.LoginFailedEvent{
+				Reason: "invalid_password",
 			})
 		return
 	}
@@ -189,14 +190,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		validMFA := totp.Validate(req.MFACode, string(secretBytes))
 		if !validMFA {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid mfa code"})
-			_ = h.auditRepo.Create(c.Request.Context(), &user.ID, models.ActionMFAFailed,
-				middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+			h.auditor.Record(c.Request.Context(), &user.ID, middleware.GetClientIP(c), c.Request.UserAgent(),
+				models.MFAFailedEvent{})
 			return
 		}
 
 		// Log MFA verification success
-		_ = h.auditRepo.Create(c.Request.Context(), &user.ID, models.ActionMFAVerified,
-			middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+		h.auditor.Record(c.Request.Context(), &user.ID, middleware.GetClientIP(c), c.Request.UserAgent(),
+			models.MFAVerifiedEvent{})
 	}
 
 	// Create session
@@ -220,8 +221,8 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	)
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &user.ID, models.ActionUserLogin,
-		middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+	h.auditor.Record(c.Request.Context(), &user.ID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.UserLoginEvent{})
 
 	userResp := user.ToResponse()
 	if mfa != nil {
@@ -268,8 +269,8 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	c.SetCookie("session_id", "", -1, "/", "", h.config.Session.SecureCookies, true)
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionUserLogout,
-		middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.UserLogoutEvent{})
 
 	c.JSON(http.StatusOK, gin.H{"message": "logout successful"})
 }
@@ -423,8 +424,8 @@ func (h *AuthHandler) SetupMFA(c *gin.Context) {
 	qrCodeBase64 := base64.StdEncoding.EncodeToString(buf.Bytes())
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionMFASetup,
-		middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.MFASetupEvent{})
 
 	c.JSON(http.StatusOK, models.MFASetupResponse{
 		Secret:      key.Secret(),
@@ -481,8 +482,8 @@ func (h *AuthHandler) VerifyMFA(c *gin.Context) {
 	valid := totp.Validate(req.Code, string(secretBytes))
 	if !valid {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid code"})
-		_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionMFAFailed,
-			middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+		h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+			models.MFAFailedEvent{})
 		return
 	}
 
@@ -495,10 +496,10 @@ func (h *AuthHandler) VerifyMFA(c *gin.Context) {
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionMFAVerified,
-		middleware.GetClientIP(c), c.Request.UserAgent(), nil)
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionMFAEnabled,
-		middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.MFAVerifiedEvent{})
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.MFAEnabledEvent{})
 
 	c.JSON(http.StatusOK, gin.H{"message": "MFA enabled successfully"})
 }
@@ -526,8 +527,8 @@ func (h *AuthHandler) DisableMFA(c *gin.Context) {
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionMFADisabled,
-		middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.MFADisabledEvent{})
 
 	c.JSON(http.StatusOK, gin.H{"message": "MFA disabled successfully"})
 }
@@ -609,8 +610,8 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, "user.password_changed",
-		middleware.GetClientIP(c), c.Request.UserAgent(), nil)
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.PasswordChangedEvent{})
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
 }
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AutofillHandler handles autofill domain hash requests. Registrable domain
+// normalisation and hashing happen client-side; the server only matches hashes.
+type AutofillHandler struct {
+	autofillRepo *repository.AutofillRepository
+	entryRepo    *repository.EntryRepository
+	vaultRepo    *repository.VaultRepository
+	auditor      audit.Recorder
+	logger       *zap.Logger
+}
+
+// NewAutofillHandler creates a new autofill handler
+func NewAutofillHandler(
+	autofillRepo *repository.AutofillRepository,
+	entryRepo *repository.EntryRepository,
+	vaultRepo *repository.VaultRepository,
+	auditor audit.Recorder,
+	logger *zap.Logger,
+) *AutofillHandler {
+	return &AutofillHandler{
+		autofillRepo: autofillRepo,
+		entryRepo:    entryRepo,
+		vaultRepo:    vaultRepo,
+		auditor:      auditor,
+		logger:       logger,
+	}
+}
+
+// SetEntryHashes replaces the autofill domain hashes of an entry
+// @Summary      Set entry autofill hashes
+// @Description  Replace the keyed hashes of the registrable domains an entry should be offered on
+// @Tags         autofill
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "Entry ID"
+// @Param        request  body      models.EntryAutofillRequest  true  "Entry Autofill Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /entries/{id}/autofill [put]
+func (h *AutofillHandler) SetEntryHashes(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	var req models.EntryAutofillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	hashes := decodeHexTokens(req.DomainHashes)
+	if err := h.autofillRepo.SetEntryHashes(c.Request.Context(), entryID, entry.VaultID, hashes); err != nil {
+		h.logger.Error("failed to set autofill hashes", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryAutofillUpdatedEvent{
+			EntryRef: models.EntryRef{
+				EntryID: entryID,
+				VaultID: entry.VaultID,
+			},
+			Hashes: len(hashes),
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "autofill hashes updated successfully"})
+}
+
+// Lookup finds the user's entries matching a site
+// @Summary      Autofill lookup
+// @Description  Return the entries across all of the user's vaults whose domain hash matches. Requires an API token with the autofill scope.
+// @Tags         autofill
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.AutofillLookupRequest  true  "Autofill Lookup Request"
+// @Success      200      {object}  models.AutofillLookupResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /autofill/lookup [post]
+func (h *AutofillHandler) Lookup(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.AutofillLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	matches, err := h.autofillRepo.Lookup(c.Request.Context(), userID, decodeHexTokens(req.DomainHashes))
+	if err != nil {
+		h.logger.Error("failed to look up autofill entries", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	event := models.AutofillLookupEvent{Matches: len(matches)}
+	if tokenID, ok := middleware.GetAPITokenID(c); ok {
+		event.TokenID = &tokenID
+	}
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(), event)
+
+	c.JSON(http.StatusOK, models.AutofillLookupResponse{Matches: matches})
+}
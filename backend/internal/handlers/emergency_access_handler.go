@@ -0,0 +1,606 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Emergency access parties
+const (
+	roleGrantor = "grantor"
+	roleGrantee = "grantee"
+)
+
+// EmergencyAccessHandler handles emergency access requests
+type EmergencyAccessHandler struct {
+	emergencyRepo *repository.EmergencyAccessRepository
+	vaultRepo     *repository.VaultRepository
+	entryRepo     *repository.EntryRepository
+	auditor       audit.Recorder
+	pageLimits    pagination.Limits
+	logger        *zap.Logger
+}
+
+// NewEmergencyAccessHandler creates a new emergency access handler
+func NewEmergencyAccessHandler(
+	emergencyRepo *repository.EmergencyAccessRepository,
+	vaultRepo *repository.VaultRepository,
+	entryRepo *repository.EntryRepository,
+	auditor audit.Recorder,
+	pageLimits pagination.Limits,
+	logger *zap.Logger,
+) *EmergencyAccessHandler {
+	return &EmergencyAccessHandler{
+		emergencyRepo: emergencyRepo,
+		vaultRepo:     vaultRepo,
+		entryRepo:     entryRepo,
+		auditor:       auditor,
+		pageLimits:    pageLimits,
+		logger:        logger,
+	}
+}
+
+// Invite grants emergency access to a trusted contact
+// @Summary      Invite emergency contact
+// @Description  Grant emergency access with a wait period and a wrapped key blob. The response is the same whether or not the email address is registered; an invitation to an unregistered address is linked to the user registering with it.
+// @Tags         emergency-access
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.EmergencyAccessInviteRequest  true  "Emergency Access Invite Request"
+// @Success      201      {object}  models.EmergencyAccessResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /emergency-access [post]
+func (h *EmergencyAccessHandler) Invite(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.EmergencyAccessInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	wrappedKey, err := hex.DecodeString(req.WrappedKey)
+	if err != nil || len(wrappedKey) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wrapped_key: must be hex string"})
+		return
+	}
+
+	// Unknown addresses are invited like registered ones, so the response
+	// does not tell whether an account exists
+	grant, err := h.emergencyRepo.Create(c.Request.Context(), userID, req.GranteeEmail, req.WaitDays, wrappedKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmergencyAccessExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, repository.ErrEmergencyAccessSelf) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to create emergency access", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.audit(c, grant, models.EmergencyAccessInvitedEvent{
+		EmergencyAccessRef: grant.AuditRef(roleGrantor),
+		WaitDays:           grant.WaitDays,
+	})
+
+	c.JSON(http.StatusCreated, grant.ToResponse())
+}
+
+// ListGranted lists the emergency access the user has granted
+// @Summary      List granted emergency access
+// @Description  Get the trusted contacts of the current user, including pending recovery requests
+// @Tags         emergency-access
+// @Produce      json
+// @Success      200  {array}   models.EmergencyAccessResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /emergency-access/granted [get]
+func (h *EmergencyAccessHandler) ListGranted(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grants, err := h.emergencyRepo.GetByGrantorID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list granted emergency access", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, emergencyAccessResponses(grants))
+}
+
+// ListTrusted lists the emergency access the user has received
+// @Summary      List trusted emergency access
+// @Description  Get the users who have named the current user as a trusted contact
+// @Tags         emergency-access
+// @Produce      json
+// @Success      200  {array}   models.EmergencyAccessResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /emergency-access/trusted [get]
+func (h *EmergencyAccessHandler) ListTrusted(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grants, err := h.emergencyRepo.GetByGranteeID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list trusted emergency access", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, emergencyAccessResponses(grants))
+}
+
+// Update changes the wait period or wrapped key of a grant
+// @Summary      Update emergency access
+// @Description  Change the wait period or replace the wrapped key, e.g. after a vault key rotation (grantor only)
+// @Tags         emergency-access
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                               true  "Emergency Access ID"
+// @Param        request  body      models.EmergencyAccessUpdateRequest  true  "Emergency Access Update Request"
+// @Success      200      {object}  models.EmergencyAccessResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /emergency-access/{id} [put]
+func (h *EmergencyAccessHandler) Update(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grant, ok := h.loadGrant(c, userID, roleGrantor)
+	if !ok {
+		return
+	}
+
+	var req models.EmergencyAccessUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	var wrappedKey []byte
+	if req.WrappedKey != "" {
+		wrappedKey, err = hex.DecodeString(req.WrappedKey)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wrapped_key: must be hex string"})
+			return
+		}
+	}
+
+	if req.WaitDays == nil && wrappedKey == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nothing to update: set wait_days or wrapped_key"})
+		return
+	}
+
+	grant, err = h.emergencyRepo.Update(c.Request.Context(), grant.ID, req.WaitDays, wrappedKey)
+	if err != nil {
+		h.logger.Error("failed to update emergency access", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.audit(c, grant, models.EmergencyAccessUpdatedEvent{
+		EmergencyAccessRef: grant.AuditRef(roleGrantor),
+		WaitDays:           grant.WaitDays,
+		KeyUpdated:         wrappedKey != nil,
+	})
+
+	c.JSON(http.StatusOK, grant.ToResponse())
+}
+
+// Delete revokes a grant (grantor) or declines it (grantee)
+// @Summary      Revoke emergency access
+// @Description  Delete a grant; the grantor revokes it or the grantee declines it, in any state
+// @Tags         emergency-access
+// @Produce      json
+// @Param        id   path      string  true  "Emergency Access ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /emergency-access/{id} [delete]
+func (h *EmergencyAccessHandler) Delete(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grant, ok := h.loadGrant(c, userID, "")
+	if !ok {
+		return
+	}
+
+	if err := h.emergencyRepo.Delete(c.Request.Context(), grant.ID); err != nil {
+		h.logger.Error("failed to delete emergency access", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	actor := roleGrantor
+	if grant.IsGrantee(userID) {
+		actor = roleGrantee
+	}
+	h.audit(c, grant, models.EmergencyAccessRevokedEvent{
+		EmergencyAccessRef: grant.AuditRef(actor),
+		PreviousStatus:     grant.Status,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "emergency access revoked successfully"})
+}
+
+// Accept accepts an invitation
+// @Summary      Accept emergency access
+// @Description  Accept an invitation to be a trusted contact (grantee only)
+// @Tags         emergency-access
+// @Produce      json
+// @Param        id   path      string  true  "Emergency Access ID"
+// @Success      200  {object}  models.EmergencyAccessResponse
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /emergency-access/{id}/accept [post]
+func (h *EmergencyAccessHandler) Accept(c *gin.Context) {
+	h.transition(c, roleGrantee, h.emergencyRepo.Accept, func(t models.EmergencyAccessTransition) models.AuditEvent {
+		return models.EmergencyAccessAcceptedEvent(t)
+	})
+}
+
+// RequestRecovery starts the wait period
+// @Summary      Request emergency access
+// @Description  Start the wait period; the key is released when it ends unless the grantor rejects the request (grantee only)
+// @Tags         emergency-access
+// @Produce      json
+// @Param        id   path      string  true  "Emergency Access ID"
+// @Success      200  {object}  models.EmergencyAccessResponse
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /emergency-access/{id}/request [post]
+func (h *EmergencyAccessHandler) RequestRecovery(c *gin.Context) {
+	h.transition(c, roleGrantee, h.emergencyRepo.RequestRecovery, func(t models.EmergencyAccessTransition) models.AuditEvent {
+		return models.EmergencyAccessRecoveryRequestedEvent(t)
+	})
+}
+
+// ApproveRecovery releases the key before the wait period ends
+// @Summary      Approve emergency access
+// @Description  Release the wrapped key to the grantee immediately (grantor only)
+// @Tags         emergency-access
+// @Produce      json
+// @Param        id   path      string  true  "Emergency Access ID"
+// @Success      200  {object}  models.EmergencyAccessResponse
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /emergency-access/{id}/approve [post]
+func (h *EmergencyAccessHandler) ApproveRecovery(c *gin.Context) {
+	h.transition(c, roleGrantor, h.emergencyRepo.ApproveRecovery, func(t models.EmergencyAccessTransition) models.AuditEvent {
+		return models.EmergencyAccessRecoveryApprovedEvent(t)
+	})
+}
+
+// RejectRecovery rejects a pending request or ends released access
+// @Summary      Reject emergency access
+// @Description  Reject a pending recovery request or withdraw released access; the grant returns to accepted (grantor only)
+// @Tags         emergency-access
+// @Produce      json
+// @Param        id   path      string  true  "Emergency Access ID"
+// @Success      200  {object}  models.EmergencyAccessResponse
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /emergency-access/{id}/reject [post]
+func (h *EmergencyAccessHandler) RejectRecovery(c *gin.Context) {
+	h.transition(c, roleGrantor, h.emergencyRepo.RejectRecovery, func(t models.EmergencyAccessTransition) models.AuditEvent {
+		return models.EmergencyAccessRecoveryRejectedEvent(t)
+	})
+}
+
+// GetKey returns the wrapped key once access has been released
+// @Summary      Get emergency access key
+// @Description  Get the wrapped key blob after the recovery request was approved (grantee only)
+// @Tags         emergency-access
+// @Produce      json
+// @Param        id   path      string  true  "Emergency Access ID"
+// @Success      200  {object}  models.EmergencyAccessKeyResponse
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /emergency-access/{id}/key [get]
+func (h *EmergencyAccessHandler) GetKey(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grant, ok := h.loadApprovedGrant(c, userID)
+	if !ok {
+		return
+	}
+
+	h.audit(c, grant, models.EmergencyAccessKeyAccessedEvent(grant.AuditRef(roleGrantee)))
+
+	c.JSON(http.StatusOK, grant.ToKeyResponse())
+}
+
+// ListVaults lists the grantor's vaults once access has been released
+// @Summary      List grantor vaults
+// @Description  Get the grantor's vaults after the recovery request was approved (grantee only, read-only)
+// @Tags         emergency-access
+// @Produce      json
+// @Param        id      path   string  true   "Emergency Access ID"
+// @Param        cursor  query  string  false  "Opaque cursor from a previous page"
+// @Param        limit   query  int     false  "Page size"
+// @Param        sort    query  string  false  "Sort column (created_at, updated_at, name)"
+// @Param        order   query  string  false  "Sort order (asc, desc)"
+// @Success      200  {object}  models.ListResponse[models.VaultResponse]
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /emergency-access/{id}/vaults [get]
+func (h *EmergencyAccessHandler) ListVaults(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grant, ok := h.loadApprovedGrant(c, userID)
+	if !ok {
+		return
+	}
+
+	page, err := parsePage(c, h.pageLimits, repository.VaultSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vaults, err := h.vaultRepo.GetByUserID(c.Request.Context(), grant.GrantorID, page)
+	if err != nil {
+		h.logger.Error("failed to list grantor vaults", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var nextCursor string
+	if len(vaults) > page.Limit {
+		vaults = vaults[:page.Limit]
+		nextCursor = vaultCursor(vaults[len(vaults)-1], page)
+	}
+
+	responses := make([]models.VaultResponse, len(vaults))
+	for i, vault := range vaults {
+		entryCount, err := h.entryRepo.CountByVaultID(c.Request.Context(), vault.ID)
+		if err != nil {
+			h.logger.Error("failed to count entries", zap.Error(err))
+			entryCount = 0
+		}
+
+		responses[i] = vault.ToResponse(entryCount)
+	}
+
+	c.JSON(http.StatusOK, models.ListResponse[models.VaultResponse]{
+		Items:      responses,
+		NextCursor: nextCursor,
+	})
+}
+
+// ListEntries lists the entries of a grantor vault once access has been released
+// @Summary      List grantor vault entries
+// @Description  Get the encrypted entries of one of the grantor's vaults after the recovery request was approved (grantee only, read-only)
+// @Tags         emergency-access
+// @Produce      json
+// @Param        id        path   string  true   "Emergency Access ID"
+// @Param        vault_id  path   string  true   "Vault ID"
+// @Param        cursor    query  string  false  "Opaque cursor from a previous page"
+// @Param        limit     query  int     false  "Page size"
+// @Param        sort      query  string  false  "Sort column (created_at, updated_at)"
+// @Param        order     query  string  false  "Sort order (asc, desc)"
+// @Success      200  {object}  models.ListResponse[models.VaultEntryResponse]
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /emergency-access/{id}/vaults/{vault_id}/entries [get]
+func (h *EmergencyAccessHandler) ListEntries(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grant, ok := h.loadApprovedGrant(c, userID)
+	if !ok {
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("vault_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	// The vault must belong to the grantor
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), vaultID, grant.GrantorID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	page, err := parsePage(c, h.pageLimits, repository.EntrySorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.audit(c, grant, models.EmergencyAccessVaultAccessedEvent{
+		EmergencyAccessRef: grant.AuditRef(roleGrantee),
+		VaultID:            vaultID,
+	})
+
+	entries, err := h.entryRepo.GetByVaultID(c.Request.Context(), vaultID, repository.EntryFilter{}, page)
+	if err != nil {
+		h.logger.Error("failed to list grantor entries", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var nextCursor string
+	if len(entries) > page.Limit {
+		entries = entries[:page.Limit]
+		nextCursor = entryCursor(entries[len(entries)-1], page)
+	}
+
+	responses := make([]models.VaultEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = entry.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, models.ListResponse[models.VaultEntryResponse]{
+		Items:      responses,
+		NextCursor: nextCursor,
+	})
+}
+
+// transition applies a state change performed by the given party and audits
+// it with the event built by newEvent
+func (h *EmergencyAccessHandler) transition(
+	c *gin.Context,
+	role string,
+	apply func(ctx context.Context, id uuid.UUID) (*models.EmergencyAccess, error),
+	newEvent func(models.EmergencyAccessTransition) models.AuditEvent,
+) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	grant, ok := h.loadGrant(c, userID, role)
+	if !ok {
+		return
+	}
+
+	previousStatus := grant.Status
+	grant, err = apply(c.Request.Context(), grant.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmergencyAccessState) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "status": previousStatus})
+			return
+		}
+		h.logger.Error("failed to update emergency access", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.audit(c, grant, newEvent(models.EmergencyAccessTransition{
+		EmergencyAccessRef: grant.AuditRef(role),
+		PreviousStatus:     previousStatus,
+		Status:             grant.Status,
+	}))
+
+	c.JSON(http.StatusOK, grant.ToResponse())
+}
+
+// loadGrant loads the grant from the id parameter and checks that the user is
+// the given party; an empty role accepts either party
+func (h *EmergencyAccessHandler) loadGrant(c *gin.Context, userID uuid.UUID, role string) (*models.EmergencyAccess, bool) {
+	grantID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid emergency access ID"})
+		return nil, false
+	}
+
+	grant, err := h.emergencyRepo.GetByID(c.Request.Context(), grantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "emergency access not found"})
+		return nil, false
+	}
+
+	isGrantor := grant.GrantorID == userID
+	isGrantee := grant.IsGrantee(userID)
+	if !isGrantor && !isGrantee {
+		c.JSON(http.StatusNotFound, gin.H{"error": "emergency access not found"})
+		return nil, false
+	}
+
+	if (role == roleGrantor && !isGrantor) || (role == roleGrantee && !isGrantee) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the " + role + " can do this"})
+		return nil, false
+	}
+
+	return grant, true
+}
+
+// loadApprovedGrant loads a grant of the grantee whose key has been released
+func (h *EmergencyAccessHandler) loadApprovedGrant(c *gin.Context, userID uuid.UUID) (*models.EmergencyAccess, bool) {
+	grant, ok := h.loadGrant(c, userID, roleGrantee)
+	if !ok {
+		return nil, false
+	}
+
+	if grant.Status != models.EmergencyAccessRecoveryApproved {
+		c.JSON(http.StatusConflict, gin.H{"error": "emergency access has not been approved", "status": grant.Status})
+		return nil, false
+	}
+
+	return grant, true
+}
+
+// audit records an emergency access event in both the grantor's and the
+// grantee's audit log; the grantor's entry doubles as the notification
+func (h *EmergencyAccessHandler) audit(c *gin.Context, grant *models.EmergencyAccess, event models.AuditEvent) {
+	ip := middleware.GetClientIP(c)
+	userAgent := c.Request.UserAgent()
+	h.auditor.Record(c.Request.Context(), &grant.GrantorID, ip, userAgent, event)
+	if grant.GranteeID != nil {
+		h.auditor.Record(c.Request.Context(), grant.GranteeID, ip, userAgent, event)
+	}
+}
+
+// emergencyAccessResponses converts grants to responses
+func emergencyAccessResponses(grants []*models.EmergencyAccess) []models.EmergencyAccessResponse {
+	responses := make([]models.EmergencyAccessResponse, len(grants))
+	for i, grant := range grants {
+		responses[i] = grant.ToResponse()
+	}
+	return responses
+}
@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// EntryBatchHandler handles batch entry operations
+type EntryBatchHandler struct {
+	entryRepo *repository.EntryRepository
+	auditor   audit.Recorder
+	maxItems  int
+	logger    *zap.Logger
+}
+
+// NewEntryBatchHandler creates a new entry batch handler
+func NewEntryBatchHandler(
+	entryRepo *repository.EntryRepository,
+	auditor audit.Recorder,
+	maxItems int,
+	logger *zap.Logger,
+) *EntryBatchHandler {
+	return &EntryBatchHandler{
+		entryRepo: entryRepo,
+		auditor:   auditor,
+		maxItems:  maxItems,
+		logger:    logger,
+	}
+}
+
+// batchPayload holds the decoded ciphertext of a batch item
+type batchPayload struct {
+	encryptedData []byte
+	nonce         []byte
+}
+
+// Apply applies a batch of entry operations atomically
+// @Summary      Batch entry operations
+// @Description  Create, update, delete (trash) and move many entries of a vault in a single transaction. Either all operations are applied or none.
+// @Tags         entries
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                    true  "Vault ID"
+// @Param        request  body      models.EntryBatchRequest  true  "Batch Request"
+// @Success      200      {object}  models.EntryBatchResponse
+// @Failure      400      {object}  models.EntryBatchResponse
+// @Failure      403      {object}  map[string]string
+// @Failure      422      {object}  models.EntryBatchResponse
+// @Failure      500      {object}  models.EntryBatchResponse
+// @Router       /vaults/{id}/entries/batch [post]
+func (h *EntryBatchHandler) Apply(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	var req models.EntryBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	if len(req.Operations) > h.maxItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many operations: maximum is %d", h.maxItems)})
+		return
+	}
+
+	resp := models.EntryBatchResponse{
+		Results: make([]models.EntryBatchResult, len(req.Operations)),
+	}
+	for i, item := range req.Operations {
+		resp.Results[i] = models.EntryBatchResult{
+			Index:   i,
+			Op:      item.Op,
+			Status:  models.EntryBatchStatusSkipped,
+			EntryID: item.EntryID,
+		}
+	}
+
+	// Validate every operation before touching the database
+	payloads := make([]batchPayload, len(req.Operations))
+	vaultIDs := []uuid.UUID{vaultID}
+	for i, item := range req.Operations {
+		payload, err := validateBatchItem(vaultID, item)
+		if err != nil {
+			resp.Results[i].Status = models.EntryBatchStatusFailed
+			resp.Results[i].Error = err.Error()
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+		payloads[i] = payload
+		if item.Op == models.EntryBatchMove {
+			vaultIDs = append(vaultIDs, *item.TargetVaultID)
+		}
+	}
+
+	tx, err := h.entryRepo.BeginTx(c.Request.Context())
+	if err != nil {
+		h.logger.Error("failed to begin entry batch", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Take the usage lock and check and lock the source and target vaults before
+	// any entry lock; every operation updates the counters
+	owns, err := tx.LockOwnedVaults(c.Request.Context(), userID, vaultIDs...)
+	if err != nil {
+		h.logger.Error("failed to lock vaults", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if !owns {
+		h.rejectUnownedVault(c, tx, userID, vaultID, req.Operations, resp)
+		return
+	}
+
+	for i, item := range req.Operations {
+		entry, status, err := h.applyItem(c, tx, userID, vaultID, item, payloads[i])
+		if err != nil {
+			if quotaErr, quota, ok := quotaStatus(err); ok {
+				status = quota
+				resp.Results[i].Code = quotaErr.Code
+			}
+			if status == http.StatusInternalServerError {
+				h.logger.Error("failed to apply entry batch operation", zap.Int("index", i), zap.Error(err))
+				err = fmt.Errorf("internal server error")
+			}
+			resp.Results[i].Status = models.EntryBatchStatusFailed
+			resp.Results[i].Error = err.Error()
+			markRolledBack(resp.Results[:i])
+			c.JSON(status, resp)
+			return
+		}
+
+		resp.Results[i].Status = models.EntryBatchStatusOK
+		if entry != nil {
+			resp.Results[i].EntryID = &entry.ID
+			entryResp := entry.ToResponse()
+			resp.Results[i].Entry = &entryResp
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.logger.Error("failed to commit entry batch", zap.Error(err))
+		markRolledBack(resp.Results)
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+	resp.Committed = true
+
+	// Audit log
+	for _, event := range batchAuditEvents(vaultID, req.Operations, resp.Results) {
+		h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(), event)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// batchAuditEvents returns the audit events of a committed batch: one summary
+// in the source vault's trail and, like a single transfer, an event in the
+// source and in the target vault's trail for every moved entry
+func batchAuditEvents(vaultID uuid.UUID, items []models.EntryBatchItem, results []models.EntryBatchResult) []models.AuditEvent {
+	counts := map[models.EntryBatchOp]int{}
+	for _, item := range items {
+		counts[item.Op]++
+	}
+
+	events := []models.AuditEvent{models.EntryBatchEvent{
+		VaultID:    vaultID,
+		Operations: len(items),
+		Created:    counts[models.EntryBatchCreate],
+		Updated:    counts[models.EntryBatchUpdate],
+		Deleted:    counts[models.EntryBatchDelete],
+		Moved:      counts[models.EntryBatchMove],
+	}}
+
+	for i, item := range items {
+		if item.Op != models.EntryBatchMove {
+			continue
+		}
+		transfer := models.EntryTransfer{
+			EntryID:       *item.EntryID,
+			TargetEntryID: *item.EntryID,
+			SourceVaultID: vaultID,
+			TargetVaultID: *item.TargetVaultID,
+		}
+		if results[i].EntryID != nil {
+			transfer.TargetEntryID = *results[i].EntryID
+		}
+
+		out, in := transfer, transfer
+		out.VaultID, out.Direction = vaultID, "out"
+		in.VaultID, in.Direction = *item.TargetVaultID, "in"
+		events = append(events, models.EntryMovedEvent(out), models.EntryMovedEvent(in))
+	}
+
+	return events
+}
+
+// rejectUnownedVault responds to a batch whose source vault or a move target
+// is not a live vault of the user. The vaults found are already locked, so
+// checking them one by one takes no new locks.
+func (h *EntryBatchHandler) rejectUnownedVault(c *gin.Context, tx *repository.EntryTx, userID, vaultID uuid.UUID, items []models.EntryBatchItem, resp models.EntryBatchResponse) {
+	ctx := c.Request.Context()
+
+	if owns, err := tx.LockOwnedVaults(ctx, userID, vaultID); err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	for i, item := range items {
+		if item.Op != models.EntryBatchMove {
+			continue
+		}
+		if owns, err := tx.LockOwnedVaults(ctx, userID, *item.TargetVaultID); err != nil || !owns {
+			resp.Results[i].Status = models.EntryBatchStatusFailed
+			resp.Results[i].Error = "target vault not found"
+			c.JSON(http.StatusBadRequest, resp)
+			return
+		}
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+}
+
+// validateBatchItem checks the fields required by an operation and decodes its ciphertext
+func validateBatchItem(vaultID uuid.UUID, item models.EntryBatchItem) (batchPayload, error) {
+	var payload batchPayload
+
+	if item.Op != models.EntryBatchCreate && item.EntryID == nil {
+		return payload, fmt.Errorf("entry_id is required for %s", item.Op)
+	}
+
+	if item.Op == models.EntryBatchMove {
+		if item.TargetVaultID == nil {
+			return payload, fmt.Errorf("target_vault_id is required for move")
+		}
+		if *item.TargetVaultID == vaultID {
+			return payload, fmt.Errorf("target_vault_id must differ from the source vault")
+		}
+	}
+
+	if item.Op == models.EntryBatchDelete {
+		return payload, nil
+	}
+
+	encryptedData, err := hex.DecodeString(item.EncryptedData)
+	if err != nil || len(encryptedData) == 0 {
+		return payload, fmt.Errorf("invalid encrypted_data: must be hex string")
+	}
+
+	nonce, err := hex.DecodeString(item.Nonce)
+	if err != nil || len(nonce) != 12 {
+		return payload, fmt.Errorf("invalid nonce: must be 24-char hex string (12 bytes)")
+	}
+
+	payload.encryptedData = encryptedData
+	payload.nonce = nonce
+	return payload, nil
+}
+
+// applyItem applies a single validated operation within the batch transaction.
+// On failure it returns the HTTP status the whole batch should fail with.
+func (h *EntryBatchHandler) applyItem(c *gin.Context, tx *repository.EntryTx, userID, vaultID uuid.UUID, item models.EntryBatchItem, payload batchPayload) (*models.VaultEntry, int, error) {
+	ctx := c.Request.Context()
+
+	if item.Op == models.EntryBatchCreate {
+		entry, err := tx.Create(ctx, vaultID, payload.encryptedData, payload.nonce, userID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return entry, http.StatusOK, nil
+	}
+
+	// Lock the entry and make sure it belongs to the vault of the request
+	current, err := tx.GetForUpdate(ctx, *item.EntryID)
+	if err != nil || current.VaultID != vaultID {
+		return nil, http.StatusUnprocessableEntity, fmt.Errorf("entry not found")
+	}
+
+	switch item.Op {
+	case models.EntryBatchUpdate:
+		entry, err := tx.Update(ctx, current.ID, payload.encryptedData, payload.nonce, userID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return entry, http.StatusOK, nil
+	case models.EntryBatchDelete:
+		if err := tx.Delete(ctx, current.ID); err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return nil, http.StatusOK, nil
+	default:
+		entry, err := tx.Move(ctx, current.ID, *item.TargetVaultID, payload.encryptedData, payload.nonce, userID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return entry, http.StatusOK, nil
+	}
+}
+
+// markRolledBack marks applied operations as rolled back and drops their payloads
+func markRolledBack(results []models.EntryBatchResult) {
+	for i := range results {
+		if results[i].Status == models.EntryBatchStatusOK {
+			results[i].Status = models.EntryBatchStatusRolledBack
+			results[i].Entry = nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestBatchAuditEventsRecordMovesInBothVaults(t *testing.T) {
+	source, targetA, targetB := uuid.New(), uuid.New(), uuid.New()
+	moved := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	deleted := uuid.New()
+
+	items := []models.EntryBatchItem{
+		{Op: models.EntryBatchCreate},
+		{Op: models.EntryBatchMove, EntryID: &moved[0], TargetVaultID: &targetA},
+		{Op: models.EntryBatchMove, EntryID: &moved[1], TargetVaultID: &targetA},
+		{Op: models.EntryBatchMove, EntryID: &moved[2], TargetVaultID: &targetB},
+		{Op: models.EntryBatchDelete, EntryID: &deleted},
+	}
+	results := make([]models.EntryBatchResult, len(items))
+	for i, item := range items {
+		results[i] = models.EntryBatchResult{Index: i, Op: item.Op, Status: models.EntryBatchStatusOK, EntryID: item.EntryID}
+	}
+
+	catalog, err := audit.DefaultCatalog()
+	if err != nil {
+		t.Fatalf("DefaultCatalog: %v", err)
+	}
+
+	summaries := map[uuid.UUID]models.EntryBatchEvent{}
+	trails := map[uuid.UUID]map[string][]uuid.UUID{}
+	for _, event := range batchAuditEvents(source, items, results) {
+		if err := catalog.Validate(event); err != nil {
+			t.Fatalf("invalid audit event %T: %v", event, err)
+		}
+
+		switch e := event.(type) {
+		case models.EntryBatchEvent:
+			summaries[e.VaultID] = e
+		case models.EntryMovedEvent:
+			if e.SourceVaultID != source {
+				t.Errorf("move of %s has source vault %s, want %s", e.EntryID, e.SourceVaultID, source)
+			}
+			if trails[e.VaultID] == nil {
+				trails[e.VaultID] = map[string][]uuid.UUID{}
+			}
+			trails[e.VaultID][e.Direction] = append(trails[e.VaultID][e.Direction], e.EntryID)
+		default:
+			t.Errorf("unexpected audit event %T", event)
+		}
+	}
+
+	summary, ok := summaries[source]
+	if !ok || len(summaries) != 1 {
+		t.Fatalf("want one batch summary in the source vault, got %v", summaries)
+	}
+	if summary.Operations != 5 || summary.Created != 1 || summary.Moved != 3 || summary.Deleted != 1 {
+		t.Errorf("unexpected batch summary %+v", summary)
+	}
+
+	want := map[uuid.UUID]map[string][]uuid.UUID{
+		source:  {"out": moved},
+		targetA: {"in": moved[:2]},
+		targetB: {"in": moved[2:]},
+	}
+	for vaultID, directions := range want {
+		for direction, entries := range directions {
+			got := trails[vaultID][direction]
+			if len(got) != len(entries) {
+				t.Errorf("vault %s: got %d %q events, want %d", vaultID, len(got), direction, len(entries))
+				continue
+			}
+			for i := range entries {
+				if got[i] != entries[i] {
+					t.Errorf("vault %s: %q event %d is for entry %s, want %s", vaultID, direction, i, got[i], entries[i])
+				}
+			}
+		}
+		if len(trails[vaultID]) != len(directions) {
+			t.Errorf("vault %s: unexpected directions %v", vaultID, trails[vaultID])
+		}
+	}
+}
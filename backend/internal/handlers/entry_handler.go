@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"encoding/hex"
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
 	"github.com/SecurityByDesign/pwmanager/internal/middleware"
 	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
 	"github.com/SecurityByDesign/pwmanager/internal/repository"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,24 +18,27 @@ import (
 
 // EntryHandler handles vault entry-related requests
 type EntryHandler struct {
-	entryRepo *repository.EntryRepository
-	vaultRepo *repository.VaultRepository
-	auditRepo *repository.AuditRepository
-	logger    *zap.Logger
+	entryRepo  *repository.EntryRepository
+	vaultRepo  *repository.VaultRepository
+	auditor    audit.Recorder
+	pageLimits pagination.Limits
+	logger     *zap.Logger
 }
 
 // NewEntryHandler creates a new entry handler
 func NewEntryHandler(
 	entryRepo *repository.EntryRepository,
 	vaultRepo *repository.VaultRepository,
-	auditRepo *repository.AuditRepository,
+	auditor audit.Recorder,
+	pageLimits pagination.Limits,
 	logger *zap.Logger,
 ) *EntryHandler {
 	return &EntryHandler{
-		entryRepo: entryRepo,
-		vaultRepo: vaultRepo,
-		auditRepo: auditRepo,
-		logger:    logger,
+		entryRepo:  entryRepo,
+		vaultRepo:  vaultRepo,
+		auditor:    auditor,
+		pageLimits: pageLimits,
+		logger:     logger,
 	}
 }
 
@@ -88,37 +95,40 @@ func (h *EntryHandler) Create(c *gin.Context) {
 	}
 
 	// Create entry
-	entry, err := h.entryRepo.Create(c.Request.Context(), vaultID, encryptedData, nonce)
+	entry, err := h.entryRepo.Create(c.Request.Context(), vaultID, encryptedData, nonce, userID)
 	if err != nil {
+		if respondQuotaError(c, err) {
+			return
+		}
 		h.logger.Error("failed to create entry", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionEntryCreated,
-		middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-			"vault_id": vaultID.String(),
-			"entry_id": entry.ID.String(),
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryCreatedEvent{
+			VaultID: vaultID,
+			EntryID: entry.ID,
 		})
 
-	c.JSON(http.StatusCreated, models.VaultEntryResponse{
-		ID:            entry.ID,
-		VaultID:       entry.VaultID,
-		EncryptedData: hex.EncodeToString(entry.EncryptedData),
-		Nonce:         hex.EncodeToString(entry.Nonce),
-		CreatedAt:     entry.CreatedAt,
-		UpdatedAt:     entry.UpdatedAt,
-	})
+	c.JSON(http.StatusCreated, entry.ToResponse())
 }
 
-// List lists all entries in a vault
+// List lists the entries in a vault
 // @Summary      List vault entries
-// @Description  Get all encrypted entries in a specific vault
+// @Description  Get a page of encrypted entries in a specific vault
 // @Tags         entries
 // @Produce      json
-// @Param        id   path      string  true  "Vault ID"
-// @Success      200  {array}   models.VaultEntryResponse
+// @Param        id      path      string  true   "Vault ID"
+// @Param        cursor  query     string  false  "Cursor returned as next_cursor by the previous page"
+// @Param        limit   query     int     false  "Page size (capped at the server maximum)"
+// @Param        sort    query     string  false  "Sort column: created_at (default) or updated_at"
+// @Param        order   query     string  false  "Sort order: desc (default) or asc"
+// @Param        folder_id  query  string  false  "Only entries directly in this folder"
+// @Param        favorite   query  bool    false  "Only favorite (true) or non-favorite (false) entries"
+// @Param        tag_id     query  string  false  "Only entries carrying this tag"
+// @Success      200  {object}  models.ListResponse[models.VaultEntryResponse]
 // @Failure      400  {object}  map[string]string
 // @Failure      403  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
@@ -143,34 +153,48 @@ func (h *EntryHandler) List(c *gin.Context) {
 		return
 	}
 
+	page, err := parsePage(c, h.pageLimits, repository.EntrySorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := parseEntryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionVaultAccessed,
-		middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-			"vault_id":  vaultID.String(),
-			"operation": "list_entries",
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.VaultAccessedEvent{
+			VaultID:   vaultID,
+			Operation: "list_entries",
 		})
 
-	entries, err := h.entryRepo.GetByVaultID(c.Request.Context(), vaultID)
+	entries, err := h.entryRepo.GetByVaultID(c.Request.Context(), vaultID, filter, page)
 	if err != nil {
 		h.logger.Error("failed to list entries", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	var nextCursor string
+	if len(entries) > page.Limit {
+		entries = entries[:page.Limit]
+		nextCursor = entryCursor(entries[len(entries)-1], page)
+	}
+
 	// Convert to response format
 	responses := make([]models.VaultEntryResponse, len(entries))
 	for i, entry := range entries {
-		responses[i] = models.VaultEntryResponse{
-			ID:            entry.ID,
-			VaultID:       entry.VaultID,
-			EncryptedData: hex.EncodeToString(entry.EncryptedData),
-			Nonce:         hex.EncodeToString(entry.Nonce),
-			CreatedAt:     entry.CreatedAt,
-			UpdatedAt:     entry.UpdatedAt,
-		}
+		responses[i] = entry.ToResponse()
 	}
 
-	c.JSON(http.StatusOK, responses)
+	c.JSON(http.StatusOK, models.ListResponse[models.VaultEntryResponse]{
+		Items:      responses,
+		NextCursor: nextCursor,
+	})
 }
 
 // Get retrieves a single entry
@@ -211,20 +235,13 @@ func (h *EntryHandler) Get(c *gin.Context) {
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionEntryAccessed,
-		middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-			"entry_id": entry.ID.String(),
-			"vault_id": entry.VaultID.String(),
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryAccessedEvent{
+			EntryID: entry.ID,
+			VaultID: entry.VaultID,
 		})
 
-	c.JSON(http.StatusOK, models.VaultEntryResponse{
-		ID:            entry.ID,
-		VaultID:       entry.VaultID,
-		EncryptedData: hex.EncodeToString(entry.EncryptedData),
-		Nonce:         hex.EncodeToString(entry.Nonce),
-		CreatedAt:     entry.CreatedAt,
-		UpdatedAt:     entry.UpdatedAt,
-	})
+	c.JSON(http.StatusOK, entry.ToResponse())
 }
 
 // Update updates an entry
@@ -287,24 +304,27 @@ func (h *EntryHandler) Update(c *gin.Context) {
 	}
 
 	// Update entry
-	if err := h.entryRepo.Update(c.Request.Context(), entryID, encryptedData, nonce); err != nil {
+	if err := h.entryRepo.Update(c.Request.Context(), entryID, encryptedData, nonce, userID); err != nil {
+		if respondQuotaError(c, err) {
+			return
+		}
 		h.logger.Error("failed to update entry", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionEntryUpdated,
-		middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-			"entry_id": entryID.String(),
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryUpdatedEvent{
+			EntryID: entryID,
 		})
 
 	c.JSON(http.StatusOK, gin.H{"message": "entry updated successfully"})
 }
 
-// Delete deletes an entry
+// Delete moves an entry to the trash
 // @Summary      Delete vault entry
-// @Description  Delete an encrypted entry
+// @Description  Move an encrypted entry to the trash
 // @Tags         entries
 // @Produce      json
 // @Param        id   path      string  true  "Entry ID"
@@ -348,10 +368,263 @@ func (h *EntryHandler) Delete(c *gin.Context) {
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionEntryDeleted,
-		middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-			"entry_id": entryID.String(),
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryDeletedEvent{
+			EntryID: entryID,
 		})
 
 	c.JSON(http.StatusOK, gin.H{"message": "entry deleted successfully"})
 }
+
+// SetFavorite marks or unmarks an entry as favorite
+// @Summary      Set entry favorite
+// @Description  Mark or unmark an entry as favorite
+// @Tags         entries
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "Entry ID"
+// @Param        request  body      models.EntryFavoriteRequest  true  "Favorite Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /entries/{id}/favorite [put]
+func (h *EntryHandler) SetFavorite(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	var req models.EntryFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	if err := h.entryRepo.SetFavorite(c.Request.Context(), entryID, *req.Favorite); err != nil {
+		h.logger.Error("failed to set entry favorite", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryFavoriteEvent{
+			EntryRef: models.EntryRef{
+				EntryID: entryID,
+				VaultID: entry.VaultID,
+			},
+			Favorite: *req.Favorite,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "entry updated successfully"})
+}
+
+// Move moves an entry into another vault
+// @Summary      Move entry to vault
+// @Description  Move an entry into another vault with data re-encrypted for that vault. Both vaults must belong to the user; earlier revisions, folder, tags and search data of the source vault are dropped.
+// @Tags         entries
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "Entry ID"
+// @Param        request  body      models.EntryTransferRequest  true  "Entry Transfer Request"
+// @Success      200      {object}  models.VaultEntryResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /entries/{id}/move [post]
+func (h *EntryHandler) Move(c *gin.Context) {
+	h.transfer(c, false)
+}
+
+// Copy copies an entry into another vault
+// @Summary      Copy entry to vault
+// @Description  Create a copy of an entry in another vault from data re-encrypted for that vault. Both vaults must belong to the user; attachments are not copied.
+// @Tags         entries
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "Entry ID"
+// @Param        request  body      models.EntryTransferRequest  true  "Entry Transfer Request"
+// @Success      201      {object}  models.VaultEntryResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /entries/{id}/copy [post]
+func (h *EntryHandler) Copy(c *gin.Context) {
+	h.transfer(c, true)
+}
+
+// transfer moves or copies an entry into another vault in one transaction,
+// holding locks on both vaults and the source entry
+func (h *EntryHandler) transfer(c *gin.Context, copyEntry bool) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	var req models.EntryTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	encryptedData, err := hex.DecodeString(req.EncryptedData)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encrypted_data: must be hex string"})
+		return
+	}
+
+	nonce, err := hex.DecodeString(req.Nonce)
+	if err != nil || len(nonce) != 12 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid nonce: must be 24-char hex string (12 bytes)"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tx, err := h.entryRepo.BeginTx(ctx)
+	if err != nil {
+		h.logger.Error("failed to begin entry transfer", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	source, err := h.entryRepo.GetByID(ctx, entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	if source.VaultID == req.TargetVaultID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target_vault_id must differ from the entry's vault"})
+		return
+	}
+
+	// Check and lock both vaults, then lock the entry and make sure it did not move meanwhile
+	owns, err := tx.LockOwnedVaults(ctx, userID, source.VaultID, req.TargetVaultID)
+	if err != nil {
+		h.logger.Error("failed to lock vaults", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	current, err := tx.GetForUpdate(ctx, entryID)
+	if err != nil || current.VaultID != source.VaultID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	var entry *models.VaultEntry
+	if copyEntry {
+		entry, err = tx.Create(ctx, req.TargetVaultID, encryptedData, nonce, userID)
+	} else {
+		entry, err = tx.Move(ctx, entryID, req.TargetVaultID, encryptedData, nonce, userID)
+	}
+	if err != nil {
+		if respondQuotaError(c, err) {
+			return
+		}
+		h.logger.Error("failed to transfer entry", zap.Bool("copy", copyEntry), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.logger.Error("failed to commit entry transfer", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log: one event in each vault's trail
+	status := http.StatusOK
+	if copyEntry {
+		status = http.StatusCreated
+	}
+	for _, side := range []struct {
+		vaultID   uuid.UUID
+		direction string
+	}{
+		{source.VaultID, "out"},
+		{req.TargetVaultID, "in"},
+	} {
+		transfer := models.EntryTransfer{
+			VaultID:       side.vaultID,
+			Direction:     side.direction,
+			EntryID:       entryID,
+			TargetEntryID: entry.ID,
+			SourceVaultID: source.VaultID,
+			TargetVaultID: req.TargetVaultID,
+		}
+		var event models.AuditEvent = models.EntryMovedEvent(transfer)
+		if copyEntry {
+			event = models.EntryCopiedEvent(transfer)
+		}
+		h.auditor.Record(ctx, &userID, middleware.GetClientIP(c), c.Request.UserAgent(), event)
+	}
+
+	c.JSON(status, entry.ToResponse())
+}
+
+// parseEntryFilter parses the folder_id, favorite and tag_id query parameters
+func parseEntryFilter(c *gin.Context) (repository.EntryFilter, error) {
+	var filter repository.EntryFilter
+
+	if v := c.Query("folder_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, errors.New("invalid folder_id")
+		}
+		filter.FolderID = &id
+	}
+
+	if v := c.Query("favorite"); v != "" {
+		favorite, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("invalid favorite: must be true or false")
+		}
+		filter.Favorite = &favorite
+	}
+
+	if v := c.Query("tag_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, errors.New("invalid tag_id")
+		}
+		filter.TagID = &id
+	}
+
+	return filter, nil
+}
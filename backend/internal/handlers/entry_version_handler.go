@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// EntryVersionHandler handles entry revision history requests
+type EntryVersionHandler struct {
+	versionRepo *repository.EntryVersionRepository
+	entryRepo   *repository.EntryRepository
+	vaultRepo   *repository.VaultRepository
+	auditor     audit.Recorder
+	logger      *zap.Logger
+}
+
+// NewEntryVersionHandler creates a new entry version handler
+func NewEntryVersionHandler(
+	versionRepo *repository.EntryVersionRepository,
+	entryRepo *repository.EntryRepository,
+	vaultRepo *repository.VaultRepository,
+	auditor audit.Recorder,
+	logger *zap.Logger,
+) *EntryVersionHandler {
+	return &EntryVersionHandler{
+		versionRepo: versionRepo,
+		entryRepo:   entryRepo,
+		vaultRepo:   vaultRepo,
+		auditor:     auditor,
+		logger:      logger,
+	}
+}
+
+// List lists the retained revisions of an entry
+// @Summary      List entry versions
+// @Description  Get the retained revisions of an entry (without ciphertext)
+// @Tags         entries
+// @Produce      json
+// @Param        id   path      string  true  "Entry ID"
+// @Success      200  {array}   models.EntryVersionSummary
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /entries/{id}/versions [get]
+func (h *EntryVersionHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	versions, err := h.versionRepo.GetByEntryID(c.Request.Context(), entryID)
+	if err != nil {
+		h.logger.Error("failed to list entry versions", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryVersionsListedEvent{
+			EntryID: entryID,
+			VaultID: entry.VaultID,
+		})
+
+	responses := make([]models.EntryVersionSummary, len(versions))
+	for i, v := range versions {
+		responses[i] = models.EntryVersionSummary{
+			Version:   v.Version,
+			CreatedBy: v.CreatedBy,
+			CreatedAt: v.CreatedAt,
+			Current:   v.Version == entry.Version,
+		}
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// Get retrieves a single revision of an entry
+// @Summary      Get entry version
+// @Description  Get a specific retained revision of an entry including its ciphertext
+// @Tags         entries
+// @Produce      json
+// @Param        id       path      string  true  "Entry ID"
+// @Param        version  path      int     true  "Version number"
+// @Success      200      {object}  models.EntryVersionResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Router       /entries/{id}/versions/{version} [get]
+func (h *EntryVersionHandler) Get(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	v, err := h.versionRepo.GetByVersion(c.Request.Context(), entryID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry version not found"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryVersionAccessedEvent{
+			EntryRef: models.EntryRef{
+				EntryID: entryID,
+				VaultID: entry.VaultID,
+			},
+			Version: version,
+		})
+
+	c.JSON(http.StatusOK, v.ToResponse())
+}
+
+// Restore restores a previous revision of an entry as a new revision
+// @Summary      Restore entry version
+// @Description  Restore a retained revision of an entry; the restored ciphertext becomes a new revision
+// @Tags         entries
+// @Produce      json
+// @Param        id       path      string  true  "Entry ID"
+// @Param        version  path      int     true  "Version number"
+// @Success      200      {object}  models.VaultEntryResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /entries/{id}/versions/{version}/restore [post]
+func (h *EntryVersionHandler) Restore(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	entry, err := h.entryRepo.GetByID(ctx, entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	tx, err := h.entryRepo.BeginTx(ctx)
+	if err != nil {
+		h.logger.Error("failed to begin entry restore", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Check and lock the vault so a key rotation cannot re-encrypt the entry and
+	// replace its revisions before the old ciphertext is written back
+	owns, err := tx.LockOwnedVaults(ctx, userID, entry.VaultID)
+	if err != nil {
+		h.logger.Error("failed to lock vault", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	current, err := tx.GetForUpdate(ctx, entryID)
+	if err != nil || current.VaultID != entry.VaultID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	if version == current.Version {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version is already current"})
+		return
+	}
+
+	v, err := tx.GetVersion(ctx, entryID, version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry version not found"})
+		return
+	}
+
+	// Write the old ciphertext as a new revision
+	restored, err := tx.Update(ctx, entryID, v.EncryptedData, v.Nonce, userID)
+	if err != nil {
+		if respondQuotaError(c, err) {
+			return
+		}
+		h.logger.Error("failed to restore entry version", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.logger.Error("failed to commit entry restore", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryVersionRestoredEvent{
+			EntryRef: models.EntryRef{
+				EntryID: entryID,
+				VaultID: entry.VaultID,
+			},
+			RestoredVersion: version,
+			NewVersion:      restored.Version,
+		})
+
+	c.JSON(http.StatusOK, restored.ToResponse())
+}
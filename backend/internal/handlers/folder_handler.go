@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// FolderHandler handles folder tree requests
+type FolderHandler struct {
+	folderRepo *repository.FolderRepository
+	entryRepo  *repository.EntryRepository
+	vaultRepo  *repository.VaultRepository
+	auditor    audit.Recorder
+	logger     *zap.Logger
+}
+
+// NewFolderHandler creates a new folder handler
+func NewFolderHandler(
+	folderRepo *repository.FolderRepository,
+	entryRepo *repository.EntryRepository,
+	vaultRepo *repository.VaultRepository,
+	auditor audit.Recorder,
+	logger *zap.Logger,
+) *FolderHandler {
+	return &FolderHandler{
+		folderRepo: folderRepo,
+		entryRepo:  entryRepo,
+		vaultRepo:  vaultRepo,
+		auditor:    auditor,
+		logger:     logger,
+	}
+}
+
+// Create creates a new folder in a vault
+// @Summary      Create folder
+// @Description  Create a folder with a client-encrypted name, optionally below a parent folder
+// @Tags         folders
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                      true  "Vault ID"
+// @Param        request  body      models.FolderCreateRequest  true  "Folder Creation Request"
+// @Success      201      {object}  models.FolderResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /vaults/{id}/folders [post]
+func (h *FolderHandler) Create(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	// Check vault ownership
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), vaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	var req models.FolderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	encryptedName, nonce, err := decodeEncryptedName(req.EncryptedName, req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ParentID != nil && !h.folderInVault(c, *req.ParentID, vaultID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parent folder not found in vault"})
+		return
+	}
+
+	folder, err := h.folderRepo.Create(c.Request.Context(), vaultID, req.ParentID, encryptedName, nonce)
+	if err != nil {
+		h.logger.Error("failed to create folder", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.FolderCreatedEvent{
+			FolderID: folder.ID,
+			VaultID:  vaultID,
+		})
+
+	c.JSON(http.StatusCreated, folder.ToResponse())
+}
+
+// List lists the folders of a vault
+// @Summary      List folders
+// @Description  Get all folders of a vault as a flat list; clients build the tree from parent_id
+// @Tags         folders
+// @Produce      json
+// @Param        id   path      string  true  "Vault ID"
+// @Success      200  {array}   models.FolderResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /vaults/{id}/folders [get]
+func (h *FolderHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	// Check vault ownership
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), vaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	folders, err := h.folderRepo.GetByVaultID(c.Request.Context(), vaultID)
+	if err != nil {
+		h.logger.Error("failed to list folders", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	responses := make([]models.FolderResponse, len(folders))
+	for i, folder := range folders {
+		responses[i] = folder.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// Rename renames a folder
+// @Summary      Rename folder
+// @Description  Replace the client-encrypted name of a folder
+// @Tags         folders
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                      true  "Folder ID"
+// @Param        request  body      models.FolderRenameRequest  true  "Folder Rename Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /folders/{id} [put]
+func (h *FolderHandler) Rename(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	folder, ok := h.ownedFolder(c, userID)
+	if !ok {
+		return
+	}
+
+	var req models.FolderRenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	encryptedName, nonce, err := decodeEncryptedName(req.EncryptedName, req.Nonce)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.folderRepo.Rename(c.Request.Context(), folder.ID, encryptedName, nonce); err != nil {
+		h.logger.Error("failed to rename folder", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.FolderRenamedEvent{
+			FolderID: folder.ID,
+			VaultID:  folder.VaultID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "folder renamed successfully"})
+}
+
+// Move moves a folder to a new parent
+// @Summary      Move folder
+// @Description  Move a folder below another folder of the same vault, or to the top level
+// @Tags         folders
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                    true  "Folder ID"
+// @Param        request  body      models.FolderMoveRequest  true  "Folder Move Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /folders/{id}/parent [put]
+func (h *FolderHandler) Move(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	folder, ok := h.ownedFolder(c, userID)
+	if !ok {
+		return
+	}
+
+	var req models.FolderMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	if req.ParentID != nil && !h.folderInVault(c, *req.ParentID, folder.VaultID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parent folder not found in vault"})
+		return
+	}
+
+	if err := h.folderRepo.Move(c.Request.Context(), folder.ID, req.ParentID); err != nil {
+		if errors.Is(err, repository.ErrFolderCycle) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to move folder", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.FolderMovedEvent{
+			FolderRef: models.FolderRef{
+				FolderID: folder.ID,
+				VaultID:  folder.VaultID,
+			},
+			OldParentID: folder.ParentID,
+			NewParentID: req.ParentID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "folder moved successfully"})
+}
+
+// Delete deletes a folder
+// @Summary      Delete folder
+// @Description  Delete a folder; its subfolders and entries move up to its parent
+// @Tags         folders
+// @Produce      json
+// @Param        id   path      string  true  "Folder ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /folders/{id} [delete]
+func (h *FolderHandler) Delete(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	folder, ok := h.ownedFolder(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.folderRepo.Delete(c.Request.Context(), folder.ID); err != nil {
+		h.logger.Error("failed to delete folder", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.FolderDeletedEvent{
+			FolderID: folder.ID,
+			VaultID:  folder.VaultID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "folder deleted successfully"})
+}
+
+// MoveEntry moves an entry into a folder
+// @Summary      Move entry to folder
+// @Description  Move an entry into a folder of its vault, or to the vault root when folder_id is null
+// @Tags         entries
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                     true  "Entry ID"
+// @Param        request  body      models.EntryFolderRequest  true  "Entry Folder Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /entries/{id}/folder [put]
+func (h *FolderHandler) MoveEntry(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	var req models.EntryFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	if req.FolderID != nil && !h.folderInVault(c, *req.FolderID, entry.VaultID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "folder not found in vault"})
+		return
+	}
+
+	if err := h.entryRepo.SetFolder(c.Request.Context(), entryID, req.FolderID); err != nil {
+		h.logger.Error("failed to move entry to folder", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryFolderChangedEvent{
+			EntryRef: models.EntryRef{
+				EntryID: entryID,
+				VaultID: entry.VaultID,
+			},
+			OldFolderID: entry.FolderID,
+			NewFolderID: req.FolderID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "entry moved successfully"})
+}
+
+// ownedFolder loads the folder from the id parameter and checks vault ownership
+func (h *FolderHandler) ownedFolder(c *gin.Context, userID uuid.UUID) (*models.Folder, bool) {
+	folderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid folder ID"})
+		return nil, false
+	}
+
+	folder, err := h.folderRepo.GetByID(c.Request.Context(), folderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+		return nil, false
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), folder.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return nil, false
+	}
+
+	return folder, true
+}
+
+// folderInVault reports whether a folder exists in the given vault
+func (h *FolderHandler) folderInVault(c *gin.Context, folderID, vaultID uuid.UUID) bool {
+	folder, err := h.folderRepo.GetByID(c.Request.Context(), folderID)
+	return err == nil && folder.VaultID == vaultID
+}
+
+// decodeEncryptedName decodes a hex-encoded encrypted name and its nonce
+func decodeEncryptedName(encryptedNameHex, nonceHex string) ([]byte, []byte, error) {
+	encryptedName, err := hex.DecodeString(encryptedNameHex)
+	if err != nil || len(encryptedName) == 0 {
+		return nil, nil, errors.New("invalid encrypted_name: must be hex string")
+	}
+
+	nonce, err := hex.DecodeString(nonceHex)
+	if err != nil || len(nonce) != 12 {
+		return nil, nil, errors.New("invalid nonce: must be 24-char hex string (12 bytes)")
+	}
+
+	return encryptedName, nonce, nil
+}
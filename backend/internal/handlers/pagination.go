@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// parsePage parses the cursor, limit, sort and order query parameters
+func parsePage(c *gin.Context, limits pagination.Limits, sorts pagination.Sorts) (pagination.Params, error) {
+	return pagination.Parse(c.Query("cursor"), c.Query("limit"), c.Query("sort"), c.Query("order"), limits, sorts)
+}
+
+// timeCursor builds a cursor from the value of a timestamp sort column
+func timeCursor(page pagination.Params, t time.Time, id string) string {
+	return page.Next(t.Format(time.RFC3339Nano), id)
+}
+
+// vaultCursor builds the cursor pointing after the given vault
+func vaultCursor(v *models.Vault, page pagination.Params) string {
+	switch page.Sort {
+	case "updated_at":
+		return timeCursor(page, v.UpdatedAt, v.ID.String())
+	case "name":
+		return page.Next(v.Name, v.ID.String())
+	default:
+		return timeCursor(page, v.CreatedAt, v.ID.String())
+	}
+}
+
+// entryCursor builds the cursor pointing after the given entry
+func entryCursor(e *models.VaultEntry, page pagination.Params) string {
+	if page.Sort == "updated_at" {
+		return timeCursor(page, e.UpdatedAt, e.ID.String())
+	}
+	return timeCursor(page, e.CreatedAt, e.ID.String())
+}
+
+// auditCursor builds the cursor pointing after the given audit log
+func auditCursor(l *models.AuditLog, page pagination.Params) string {
+	return timeCursor(page, l.Timestamp, strconv.FormatInt(l.ID, 10))
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/config"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SearchHandler handles blind index search requests. The server only stores
+// and compares opaque tokens; it never sees the search key or any plaintext.
+type SearchHandler struct {
+	searchRepo *repository.SearchRepository
+	entryRepo  *repository.EntryRepository
+	vaultRepo  *repository.VaultRepository
+	auditor    audit.Recorder
+	limits     config.SearchConfig
+	logger     *zap.Logger
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(
+	searchRepo *repository.SearchRepository,
+	entryRepo *repository.EntryRepository,
+	vaultRepo *repository.VaultRepository,
+	auditor audit.Recorder,
+	limits config.SearchConfig,
+	logger *zap.Logger,
+) *SearchHandler {
+	return &SearchHandler{
+		searchRepo: searchRepo,
+		entryRepo:  entryRepo,
+		vaultRepo:  vaultRepo,
+		auditor:    auditor,
+		limits:     limits,
+		logger:     logger,
+	}
+}
+
+// SetEntryTokens replaces the search tokens of an entry
+// @Summary      Set entry search tokens
+// @Description  Replace the blind index tokens of an entry; an empty list removes the entry from search
+// @Tags         search
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                           true  "Entry ID"
+// @Param        request  body      models.EntrySearchTokensRequest  true  "Entry Search Tokens Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /entries/{id}/search-tokens [put]
+func (h *SearchHandler) SetEntryTokens(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	var req models.EntrySearchTokensRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	tokens := decodeHexTokens(req.Tokens)
+	if len(tokens) > h.limits.MaxEntryTokens {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many tokens: at most %d allowed", h.limits.MaxEntryTokens)})
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	if err := h.searchRepo.SetEntryTokens(c.Request.Context(), entryID, entry.VaultID, tokens); err != nil {
+		h.logger.Error("failed to set search tokens", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryIndexedEvent{
+			EntryRef: models.EntryRef{
+				EntryID: entryID,
+				VaultID: entry.VaultID,
+			},
+			Tokens: len(tokens),
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "search tokens updated successfully"})
+}
+
+// Search finds entries of a vault by blind index tokens
+// @Summary      Search vault
+// @Description  Match client-computed blind index tokens against the vault's entries and return entry IDs ranked by matches
+// @Tags         search
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                     true  "Vault ID"
+// @Param        request  body      models.VaultSearchRequest  true  "Vault Search Request"
+// @Success      200      {object}  models.VaultSearchResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /vaults/{id}/search [post]
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	// Check vault ownership
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), vaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	var req models.VaultSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	tokens := decodeHexTokens(req.Tokens)
+	if len(tokens) > h.limits.MaxQueryTokens {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many tokens: at most %d allowed", h.limits.MaxQueryTokens)})
+		return
+	}
+
+	minMatches := len(tokens)
+	if req.Match == models.SearchMatchAny {
+		minMatches = 1
+	}
+
+	results, err := h.searchRepo.Search(c.Request.Context(), vaultID, tokens, minMatches, h.limits.MaxResults)
+	if err != nil {
+		h.logger.Error("failed to search vault", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log; tokens are not recorded since repeated tokens reveal repeated queries
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.VaultSearchedEvent{
+			VaultID: vaultID,
+			Tokens:  len(tokens),
+			Results: len(results),
+		})
+
+	c.JSON(http.StatusOK, models.VaultSearchResponse{Results: results})
+}
+
+// decodeHexTokens decodes hex tokens, dropping duplicates. The tokens have
+// already been validated as 64-char hex strings by the request binding.
+func decodeHexTokens(hexTokens []string) [][]byte {
+	seen := make(map[string]bool, len(hexTokens))
+	tokens := make([][]byte, 0, len(hexTokens))
+	for _, t := range hexTokens {
+		token, err := hex.DecodeString(t)
+		if err != nil || seen[string(token)] {
+			continue
+		}
+		seen[string(token)] = true
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
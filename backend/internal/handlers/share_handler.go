@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/config"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/SecurityByDesign/pwmanager/pkg/crypto"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ShareHandler handles share link requests
+type ShareHandler struct {
+	shareRepo    *repository.ShareRepository
+	auditor      audit.Recorder
+	argon2Params *crypto.Argon2Params
+	limits       config.ShareConfig
+	logger       *zap.Logger
+}
+
+// NewShareHandler creates a new share handler
+func NewShareHandler(
+	shareRepo *repository.ShareRepository,
+	auditor audit.Recorder,
+	argon2Params *crypto.Argon2Params,
+	limits config.ShareConfig,
+	logger *zap.Logger,
+) *ShareHandler {
+	return &ShareHandler{
+		shareRepo:    shareRepo,
+		auditor:      auditor,
+		argon2Params: argon2Params,
+		limits:       limits,
+		logger:       logger,
+	}
+}
+
+// Create creates a new share link
+// @Summary      Create share link
+// @Description  Store client-encrypted data under a random link ID. The decryption key belongs in the link's URL fragment and is never sent to the server.
+// @Tags         shares
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ShareCreateRequest  true  "Share Creation Request"
+// @Success      201      {object}  models.ShareResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      413      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /shares [post]
+func (h *ShareHandler) Create(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.ShareCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	if req.ExpiresInHours > h.limits.MaxTTLHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expires_in_hours must not exceed %d", h.limits.MaxTTLHours)})
+		return
+	}
+
+	encryptedData, err := hex.DecodeString(req.EncryptedData)
+	if err != nil || len(encryptedData) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid encrypted_data: must be hex string"})
+		return
+	}
+
+	if len(encryptedData) > h.limits.MaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("share exceeds maximum size of %d bytes", h.limits.MaxSize)})
+		return
+	}
+
+	nonce, err := hex.DecodeString(req.Nonce)
+	if err != nil || len(nonce) != 12 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid nonce: must be 24-char hex string (12 bytes)"})
+		return
+	}
+
+	var passwordHash *string
+	if req.Password != "" {
+		hash, err := crypto.HashPassword(req.Password, h.argon2Params)
+		if err != nil {
+			h.logger.Error("failed to hash share password", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		passwordHash = &hash
+	}
+
+	shareID, err := generateShareID()
+	if err != nil {
+		h.logger.Error("failed to generate share ID", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	share, err := h.shareRepo.Create(c.Request.Context(), &models.Share{
+		ID:            shareID,
+		UserID:        userID,
+		EncryptedData: encryptedData,
+		Nonce:         nonce,
+		PasswordHash:  passwordHash,
+		MaxViews:      req.MaxViews,
+		ExpiresAt:     time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour),
+	})
+	if err != nil {
+		h.logger.Error("failed to create share", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.ShareCreatedEvent{
+			ShareID:     share.ID,
+			MaxViews:    share.MaxViews,
+			ExpiresAt:   share.ExpiresAt.UTC(),
+			HasPassword: passwordHash != nil,
+		})
+
+	c.JSON(http.StatusCreated, share.ToResponse())
+}
+
+// List lists the user's share links
+// @Summary      List share links
+// @Description  Get all share links of the current user that have not been purged
+// @Tags         shares
+// @Produce      json
+// @Success      200  {array}   models.ShareResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /shares [get]
+func (h *ShareHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	shares, err := h.shareRepo.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list shares", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	responses := make([]models.ShareResponse, len(shares))
+	for i, share := range shares {
+		responses[i] = share.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// Revoke revokes a share link
+// @Summary      Revoke share link
+// @Description  Revoke a share link and delete its ciphertext
+// @Tags         shares
+// @Produce      json
+// @Param        id   path      string  true  "Share ID"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /shares/{id} [delete]
+func (h *ShareHandler) Revoke(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	shareID := c.Param("id")
+	if err := h.shareRepo.Revoke(c.Request.Context(), shareID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.ShareRevokedEvent{
+			ShareID: shareID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "share revoked successfully"})
+}
+
+// Info returns whether a share is available and needs a password
+// @Summary      Share link info
+// @Description  Check a share link without using up a view (public)
+// @Tags         shares
+// @Produce      json
+// @Param        id   path      string  true  "Share ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]string
+// @Failure      410  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Router       /public/shares/{id} [get]
+func (h *ShareHandler) Info(c *gin.Context) {
+	share, err := h.shareRepo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+
+	if !share.Available() {
+		c.JSON(http.StatusGone, gin.H{"error": repository.ErrShareUnavailable.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"has_password": share.PasswordHash != nil,
+		"expires_at":   share.ExpiresAt,
+	})
+}
+
+// Access retrieves the ciphertext of a share and counts a view
+// @Summary      Access share link
+// @Description  Retrieve a share's ciphertext anonymously; each successful call uses up one view (public)
+// @Tags         shares
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                     true   "Share ID"
+// @Param        request  body      models.ShareAccessRequest  false  "Share Access Request"
+// @Success      200      {object}  models.ShareContentResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      410      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /public/shares/{id} [post]
+func (h *ShareHandler) Access(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	var req models.ShareAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	share, err := h.shareRepo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+
+	if !share.Available() {
+		c.JSON(http.StatusGone, gin.H{"error": repository.ErrShareUnavailable.Error()})
+		return
+	}
+
+	if share.PasswordHash != nil {
+		valid, err := crypto.VerifyPassword(req.Password, *share.PasswordHash)
+		if err != nil || !valid {
+			// Audit log (owner's log)
+			h.auditor.Record(c.Request.Context(), &share.UserID, middleware.GetClientIP(c), c.Request.UserAgent(),
+				models.ShareAccessDeniedEvent{
+					ShareID: share.ID,
+				})
+
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid share password"})
+			return
+		}
+	}
+
+	share, err = h.shareRepo.Consume(c.Request.Context(), share.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrShareUnavailable) {
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to access share", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log (owner's log)
+	h.auditor.Record(c.Request.Context(), &share.UserID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.ShareAccessedEvent{
+			ShareID:   share.ID,
+			ViewCount: share.ViewCount,
+		})
+
+	c.JSON(http.StatusOK, share.ToContentResponse())
+}
+
+// generateShareID generates a random, URL-safe share link ID
+func generateShareID() (string, error) {
+	bytes := make([]byte, 16) // 128 bits
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
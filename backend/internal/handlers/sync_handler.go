@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SyncHandler handles delta synchronisation requests
+type SyncHandler struct {
+	syncRepo   *repository.SyncRepository
+	entryRepo  *repository.EntryRepository
+	auditor    audit.Recorder
+	pageLimits pagination.Limits
+	logger     *zap.Logger
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(
+	syncRepo *repository.SyncRepository,
+	entryRepo *repository.EntryRepository,
+	auditor audit.Recorder,
+	pageLimits pagination.Limits,
+	logger *zap.Logger,
+) *SyncHandler {
+	return &SyncHandler{
+		syncRepo:   syncRepo,
+		entryRepo:  entryRepo,
+		auditor:    auditor,
+		pageLimits: pageLimits,
+		logger:     logger,
+	}
+}
+
+// Sync returns vaults and entries changed since a cursor
+// @Summary      Delta sync
+// @Description  Get vaults and entries created, updated or deleted since the given cursor across all vaults of the user. Omit the cursor for a full sync.
+// @Tags         sync
+// @Produce      json
+// @Param        cursor  query     string  false  "Cursor returned by a previous sync"
+// @Param        limit   query     int     false  "Maximum number of changes (capped at the server maximum)"
+// @Success      200     {object}  models.SyncResponse
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /sync [get]
+func (h *SyncHandler) Sync(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	since, err := decodeSyncCursor(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	}
+
+	limit, err := pagination.ParseLimit(c.Query("limit"), h.pageLimits)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Fetch one extra change to detect further pages
+	changes, err := h.syncRepo.GetChanges(c.Request.Context(), userID, since, limit+1)
+	if err != nil {
+		h.logger.Error("failed to get sync changes", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	hasMore := len(changes) > limit
+	if hasMore {
+		changes = changes[:limit]
+	}
+
+	// Load payloads for changes that are not deletions
+	var vaultIDs, entryIDs []uuid.UUID
+	for _, change := range changes {
+		if change.Deleted {
+			continue
+		}
+		if change.Type == models.SyncChangeVault {
+			vaultIDs = append(vaultIDs, change.ID)
+		} else {
+			entryIDs = append(entryIDs, change.ID)
+		}
+	}
+
+	vaults, err := h.syncRepo.GetVaultsByIDs(c.Request.Context(), userID, vaultIDs)
+	if err != nil {
+		h.logger.Error("failed to load sync vaults", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	entries, err := h.syncRepo.GetEntriesByIDs(c.Request.Context(), userID, entryIDs)
+	if err != nil {
+		h.logger.Error("failed to load sync entries", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	vaultsByID := make(map[uuid.UUID]*models.Vault, len(vaults))
+	for _, vault := range vaults {
+		vaultsByID[vault.ID] = vault
+	}
+	entriesByID := make(map[uuid.UUID]*models.VaultEntry, len(entries))
+	for _, entry := range entries {
+		entriesByID[entry.ID] = entry
+	}
+
+	resp := models.SyncResponse{
+		Changes:    make([]models.SyncChangeResponse, 0, len(changes)),
+		NextCursor: encodeSyncCursor(since),
+		HasMore:    hasMore,
+	}
+
+	for _, change := range changes {
+		item := models.SyncChangeResponse{
+			Type:    change.Type,
+			ID:      change.ID,
+			VaultID: change.VaultID,
+			Deleted: change.Deleted,
+		}
+
+		// A row may have been trashed between both queries; report it as deleted
+		switch change.Type {
+		case models.SyncChangeVault:
+			if vault, ok := vaultsByID[change.ID]; ok {
+				entryCount, err := h.entryRepo.CountByVaultID(c.Request.Context(), vault.ID)
+				if err != nil {
+					h.logger.Error("failed to count entries", zap.Error(err))
+					entryCount = 0
+				}
+				vaultResp := vault.ToResponse(entryCount)
+				item.Vault = &vaultResp
+			} else {
+				item.Deleted = true
+			}
+		case models.SyncChangeEntry:
+			if entry, ok := entriesByID[change.ID]; ok {
+				entryResp := entry.ToResponse()
+				item.Entry = &entryResp
+			} else {
+				item.Deleted = true
+			}
+		}
+
+		resp.Changes = append(resp.Changes, item)
+		resp.NextCursor = encodeSyncCursor(change.Cursor())
+	}
+
+	// Audit log (only when ciphertext was actually handed out)
+	if len(changes) > 0 {
+		h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+			models.VaultSyncedEvent{
+				Changes: len(changes),
+				Full:    since == models.SyncCursor{},
+			})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// encodeSyncCursor encodes a sync position as an opaque cursor of the form "xid:seq"
+func encodeSyncCursor(cursor models.SyncCursor) string {
+	raw := strconv.FormatInt(cursor.XID, 10) + ":" + strconv.FormatInt(cursor.Seq, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSyncCursor decodes an opaque cursor; an empty cursor starts a full sync.
+// Cursors issued before changes carried transaction IDs hold only a change
+// sequence number; earlier changes all carry transaction ID 0, so they resume
+// from that number.
+func decodeSyncCursor(cursor string) (models.SyncCursor, error) {
+	if cursor == "" {
+		return models.SyncCursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return models.SyncCursor{}, err
+	}
+
+	xidPart, seqPart, found := strings.Cut(string(raw), ":")
+	if !found {
+		xidPart, seqPart = "0", xidPart
+	}
+
+	xid, err := strconv.ParseInt(xidPart, 10, 64)
+	if err != nil || xid < 0 {
+		return models.SyncCursor{}, strconv.ErrSyntax
+	}
+
+	seq, err := strconv.ParseInt(seqPart, 10, 64)
+	if err != nil || seq < 0 {
+		return models.SyncCursor{}, strconv.ErrSyntax
+	}
+
+	return models.SyncCursor{XID: xid, Seq: seq}, nil
+}
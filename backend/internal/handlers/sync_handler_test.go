@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+)
+
+func TestSyncCursorRoundTrips(t *testing.T) {
+	for _, cursor := range []models.SyncCursor{{}, {XID: 0, Seq: 42}, {XID: 987654, Seq: 12345678901}} {
+		got, err := decodeSyncCursor(encodeSyncCursor(cursor))
+		if err != nil {
+			t.Fatalf("decodeSyncCursor(encodeSyncCursor(%+v)): %v", cursor, err)
+		}
+		if got != cursor {
+			t.Errorf("cursor = %+v, want %+v", got, cursor)
+		}
+	}
+}
+
+func TestDecodeSyncCursor(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name    string
+		cursor  string
+		want    models.SyncCursor
+		wantErr bool
+	}{
+		{name: "empty starts a full sync", cursor: "", want: models.SyncCursor{}},
+		{name: "transaction and sequence", cursor: encode("100:7"), want: models.SyncCursor{XID: 100, Seq: 7}},
+		{name: "legacy sequence only", cursor: encode("42"), want: models.SyncCursor{XID: 0, Seq: 42}},
+		{name: "not base64", cursor: "!!!", wantErr: true},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte("1:23")), wantErr: true},
+		{name: "non-numeric transaction", cursor: encode("x:7"), wantErr: true},
+		{name: "non-numeric sequence", cursor: encode("100:y"), wantErr: true},
+		{name: "negative transaction", cursor: encode("-1:7"), wantErr: true},
+		{name: "negative sequence", cursor: encode("100:-7"), wantErr: true},
+		{name: "extra part", cursor: encode("1:2:3"), wantErr: true},
+		{name: "empty parts", cursor: encode(":"), wantErr: true},
+		{name: "overflow", cursor: encode("1:9223372036854775808"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeSyncCursor(tt.cursor)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("decodeSyncCursor accepted %q as %+v", tt.cursor, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeSyncCursor: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("cursor = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
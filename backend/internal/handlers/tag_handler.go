@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TagHandler handles tag requests
+type TagHandler struct {
+	tagRepo   *repository.TagRepository
+	entryRepo *repository.EntryRepository
+	vaultRepo *repository.VaultRepository
+	auditor   audit.Recorder
+	logger    *zap.Logger
+}
+
+// NewTagHandler creates a new tag handler
+func NewTagHandler(
+	tagRepo *repository.TagRepository,
+	entryRepo *repository.EntryRepository,
+	vaultRepo *repository.VaultRepository,
+	auditor audit.Recorder,
+	logger *zap.Logger,
+) *TagHandler {
+	return &TagHandler{
+		tagRepo:   tagRepo,
+		entryRepo: entryRepo,
+		vaultRepo: vaultRepo,
+		auditor:   auditor,
+		logger:    logger,
+	}
+}
+
+// Create creates a new tag in a vault
+// @Summary      Create tag
+// @Description  Create a tag identified by a client-computed blind ID, optionally with an encrypted name
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                   true  "Vault ID"
+// @Param        request  body      models.TagCreateRequest  true  "Tag Creation Request"
+// @Success      201      {object}  models.TagResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /vaults/{id}/tags [post]
+func (h *TagHandler) Create(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	// Check vault ownership
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), vaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	var req models.TagCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	blindID, err := hex.DecodeString(req.BlindID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid blind_id: must be 64-char hex string (32 bytes)"})
+		return
+	}
+
+	var encryptedName, nonce []byte
+	if req.EncryptedName != "" || req.Nonce != "" {
+		encryptedName, nonce, err = decodeEncryptedName(req.EncryptedName, req.Nonce)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	tag, err := h.tagRepo.Create(c.Request.Context(), vaultID, blindID, encryptedName, nonce)
+	if err != nil {
+		if errors.Is(err, repository.ErrTagExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to create tag", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.TagCreatedEvent{
+			TagID:   tag.ID,
+			VaultID: vaultID,
+		})
+
+	c.JSON(http.StatusCreated, tag.ToResponse(nil))
+}
+
+// List lists the tags of a vault with their entries
+// @Summary      List tags
+// @Description  Get all tags of a vault including the IDs of the live entries carrying each tag
+// @Tags         tags
+// @Produce      json
+// @Param        id   path      string  true  "Vault ID"
+// @Success      200  {array}   models.TagResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /vaults/{id}/tags [get]
+func (h *TagHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	// Check vault ownership
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), vaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	tags, err := h.tagRepo.GetByVaultID(c.Request.Context(), vaultID)
+	if err != nil {
+		h.logger.Error("failed to list tags", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	assignments, err := h.tagRepo.GetAssignments(c.Request.Context(), vaultID)
+	if err != nil {
+		h.logger.Error("failed to list tag assignments", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	entriesByTag := make(map[uuid.UUID][]uuid.UUID)
+	for _, a := range assignments {
+		entriesByTag[a.TagID] = append(entriesByTag[a.TagID], a.EntryID)
+	}
+
+	responses := make([]models.TagResponse, len(tags))
+	for i, tag := range tags {
+		responses[i] = tag.ToResponse(entriesByTag[tag.ID])
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// Delete deletes a tag
+// @Summary      Delete tag
+// @Description  Delete a tag and remove it from all entries
+// @Tags         tags
+// @Produce      json
+// @Param        id   path      string  true  "Tag ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /tags/{id} [delete]
+func (h *TagHandler) Delete(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	tagID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag ID"})
+		return
+	}
+
+	tag, err := h.tagRepo.GetByID(c.Request.Context(), tagID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), tag.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	if err := h.tagRepo.Delete(c.Request.Context(), tagID); err != nil {
+		h.logger.Error("failed to delete tag", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.TagDeletedEvent{
+			TagID:   tagID,
+			VaultID: tag.VaultID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "tag deleted successfully"})
+}
+
+// SetEntryTags replaces the tags of an entry
+// @Summary      Set entry tags
+// @Description  Replace the tags of an entry; all tags must belong to the entry's vault
+// @Tags         entries
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                   true  "Entry ID"
+// @Param        request  body      models.EntryTagsRequest  true  "Entry Tags Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /entries/{id}/tags [put]
+func (h *TagHandler) SetEntryTags(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	var req models.EntryTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	if err := h.tagRepo.SetEntryTags(c.Request.Context(), entryID, entry.VaultID, req.TagIDs); err != nil {
+		if errors.Is(err, repository.ErrTagNotInVault) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to set entry tags", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryTaggedEvent{
+			EntryRef: models.EntryRef{
+				EntryID: entryID,
+				VaultID: entry.VaultID,
+			},
+			Tags: len(req.TagIDs),
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "entry tags updated successfully"})
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TrashHandler handles trash listing, restore and purge requests
+type TrashHandler struct {
+	vaultRepo *repository.VaultRepository
+	entryRepo *repository.EntryRepository
+	auditor   audit.Recorder
+	retention time.Duration
+	logger    *zap.Logger
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(
+	vaultRepo *repository.VaultRepository,
+	entryRepo *repository.EntryRepository,
+	auditor audit.Recorder,
+	retention time.Duration,
+	logger *zap.Logger,
+) *TrashHandler {
+	return &TrashHandler{
+		vaultRepo: vaultRepo,
+		entryRepo: entryRepo,
+		auditor:   auditor,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// List lists all trashed vaults and entries of the current user
+// @Summary      List trash
+// @Description  Get all vaults and entries in the trash of the current user
+// @Tags         trash
+// @Produce      json
+// @Success      200  {object}  models.TrashResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /trash [get]
+func (h *TrashHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaults, err := h.vaultRepo.GetTrashedByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list trashed vaults", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	entries, err := h.entryRepo.GetTrashedByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to list trashed entries", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	resp := models.TrashResponse{
+		Vaults:        make([]models.VaultResponse, len(vaults)),
+		Entries:       make([]models.VaultEntryResponse, len(entries)),
+		RetentionDays: int(h.retention.Hours() / 24),
+	}
+	for i, vault := range vaults {
+		resp.Vaults[i] = vault.ToResponse(0)
+	}
+	for i, entry := range entries {
+		resp.Entries[i] = entry.ToResponse()
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RestoreVault moves a vault out of the trash
+// @Summary      Restore vault
+// @Description  Restore a trashed vault together with its entries
+// @Tags         trash
+// @Produce      json
+// @Param        id   path      string  true  "Vault ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /trash/vaults/{id}/restore [post]
+func (h *TrashHandler) RestoreVault(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	// Trashed items of other users are reported as not found
+	vault, err := h.vaultRepo.GetTrashedByID(c.Request.Context(), vaultID)
+	if err != nil || vault.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "vault not found in trash"})
+		return
+	}
+
+	if err := h.vaultRepo.Restore(c.Request.Context(), vaultID); err != nil {
+		h.logger.Error("failed to restore vault", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.VaultRestoredEvent{
+			VaultID: vaultID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "vault restored successfully"})
+}
+
+// PurgeVault permanently deletes a trashed vault
+// @Summary      Purge vault
+// @Description  Permanently delete a trashed vault and all its entries
+// @Tags         trash
+// @Produce      json
+// @Param        id   path      string  true  "Vault ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /trash/vaults/{id} [delete]
+func (h *TrashHandler) PurgeVault(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	vault, err := h.vaultRepo.GetTrashedByID(c.Request.Context(), vaultID)
+	if err != nil || vault.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "vault not found in trash"})
+		return
+	}
+
+	// Purge vault (will cascade to entries)
+	if err := h.vaultRepo.Purge(c.Request.Context(), vaultID); err != nil {
+		h.logger.Error("failed to purge vault", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.VaultPurgedEvent{
+			VaultID: vaultID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "vault purged successfully"})
+}
+
+// RestoreEntry moves an entry out of the trash
+// @Summary      Restore entry
+// @Description  Restore a trashed entry into its vault
+// @Tags         trash
+// @Produce      json
+// @Param        id   path      string  true  "Entry ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /trash/entries/{id}/restore [post]
+func (h *TrashHandler) RestoreEntry(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	entry, err := h.entryRepo.GetTrashedByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found in trash"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found in trash"})
+		return
+	}
+
+	if err := h.entryRepo.Restore(c.Request.Context(), entryID); err != nil {
+		h.logger.Error("failed to restore entry", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryRestoredEvent{
+			EntryID: entryID,
+			VaultID: entry.VaultID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "entry restored successfully"})
+}
+
+// PurgeEntry permanently deletes a trashed entry
+// @Summary      Purge entry
+// @Description  Permanently delete a trashed entry and its version history
+// @Tags         trash
+// @Produce      json
+// @Param        id   path      string  true  "Entry ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /trash/entries/{id} [delete]
+func (h *TrashHandler) PurgeEntry(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entry ID"})
+		return
+	}
+
+	entry, err := h.entryRepo.GetTrashedByID(c.Request.Context(), entryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found in trash"})
+		return
+	}
+
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), entry.VaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusNotFound, gin.H{"error": "entry not found in trash"})
+		return
+	}
+
+	if err := h.entryRepo.Purge(c.Request.Context(), entryID); err != nil {
+		h.logger.Error("failed to purge entry", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.EntryPurgedEvent{
+			EntryID: entryID,
+			VaultID: entry.VaultID,
+		})
+
+	c.JSON(http.StatusOK, gin.H{"message": "entry purged successfully"})
+}
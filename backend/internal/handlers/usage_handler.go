@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SecurityByDesign/pwmanager/internal/middleware"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// UsageHandler handles storage usage requests
+type UsageHandler struct {
+	usageRepo *repository.UsageRepository
+	quota     repository.Quota
+	logger    *zap.Logger
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(
+	usageRepo *repository.UsageRepository,
+	quota repository.Quota,
+	logger *zap.Logger,
+) *UsageHandler {
+	return &UsageHandler{
+		usageRepo: usageRepo,
+		quota:     quota,
+		logger:    logger,
+	}
+}
+
+// Get reports the current user's consumption against the configured limits
+// @Summary      Get usage
+// @Description  Get the current user's vault and storage usage together with the limits. Storage covers entries, their retained revisions and attachments; trashed items count until purged
+// @Tags         usage
+// @Produce      json
+// @Success      200  {object}  models.UsageResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /usage [get]
+func (h *UsageHandler) Get(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	usage, err := h.usageRepo.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Error("failed to get usage", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.UsageResponse{
+		Vaults:             models.UsageCounter{Used: int64(usage.VaultCount), Limit: int64(h.quota.MaxVaultsPerUser)},
+		StorageBytes:       models.UsageCounter{Used: usage.EntryBytes + usage.VersionBytes + usage.AttachmentBytes, Limit: h.quota.MaxUserStorage},
+		EntryBytes:         usage.EntryBytes,
+		VersionBytes:       usage.VersionBytes,
+		AttachmentBytes:    usage.AttachmentBytes,
+		MaxEntrySize:       h.quota.MaxEntrySize,
+		MaxEntriesPerVault: h.quota.MaxEntriesPerVault,
+		VaultEntries:       usage.Vaults,
+	})
+}
+
+// quotaStatus returns the HTTP status for a quota error, or false if err is not one.
+// Size limits map to 413, count limits to 403.
+func quotaStatus(err error) (*repository.QuotaError, int, bool) {
+	var quotaErr *repository.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return nil, 0, false
+	}
+
+	switch quotaErr.Code {
+	case repository.QuotaEntryTooLarge, repository.QuotaUserStorage:
+		return quotaErr, http.StatusRequestEntityTooLarge, true
+	default:
+		return quotaErr, http.StatusForbidden, true
+	}
+}
+
+// respondQuotaError writes the response for a quota error and reports whether err was one
+func respondQuotaError(c *gin.Context, err error) bool {
+	quotaErr, status, ok := quotaStatus(err)
+	if !ok {
+		return false
+	}
+
+	c.JSON(status, gin.H{"error": quotaErr.Error(), "code": quotaErr.Code, "limit": quotaErr.Limit})
+	return true
+}
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/hex"
+	"errors"
 	"net/http"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
 	"github.com/SecurityByDesign/pwmanager/internal/middleware"
 	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
 	"github.com/SecurityByDesign/pwmanager/internal/repository"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,24 +17,27 @@ import (
 
 // VaultHandler handles vault-related requests
 type VaultHandler struct {
-	vaultRepo *repository.VaultRepository
-	entryRepo *repository.EntryRepository
-	auditRepo *repository.AuditRepository
-	logger    *zap.Logger
+	vaultRepo  *repository.VaultRepository
+	entryRepo  *repository.EntryRepository
+	auditor    audit.Recorder
+	pageLimits pagination.Limits
+	logger     *zap.Logger
 }
 
 // NewVaultHandler creates a new vault handler
 func NewVaultHandler(
 	vaultRepo *repository.VaultRepository,
 	entryRepo *repository.EntryRepository,
-	auditRepo *repository.AuditRepository,
+	auditor audit.Recorder,
+	pageLimits pagination.Limits,
 	logger *zap.Logger,
 ) *VaultHandler {
 	return &VaultHandler{
-		vaultRepo: vaultRepo,
-		entryRepo: entryRepo,
-		auditRepo: auditRepo,
-		logger:    logger,
+		vaultRepo:  vaultRepo,
+		entryRepo:  entryRepo,
+		auditor:    auditor,
+		pageLimits: pageLimits,
+		logger:     logger,
 	}
 }
 
@@ -67,38 +73,58 @@ func (h *VaultHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// Resolve KDF settings (defaults to PBKDF2-SHA256)
+	kdfAlgorithm := req.KDFAlgorithm
+	if kdfAlgorithm == "" {
+		kdfAlgorithm = models.KDFPBKDF2SHA256
+	}
+	var kdfParams models.KDFParams
+	if req.KDFParams != nil {
+		kdfParams = *req.KDFParams
+	} else if defaults, ok := models.DefaultKDFParams(kdfAlgorithm); ok {
+		kdfParams = defaults
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kdf_params is required for " + kdfAlgorithm})
+		return
+	}
+	if err := models.ValidateKDF(kdfAlgorithm, kdfParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid kdf_params: " + err.Error()})
+		return
+	}
+
 	// Create vault
-	vault, err := h.vaultRepo.Create(c.Request.Context(), userID, req.Name, saltBytes)
+	vault, err := h.vaultRepo.Create(c.Request.Context(), userID, req.Name, saltBytes, kdfAlgorithm, kdfParams)
 	if err != nil {
+		if respondQuotaError(c, err) {
+			return
+		}
 		h.logger.Error("failed to create vault", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionVaultCreated,
-		middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-			"vault_id":   vault.ID.String(),
-			"vault_name": vault.Name,
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.VaultCreatedEvent{
+			VaultID:      vault.ID,
+			VaultName:    vault.Name,
+			KDFAlgorithm: vault.KDFAlgorithm,
 		})
 
-	c.JSON(http.StatusCreated, models.VaultResponse{
-		ID:             vault.ID,
-		UserID:         vault.UserID,
-		Name:           vault.Name,
-		EncryptionSalt: hex.EncodeToString(vault.EncryptionSalt),
-		EntriesCount:   0, // New vault has no entries
-		CreatedAt:      vault.CreatedAt,
-		UpdatedAt:      vault.UpdatedAt,
-	})
+	c.JSON(http.StatusCreated, vault.ToResponse(0)) // New vault has no entries
 }
 
-// List lists all vaults for the current user
+// List lists the vaults of the current user
 // @Summary      List vaults
-// @Description  Get all vaults belonging to the current user
+// @Description  Get a page of vaults belonging to the current user
 // @Tags         vaults
 // @Produce      json
-// @Success      200  {array}   models.VaultResponse
+// @Param        cursor  query     string  false  "Cursor returned as next_cursor by the previous page"
+// @Param        limit   query     int     false  "Page size (capped at the server maximum)"
+// @Param        sort    query     string  false  "Sort column: created_at (default), updated_at or name"
+// @Param        order   query     string  false  "Sort order: desc (default) or asc"
+// @Success      200  {object}  models.ListResponse[models.VaultResponse]
+// @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /vaults [get]
@@ -109,13 +135,25 @@ func (h *VaultHandler) List(c *gin.Context) {
 		return
 	}
 
-	vaults, err := h.vaultRepo.GetByUserID(c.Request.Context(), userID)
+	page, err := parsePage(c, h.pageLimits, repository.VaultSorts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	vaults, err := h.vaultRepo.GetByUserID(c.Request.Context(), userID, page)
 	if err != nil {
 		h.logger.Error("failed to list vaults", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	var nextCursor string
+	if len(vaults) > page.Limit {
+		vaults = vaults[:page.Limit]
+		nextCursor = vaultCursor(vaults[len(vaults)-1], page)
+	}
+
 	// Convert to response format
 	responses := make([]models.VaultResponse, len(vaults))
 	for i, vault := range vaults {
@@ -126,18 +164,13 @@ func (h *VaultHandler) List(c *gin.Context) {
 			entryCount = 0
 		}
 
-		responses[i] = models.VaultResponse{
-			ID:             vault.ID,
-			UserID:         vault.UserID,
-			Name:           vault.Name,
-			EncryptionSalt: hex.EncodeToString(vault.EncryptionSalt),
-			EntriesCount:   entryCount,
-			CreatedAt:      vault.CreatedAt,
-			UpdatedAt:      vault.UpdatedAt,
-		}
+		responses[i] = vault.ToResponse(entryCount)
 	}
 
-	c.JSON(http.StatusOK, responses)
+	c.JSON(http.StatusOK, models.ListResponse[models.VaultResponse]{
+		Items:      responses,
+		NextCursor: nextCursor,
+	})
 }
 
 // Get retrieves a single vault
@@ -192,20 +225,12 @@ func (h *VaultHandler) Get(c *gin.Context) {
 		entryCount = 0
 	}
 
-	c.JSON(http.StatusOK, models.VaultResponse{
-		ID:             vault.ID,
-		UserID:         vault.UserID,
-		Name:           vault.Name,
-		EncryptionSalt: hex.EncodeToString(vault.EncryptionSalt),
-		EntriesCount:   entryCount,
-		CreatedAt:      vault.CreatedAt,
-		UpdatedAt:      vault.UpdatedAt,
-	})
+	c.JSON(http.StatusOK, vault.ToResponse(entryCount))
 }
 
-// Update updates a vault's name
+// Update updates a vault's name and settings
 // @Summary      Update vault
-// @Description  Update the name of a vault
+// @Description  Update the name and version retention of a vault
 // @Tags         vaults
 // @Accept       json
 // @Produce      json
@@ -242,25 +267,26 @@ func (h *VaultHandler) Update(c *gin.Context) {
 		return
 	}
 
-	// Update vault
-	if err := h.vaultRepo.Update(c.Request.Context(), vaultID, req.Name); err != nil {
+	// Update the name and, if requested, the version retention together
+	if err := h.vaultRepo.Update(c.Request.Context(), vaultID, req.Name, req.VersionRetention); err != nil {
 		h.logger.Error("failed to update vault", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionVaultUpdated,
-		middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-			"vault_id": vaultID.String(),
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.VaultUpdatedEvent{
+			VaultID:          vaultID,
+			VersionRetention: req.VersionRetention,
 		})
 
 	c.JSON(http.StatusOK, gin.H{"message": "vault updated successfully"})
 }
 
-// Delete deletes a vault
+// Delete moves a vault to the trash
 // @Summary      Delete vault
-// @Description  Delete a vault and all its entries
+// @Description  Move a vault and all its entries to the trash
 // @Tags         vaults
 // @Produce      json
 // @Param        id   path      string  true  "Vault ID"
@@ -289,7 +315,7 @@ func (h *VaultHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	// Delete vault (will cascade to entries)
+	// Move vault to trash (entries become unreachable until restored)
 	if err := h.vaultRepo.Delete(c.Request.Context(), vaultID); err != nil {
 		h.logger.Error("failed to delete vault", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -297,10 +323,244 @@ func (h *VaultHandler) Delete(c *gin.Context) {
 	}
 
 	// Audit log
-	_ = h.auditRepo.Create(c.Request.Context(), &userID, models.ActionVaultDeleted,
-		middleware.GetClientIP(c), c.Request.UserAgent(), map[string]interface{}{
-			"vault_id": vaultID.String(),
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		models.VaultDeletedEvent{
+			VaultID: vaultID,
 		})
 
 	c.JSON(http.StatusOK, gin.H{"message": "vault deleted successfully"})
 }
+
+// RotateKey atomically re-encrypts a vault under a new key
+// @Summary      Rotate vault key
+// @Description  Replace the vault's encryption salt and the ciphertext of every entry (including trashed entries), folder name and tag in one transaction. The key generation is incremented; retained revisions are replaced by the new ciphertext.
+// @Tags         vaults
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                          true  "Vault ID"
+// @Param        request  body      models.VaultKeyRotationRequest  true  "Key Rotation Request"
+// @Success      200      {object}  models.VaultResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /vaults/{id}/rotate-key [post]
+func (h *VaultHandler) RotateKey(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	var req models.VaultKeyRotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	// Check ownership
+	owns, err := h.vaultRepo.CheckOwnership(c.Request.Context(), vaultID, userID)
+	if err != nil || !owns {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	rotation, err := decodeKeyRotation(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.rotateKey(c, userID, vaultID, rotation, func(change models.VaultKeyChange) models.AuditEvent {
+		return models.VaultKeyRotatedEvent(change)
+	})
+}
+
+// UpgradeKDF changes a vault's key derivation function with a re-encryption batch
+// @Summary      Upgrade vault KDF
+// @Description  Change the vault's KDF algorithm and parameters. The derived key changes, so a new encryption salt and the ciphertext of every entry (including trashed entries), folder name and tag are replaced in the same transaction as a key rotation.
+// @Tags         vaults
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                         true  "Vault ID"
+// @Param        request  body      models.VaultKDFUpgradeRequest  true  "KDF Upgrade Request"
+// @Success      200      {object}  models.VaultResponse
+// @Failure      400      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      422      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /vaults/{id}/kdf [post]
+func (h *VaultHandler) UpgradeKDF(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	vaultID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vault ID"})
+		return
+	}
+
+	var req models.VaultKDFUpgradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	if err := models.ValidateKDF(req.KDFAlgorithm, req.KDFParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid kdf_params: " + err.Error()})
+		return
+	}
+
+	// Check ownership
+	vault, err := h.vaultRepo.GetByID(c.Request.Context(), vaultID)
+	if err != nil || vault.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		return
+	}
+
+	rotation, err := decodeKeyRotation(&req.VaultKeyRotationRequest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rotation.KDFAlgorithm = req.KDFAlgorithm
+	rotation.KDFParams = &req.KDFParams
+
+	h.rotateKey(c, userID, vaultID, rotation, func(change models.VaultKeyChange) models.AuditEvent {
+		return models.VaultKDFUpgradedEvent{
+			VaultKeyChange:       change,
+			PreviousKDFAlgorithm: vault.KDFAlgorithm,
+			KDFAlgorithm:         req.KDFAlgorithm,
+		}
+	})
+}
+
+// rotateKey applies a decoded key rotation and writes the response and the
+// audit event built by newEvent
+func (h *VaultHandler) rotateKey(c *gin.Context, userID, vaultID uuid.UUID, rotation *models.VaultKeyRotation, newEvent func(models.VaultKeyChange) models.AuditEvent) {
+	vault, err := h.vaultRepo.RotateKey(c.Request.Context(), vaultID, rotation, userID)
+	if err != nil {
+		if respondQuotaError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, repository.ErrKeyGenerationMismatch):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrIncompleteRotation):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("failed to rotate vault key", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	// Audit log
+	h.auditor.Record(c.Request.Context(), &userID, middleware.GetClientIP(c), c.Request.UserAgent(),
+		newEvent(models.VaultKeyChange{
+			VaultID:       vaultID,
+			KeyGeneration: vault.KeyGeneration,
+			Entries:       len(rotation.Entries),
+			Folders:       len(rotation.Folders),
+			Tags:          len(rotation.Tags),
+		}))
+
+	entryCount, err := h.entryRepo.CountByVaultID(c.Request.Context(), vaultID)
+	if err != nil {
+		h.logger.Error("failed to count entries", zap.Error(err))
+		entryCount = 0
+	}
+
+	c.JSON(http.StatusOK, vault.ToResponse(entryCount))
+}
+
+// decodeKeyRotation decodes the hex-encoded salt, ciphertexts and blind IDs of a rotation request
+func decodeKeyRotation(req *models.VaultKeyRotationRequest) (*models.VaultKeyRotation, error) {
+	salt, err := hex.DecodeString(req.EncryptionSalt)
+	if err != nil || len(salt) != 32 {
+		return nil, errors.New("invalid encryption_salt: must be 64-char hex string (32 bytes)")
+	}
+
+	rotation := &models.VaultKeyRotation{
+		EncryptionSalt:        salt,
+		ExpectedKeyGeneration: req.ExpectedKeyGeneration,
+		Entries:               make([]models.RotatedEntry, len(req.Entries)),
+		Folders:               make([]models.RotatedFolder, len(req.Folders)),
+		Tags:                  make([]models.RotatedTag, len(req.Tags)),
+	}
+
+	for i, entry := range req.Entries {
+		encryptedData, err := hex.DecodeString(entry.EncryptedData)
+		if err != nil {
+			return nil, errors.New("invalid encrypted_data: must be hex string")
+		}
+
+		nonce, err := hex.DecodeString(entry.Nonce)
+		if err != nil || len(nonce) != 12 {
+			return nil, errors.New("invalid nonce: must be 24-char hex string (12 bytes)")
+		}
+
+		rotation.Entries[i] = models.RotatedEntry{
+			ID:            entry.EntryID,
+			EncryptedData: encryptedData,
+			Nonce:         nonce,
+		}
+	}
+
+	for i, folder := range req.Folders {
+		encryptedName, err := hex.DecodeString(folder.EncryptedName)
+		if err != nil || len(encryptedName) == 0 {
+			return nil, errors.New("invalid folder encrypted_name: must be hex string")
+		}
+
+		nonce, err := hex.DecodeString(folder.Nonce)
+		if err != nil || len(nonce) != 12 {
+			return nil, errors.New("invalid folder nonce: must be 24-char hex string (12 bytes)")
+		}
+
+		rotation.Folders[i] = models.RotatedFolder{
+			ID:            folder.FolderID,
+			EncryptedName: encryptedName,
+			Nonce:         nonce,
+		}
+	}
+
+	blindIDs := make(map[string]bool, len(req.Tags))
+	for i, tag := range req.Tags {
+		blindID, err := hex.DecodeString(tag.BlindID)
+		if err != nil || len(blindID) != 32 {
+			return nil, errors.New("invalid tag blind_id: must be 64-char hex string (32 bytes)")
+		}
+		if blindIDs[string(blindID)] {
+			return nil, errors.New("invalid tag blind_id: duplicate within the vault")
+		}
+		blindIDs[string(blindID)] = true
+
+		rotated := models.RotatedTag{ID: tag.TagID, BlindID: blindID}
+		if tag.EncryptedName != "" || tag.Nonce != "" {
+			rotated.EncryptedName, err = hex.DecodeString(tag.EncryptedName)
+			if err != nil || len(rotated.EncryptedName) == 0 {
+				return nil, errors.New("invalid tag encrypted_name: must be hex string")
+			}
+
+			rotated.Nonce, err = hex.DecodeString(tag.Nonce)
+			if err != nil || len(rotated.Nonce) != 12 {
+				return nil, errors.New("invalid tag nonce: must be 24-char hex string (12 bytes)")
+			}
+		}
+		rotation.Tags[i] = rotated
+	}
+
+	return rotation, nil
+}
@@ -0,0 +1,80 @@
+package jobs
+
+import (
+	"context"
+	"crypto/ed25519"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"go.uber.org/zap"
+)
+
+// AuditCheckpointer periodically signs the head of the audit hash chain
+type AuditCheckpointer struct {
+	auditRepo  *repository.AuditRepository
+	signingKey ed25519.PrivateKey
+	interval   time.Duration
+	logger     *zap.Logger
+}
+
+// NewAuditCheckpointer creates a new audit checkpointer
+func NewAuditCheckpointer(
+	auditRepo *repository.AuditRepository,
+	signingKey ed25519.PrivateKey,
+	interval time.Duration,
+	logger *zap.Logger,
+) *AuditCheckpointer {
+	return &AuditCheckpointer{
+		auditRepo:  auditRepo,
+		signingKey: signingKey,
+		interval:   interval,
+		logger:     logger,
+	}
+}
+
+// Run writes a checkpoint immediately and then on every interval until ctx is cancelled
+func (p *AuditCheckpointer) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.checkpoint(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkpoint signs the current chain head unless it is already checkpointed.
+// Checkpoints are not audited themselves, which would move the head every time.
+func (p *AuditCheckpointer) checkpoint(ctx context.Context) {
+	head, err := p.auditRepo.GetChainHead(ctx)
+	if err != nil {
+		p.logger.Error("failed to get audit chain head", zap.Error(err))
+		return
+	}
+	if head == nil {
+		return
+	}
+
+	latest, err := p.auditRepo.GetLatestCheckpoint(ctx)
+	if err != nil {
+		p.logger.Error("failed to get latest audit checkpoint", zap.Error(err))
+		return
+	}
+	if latest != nil && latest.LogID == head.ID {
+		return
+	}
+
+	checkpoint := audit.NewCheckpoint(p.signingKey, head.ID, head.Hash)
+	if err := p.auditRepo.CreateCheckpoint(ctx, checkpoint); err != nil {
+		p.logger.Error("failed to create audit checkpoint", zap.Error(err))
+		return
+	}
+
+	p.logger.Info("created audit checkpoint", zap.Int64("checkpoint_id", checkpoint.ID), zap.Int64("log_id", checkpoint.LogID))
+}
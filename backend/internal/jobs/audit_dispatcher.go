@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"go.uber.org/zap"
+)
+
+// auditSendTimeout bounds a single delivery to a sink
+const auditSendTimeout = 10 * time.Second
+
+// auditMaxBackoff caps the delay between retries of a failing delivery
+const auditMaxBackoff = time.Hour
+
+// AuditDispatcher delivers audit logs from the outbox to the configured sinks.
+// A failing sink only delays its own deliveries; they are retried with
+// exponential backoff until they succeed.
+type AuditDispatcher struct {
+	outboxRepo *repository.AuditOutboxRepository
+	sinks      []audit.Sink
+	batchSize  int
+	interval   time.Duration
+	logger     *zap.Logger
+}
+
+// NewAuditDispatcher creates a new audit dispatcher
+func NewAuditDispatcher(
+	outboxRepo *repository.AuditOutboxRepository,
+	sinks []audit.Sink,
+	batchSize int,
+	interval time.Duration,
+	logger *zap.Logger,
+) *AuditDispatcher {
+	return &AuditDispatcher{
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+		batchSize:  batchSize,
+		interval:   interval,
+		logger:     logger,
+	}
+}
+
+// Run delivers pending audit logs immediately and then on every interval until
+// ctx is cancelled; the sinks are closed on return
+func (p *AuditDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	defer func() {
+		for _, sink := range p.sinks {
+			_ = sink.Close()
+		}
+	}()
+
+	for {
+		for _, sink := range p.sinks {
+			p.dispatch(ctx, sink)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch delivers due items to a sink in log order until the outbox is
+// drained or a delivery fails. After a failure the failed item and the rest
+// of its batch are postponed, so the sink does not receive them out of order.
+func (p *AuditDispatcher) dispatch(ctx context.Context, sink audit.Sink) {
+	// The lease covers a whole batch of deliveries at their timeout
+	lease := time.Duration(p.batchSize) * auditSendTimeout
+
+	for ctx.Err() == nil {
+		items, err := p.outboxRepo.Claim(ctx, sink.Name(), p.batchSize, lease)
+		if err != nil {
+			p.logger.Error("failed to claim audit outbox", zap.String("sink", sink.Name()), zap.Error(err))
+			return
+		}
+
+		delivered := make([]int64, 0, len(items))
+		for i, item := range items {
+			sendCtx, cancel := context.WithTimeout(ctx, auditSendTimeout)
+			err := sink.Send(sendCtx, &item.AuditLog)
+			cancel()
+
+			if err != nil {
+				p.logger.Warn("failed to deliver audit log",
+					zap.String("sink", sink.Name()), zap.Int64("log_id", item.ID),
+					zap.Int("attempts", item.Attempts+1), zap.Error(err))
+
+				ids := make([]int64, 0, len(items)-i)
+				for _, rest := range items[i:] {
+					ids = append(ids, rest.OutboxID)
+				}
+				retryAt := time.Now().Add(p.backoff(item.Attempts))
+				if err := p.outboxRepo.Fail(ctx, ids, retryAt, err.Error()); err != nil {
+					p.logger.Error("failed to postpone audit outbox items", zap.String("sink", sink.Name()), zap.Error(err))
+				}
+				break
+			}
+
+			delivered = append(delivered, item.OutboxID)
+		}
+
+		if err := p.outboxRepo.Delete(ctx, delivered); err != nil {
+			p.logger.Error("failed to delete delivered audit outbox items", zap.String("sink", sink.Name()), zap.Error(err))
+			return
+		}
+
+		if len(delivered) < p.batchSize {
+			return
+		}
+	}
+}
+
+// backoff returns the delay before retrying an item that failed attempts
+// times before: the interval doubled per attempt, capped at auditMaxBackoff
+func (p *AuditDispatcher) backoff(attempts int) time.Duration {
+	delay := p.interval
+	for i := 0; i < attempts && delay < auditMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, auditMaxBackoff)
+}
@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"go.uber.org/zap"
+)
+
+// EmergencyAccessReleaser periodically approves recovery requests whose wait
+// period has passed without the grantor rejecting them
+type EmergencyAccessReleaser struct {
+	emergencyRepo *repository.EmergencyAccessRepository
+	auditor       audit.Recorder
+	interval      time.Duration
+	logger        *zap.Logger
+}
+
+// NewEmergencyAccessReleaser creates a new emergency access releaser
+func NewEmergencyAccessReleaser(
+	emergencyRepo *repository.EmergencyAccessRepository,
+	auditor audit.Recorder,
+	interval time.Duration,
+	logger *zap.Logger,
+) *EmergencyAccessReleaser {
+	return &EmergencyAccessReleaser{
+		emergencyRepo: emergencyRepo,
+		auditor:       auditor,
+		interval:      interval,
+		logger:        logger,
+	}
+}
+
+// Run releases due requests immediately and then on every interval until ctx is cancelled
+func (r *EmergencyAccessReleaser) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.release(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// release approves all due recovery requests and records them for both parties
+func (r *EmergencyAccessReleaser) release(ctx context.Context) {
+	grants, err := r.emergencyRepo.ApproveExpiredRequests(ctx)
+	if err != nil {
+		r.logger.Error("failed to release emergency access", zap.Error(err))
+		return
+	}
+
+	for _, grant := range grants {
+		event := models.EmergencyAccessRecoveryApprovedEvent{
+			EmergencyAccessRef: grant.AuditRef("system"),
+			PreviousStatus:     models.EmergencyAccessRecoveryRequested,
+			Status:             grant.Status,
+		}
+		r.auditor.Record(ctx, &grant.GrantorID, "", "job/emergency-access", event)
+		r.auditor.Record(ctx, grant.GranteeID, "", "job/emergency-access", event)
+	}
+
+	if len(grants) > 0 {
+		r.logger.Info("released emergency access after wait period", zap.Int("grants", len(grants)))
+	}
+}
@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"go.uber.org/zap"
+)
+
+// SharePurger periodically deletes expired share links
+type SharePurger struct {
+	shareRepo *repository.ShareRepository
+	auditor   audit.Recorder
+	interval  time.Duration
+	logger    *zap.Logger
+}
+
+// NewSharePurger creates a new share purger
+func NewSharePurger(
+	shareRepo *repository.ShareRepository,
+	auditor audit.Recorder,
+	interval time.Duration,
+	logger *zap.Logger,
+) *SharePurger {
+	return &SharePurger{
+		shareRepo: shareRepo,
+		auditor:   auditor,
+		interval:  interval,
+		logger:    logger,
+	}
+}
+
+// Run purges expired shares immediately and then on every interval until ctx is cancelled
+func (p *SharePurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge deletes all shares past their expiry
+func (p *SharePurger) purge(ctx context.Context) {
+	cutoff := time.Now()
+
+	shares, err := p.shareRepo.PurgeExpiredBefore(ctx, cutoff)
+	if err != nil {
+		p.logger.Error("failed to purge expired shares", zap.Error(err))
+		return
+	}
+
+	if shares == 0 {
+		return
+	}
+
+	p.logger.Info("purged expired shares", zap.Int64("shares", shares))
+
+	// Audit log (system action, no user)
+	p.auditor.Record(ctx, nil, "", "job/share-purge", models.SharePurgedEvent{
+		Shares: shares,
+		Cutoff: cutoff.UTC().Truncate(time.Second),
+	})
+}
@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/SecurityByDesign/pwmanager/internal/storage"
+	"go.uber.org/zap"
+)
+
+// Attachment garbage collection settings
+const (
+	staleUploadAge      = time.Hour
+	attachmentBatchSize = 500
+)
+
+// TrashPurger periodically deletes trashed vaults and entries past their
+// retention and removes the blobs of attachments left without an entry
+type TrashPurger struct {
+	vaultRepo      *repository.VaultRepository
+	entryRepo      *repository.EntryRepository
+	attachmentRepo *repository.AttachmentRepository
+	auditor        audit.Recorder
+	store          storage.BlobStore
+	retention      time.Duration
+	interval       time.Duration
+	logger         *zap.Logger
+}
+
+// NewTrashPurger creates a new trash purger
+func NewTrashPurger(
+	vaultRepo *repository.VaultRepository,
+	entryRepo *repository.EntryRepository,
+	attachmentRepo *repository.AttachmentRepository,
+	auditor audit.Recorder,
+	store storage.BlobStore,
+	retention time.Duration,
+	interval time.Duration,
+	logger *zap.Logger,
+) *TrashPurger {
+	return &TrashPurger{
+		vaultRepo:      vaultRepo,
+		entryRepo:      entryRepo,
+		attachmentRepo: attachmentRepo,
+		auditor:        auditor,
+		store:          store,
+		retention:      retention,
+		interval:       interval,
+		logger:         logger,
+	}
+}
+
+// Run purges expired trash immediately and then on every interval until ctx is cancelled
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+		p.collectAttachments(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge deletes all trashed items older than the retention period
+func (p *TrashPurger) purge(ctx context.Context) {
+	cutoff := time.Now().Add(-p.retention)
+
+	// Purge vaults first; their entries are removed by the cascade
+	vaults, err := p.vaultRepo.PurgeDeletedBefore(ctx, cutoff)
+	if err != nil {
+		p.logger.Error("failed to purge trashed vaults", zap.Error(err))
+		return
+	}
+
+	entries, err := p.entryRepo.PurgeDeletedBefore(ctx, cutoff)
+	if err != nil {
+		p.logger.Error("failed to purge trashed entries", zap.Error(err))
+		return
+	}
+
+	if vaults == 0 && entries == 0 {
+		return
+	}
+
+	p.logger.Info("purged expired trash",
+		zap.Int64("vaults", vaults),
+		zap.Int64("entries", entries),
+	)
+
+	// Audit log (system action, no user)
+	p.auditor.Record(ctx, nil, "", "job/trash-purge", models.TrashPurgedEvent{
+		Vaults:  vaults,
+		Entries: entries,
+		Cutoff:  cutoff.UTC().Truncate(time.Second),
+	})
+}
+
+// collectAttachments deletes the blobs and metadata of attachments whose entry
+// was purged and of uploads that never completed
+func (p *TrashPurger) collectAttachments(ctx context.Context) {
+	attachments, err := p.attachmentRepo.GetOrphaned(ctx, time.Now().Add(-staleUploadAge), attachmentBatchSize)
+	if err != nil {
+		p.logger.Error("failed to get orphaned attachments", zap.Error(err))
+		return
+	}
+
+	removed := 0
+	for _, attachment := range attachments {
+		if err := p.store.Delete(ctx, attachment.StorageKey); err != nil {
+			p.logger.Warn("failed to delete attachment blob", zap.String("attachment_id", attachment.ID.String()), zap.Error(err))
+			continue
+		}
+		if err := p.attachmentRepo.Delete(ctx, attachment.ID); err != nil {
+			p.logger.Warn("failed to delete attachment", zap.String("attachment_id", attachment.ID.String()), zap.Error(err))
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		p.logger.Info("removed orphaned attachments", zap.Int("attachments", removed))
+	}
+}
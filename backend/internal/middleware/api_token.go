@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/SecurityByDesign/pwmanager/internal/auth"
+	"github.com/SecurityByDesign/pwmanager/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APITokenMiddleware authenticates requests with a bearer API token carrying
+// the required scope. Session cookies are not accepted on these routes, and
+// CSRF protection is not needed since the token is never sent implicitly.
+func APITokenMiddleware(tokenRepo *repository.APITokenRepository, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		raw, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || !strings.HasPrefix(raw, auth.APITokenPrefix) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized: no API token"})
+			c.Abort()
+			return
+		}
+
+		token, err := tokenRepo.GetActiveByHash(c.Request.Context(), auth.HashAPIToken(raw))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized: invalid API token"})
+			c.Abort()
+			return
+		}
+
+		if !token.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API token lacks scope: " + scope})
+			c.Abort()
+			return
+		}
+
+		if err := tokenRepo.UpdateLastUsed(c.Request.Context(), token.ID); err != nil {
+			// Log error but don't fail the request
+			_ = c.Error(err)
+		}
+
+		// Store user ID in context for handlers
+		c.Set("user_id", token.UserID)
+		c.Set("api_token_id", token.ID)
+
+		c.Next()
+	}
+}
+
+// GetAPITokenID extracts the API token ID from Gin context
+func GetAPITokenID(c *gin.Context) (uuid.UUID, bool) {
+	tokenID, exists := c.Get("api_token_id")
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	tid, ok := tokenID.(uuid.UUID)
+	return tid, ok
+}
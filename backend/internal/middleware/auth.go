@@ -4,13 +4,16 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
 	"github.com/SecurityByDesign/pwmanager/internal/auth"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// AuthMiddleware checks for valid session
-func AuthMiddleware(sessionManager *auth.SessionManager) gin.HandlerFunc {
+// AuthMiddleware checks for valid session. Requests presenting an unknown or
+// expired session are audited; requests without a session cookie are not.
+func AuthMiddleware(sessionManager *auth.SessionManager, auditor audit.Recorder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get session ID from cookie
 		sessionID, err := c.Cookie("session_id")
@@ -23,6 +26,7 @@ func AuthMiddleware(sessionManager *auth.SessionManager) gin.HandlerFunc {
 		// Validate session
 		session, err := sessionManager.GetSession(c.Request.Context(), sessionID)
 		if err != nil {
+			recordSecurityEvent(c, auditor, models.SecuritySessionInvalidEvent(securityRequest(c, "invalid_session")))
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized: invalid session"})
 			c.Abort()
 			return
@@ -3,11 +3,13 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
-// CSRFMiddleware checks for valid CSRF token in headers
-func CSRFMiddleware() gin.HandlerFunc {
+// CSRFMiddleware checks for valid CSRF token in headers; rejections are audited
+func CSRFMiddleware(auditor audit.Recorder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Skip for safe methods
 		if c.Request.Method == "GET" || c.Request.Method == "HEAD" || c.Request.Method == "OPTIONS" {
@@ -27,12 +29,14 @@ func CSRFMiddleware() gin.HandlerFunc {
 		// Get CSRF token from header
 		clientToken := c.GetHeader("X-CSRF-Token")
 		if clientToken == "" {
+			recordSecurityEvent(c, auditor, models.SecurityCSRFRejectedEvent(securityRequest(c, "missing_token")))
 			c.JSON(http.StatusForbidden, gin.H{"error": "CSRF token missing"})
 			c.Abort()
 			return
 		}
 
 		if clientToken != expectedToken.(string) {
+			recordSecurityEvent(c, auditor, models.SecurityCSRFRejectedEvent(securityRequest(c, "invalid_token")))
 			c.JSON(http.StatusForbidden, gin.H{"error": "CSRF token invalid"})
 			c.Abort()
 			return
@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -42,8 +45,8 @@ func LoggingMiddleware(logger *zap.Logger) gin.HandlerFunc {
 	}
 }
 
-// RecoveryMiddleware recovers from panics and logs them
-func RecoveryMiddleware(logger *zap.Logger) gin.HandlerFunc {
+// RecoveryMiddleware recovers from panics, logs them and records them in the audit trail
+func RecoveryMiddleware(logger *zap.Logger, auditor audit.Recorder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
@@ -53,6 +56,9 @@ func RecoveryMiddleware(logger *zap.Logger) gin.HandlerFunc {
 					zap.String("method", c.Request.Method),
 				)
 
+				// The panic value is only logged; it may contain request data
+				recordSecurityEvent(c, auditor, models.SecurityPanicRecoveredEvent(securityRequest(c, fmt.Sprintf("%T", err))))
+
 				c.JSON(500, gin.H{"error": "internal server error"})
 				c.Abort()
 			}
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"github.com/ulule/limiter/v3"
@@ -12,8 +14,8 @@ import (
 	sredis "github.com/ulule/limiter/v3/drivers/store/redis"
 )
 
-// RateLimitMiddleware creates a new rate limit middleware
-func RateLimitMiddleware(client *redis.Client, requestsPerMinute int, keyPrefix string) gin.HandlerFunc {
+// RateLimitMiddleware creates a new rate limit middleware; requests over the limit are audited
+func RateLimitMiddleware(client *redis.Client, requestsPerMinute int, keyPrefix string, auditor audit.Recorder) gin.HandlerFunc {
 	// Define a rate: requestsPerMinute requests per minute
 	rate := limiter.Rate{
 		Period: 1 * time.Minute,
@@ -33,15 +35,18 @@ func RateLimitMiddleware(client *redis.Client, requestsPerMinute int, keyPrefix
 	}
 
 	// Create a new middleware with the limiter instance
-	middleware := mgin.NewMiddleware(limiter.New(store, rate))
+	middleware := mgin.NewMiddleware(limiter.New(store, rate), mgin.WithLimitReachedHandler(func(c *gin.Context) {
+		recordSecurityEvent(c, auditor, models.SecurityRateLimitedEvent(securityRequest(c, keyPrefix)))
+		CustomErrorHandler(c, nil)
+	}))
 
 	return middleware
 }
 
 // StrictRateLimitMiddleware creates a stricter rate limit middleware for sensitive endpoints
 // It returns a 429 Too Many Requests error if the limit is exceeded
-func StrictRateLimitMiddleware(client *redis.Client, requestsPerMinute int, keyPrefix string) gin.HandlerFunc {
-	return RateLimitMiddleware(client, requestsPerMinute, keyPrefix)
+func StrictRateLimitMiddleware(client *redis.Client, requestsPerMinute int, keyPrefix string, auditor audit.Recorder) gin.HandlerFunc {
+	return RateLimitMiddleware(client, requestsPerMinute, keyPrefix, auditor)
 }
 
 // CustomErrorHandler is an optional custom error handler for the rate limiter
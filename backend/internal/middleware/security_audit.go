@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// securityRequest describes a request rejected by a security middleware with
+// its route and the reason. The route is the registered pattern, not the raw
+// path, so repeated events aggregate in a throttled recorder.
+func securityRequest(c *gin.Context, reason string) models.SecurityRequest {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	return models.SecurityRequest{
+		Route:  route,
+		Method: c.Request.Method,
+		Reason: reason,
+	}
+}
+
+// recordSecurityEvent audits a security event for the request's user, if any
+func recordSecurityEvent(c *gin.Context, auditor audit.Recorder, event models.AuditEvent) {
+	var userID *uuid.UUID
+	if id, err := GetUserID(c); err == nil {
+		userID = &id
+	}
+
+	auditor.Record(c.Request.Context(), userID, GetClientIP(c), c.Request.UserAgent(), event)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment represents a client-encrypted file attached to a vault entry.
+// The file and its name are encrypted with a per-attachment key that the
+// client stores inside the entry's encrypted_data, so vault key rotation
+// only needs to re-encrypt the entry.
+type Attachment struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EntryID       *uuid.UUID `json:"entry_id" db:"entry_id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	StorageKey    string     `json:"-" db:"storage_key"`
+	EncryptedName []byte     `json:"encrypted_name" db:"encrypted_name"`
+	Nonce         []byte     `json:"nonce" db:"nonce"`
+	Size          int64      `json:"size" db:"size"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// AttachmentResponse represents attachment metadata
+type AttachmentResponse struct {
+	ID            uuid.UUID `json:"id"`
+	EntryID       uuid.UUID `json:"entry_id"`
+	EncryptedName string    `json:"encrypted_name"` // Hex-encoded
+	Nonce         string    `json:"nonce"`          // Hex-encoded nonce of the file content
+	Size          int64     `json:"size"`           // Ciphertext size in bytes
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ToResponse converts Attachment to AttachmentResponse
+func (a *Attachment) ToResponse() AttachmentResponse {
+	resp := AttachmentResponse{
+		ID:            a.ID,
+		EncryptedName: hex.EncodeToString(a.EncryptedName),
+		Nonce:         hex.EncodeToString(a.Nonce),
+		Size:          a.Size,
+		CreatedAt:     a.CreatedAt,
+	}
+	if a.EntryID != nil {
+		resp.EntryID = *a.EntryID
+	}
+	return resp
+}
@@ -16,17 +16,20 @@ type AuditLog struct {
 	UserAgent string          `json:"user_agent" db:"user_agent"`
 	Details   json.RawMessage `json:"details,omitempty" db:"details" swaggertype:"string"`
 	Timestamp time.Time       `json:"timestamp" db:"timestamp"`
+	PrevHash  []byte          `json:"-" db:"prev_hash"` // Hash chain link, NULL for rows written before chaining
+	Hash      []byte          `json:"-" db:"hash"`
 }
 
-// AuditAction defines standard audit action types
+// AuditAction defines standard audit action types; each is recorded by one AuditEvent type
 type AuditAction string
 
 const (
 	// Authentication actions
-	ActionUserRegistered AuditAction = "user.registered"
-	ActionUserLogin      AuditAction = "user.login"
-	ActionUserLogout     AuditAction = "user.logout"
-	ActionLoginFailed    AuditAction = "user.login_failed"
+	ActionUserRegistered  AuditAction = "user.registered"
+	ActionUserLogin       AuditAction = "user.login"
+	ActionUserLogout      AuditAction = "user.logout"
+	ActionLoginFailed     AuditAction = "user.login_failed"
+	ActionPasswordChanged AuditAction = "user.password_changed"
 
 	// MFA actions
 	ActionMFASetup    AuditAction = "mfa.setup"
@@ -36,16 +39,88 @@ const (
 	ActionMFAFailed   AuditAction = "mfa.failed"
 
 	// Vault actions
-	ActionVaultCreated  AuditAction = "vault.created"
-	ActionVaultUpdated  AuditAction = "vault.updated"
-	ActionVaultDeleted  AuditAction = "vault.deleted"
-	ActionVaultAccessed AuditAction = "vault.accessed"
+	ActionVaultCreated     AuditAction = "vault.created"
+	ActionVaultUpdated     AuditAction = "vault.updated"
+	ActionVaultDeleted     AuditAction = "vault.deleted"
+	ActionVaultAccessed    AuditAction = "vault.accessed"
+	ActionVaultRestored    AuditAction = "vault.restored"
+	ActionVaultPurged      AuditAction = "vault.purged"
+	ActionVaultSynced      AuditAction = "vault.synced"
+	ActionVaultKeyRotated  AuditAction = "vault.key_rotated"
+	ActionVaultKDFUpgraded AuditAction = "vault.kdf_upgraded"
+	ActionVaultSearched    AuditAction = "vault.searched"
 
 	// Entry actions
-	ActionEntryCreated  AuditAction = "entry.created"
-	ActionEntryUpdated  AuditAction = "entry.updated"
-	ActionEntryDeleted  AuditAction = "entry.deleted"
-	ActionEntryAccessed AuditAction = "entry.accessed"
+	ActionEntryCreated       AuditAction = "entry.created"
+	ActionEntryUpdated       AuditAction = "entry.updated"
+	ActionEntryDeleted       AuditAction = "entry.deleted"
+	ActionEntryAccessed      AuditAction = "entry.accessed"
+	ActionEntryRestored      AuditAction = "entry.restored"
+	ActionEntryPurged        AuditAction = "entry.purged"
+	ActionEntryBatch         AuditAction = "entry.batch"
+	ActionEntryMoved         AuditAction = "entry.moved"
+	ActionEntryCopied        AuditAction = "entry.copied"
+	ActionEntryFolderChanged AuditAction = "entry.folder_changed"
+	ActionEntryTagged        AuditAction = "entry.tagged"
+	ActionEntryFavorite      AuditAction = "entry.favorite"
+	ActionEntryIndexed       AuditAction = "entry.indexed"
+	ActionEntryAutofillSet   AuditAction = "entry.autofill_updated"
+
+	// Entry version actions
+	ActionEntryVersionsListed  AuditAction = "entry.versions_listed"
+	ActionEntryVersionAccessed AuditAction = "entry.version_accessed"
+	ActionEntryVersionRestored AuditAction = "entry.version_restored"
+
+	// Folder and tag actions
+	ActionFolderCreated AuditAction = "folder.created"
+	ActionFolderRenamed AuditAction = "folder.renamed"
+	ActionFolderMoved   AuditAction = "folder.moved"
+	ActionFolderDeleted AuditAction = "folder.deleted"
+	ActionTagCreated    AuditAction = "tag.created"
+	ActionTagDeleted    AuditAction = "tag.deleted"
+
+	// API token and autofill actions
+	ActionAPITokenCreated AuditAction = "api_token.created"
+	ActionAPITokenRevoked AuditAction = "api_token.revoked"
+	ActionAutofillLookup  AuditAction = "autofill.lookup"
+
+	// Share link actions
+	ActionShareCreated      AuditAction = "share.created"
+	ActionShareAccessed     AuditAction = "share.accessed"
+	ActionShareAccessDenied AuditAction = "share.access_denied"
+	ActionShareRevoked      AuditAction = "share.revoked"
+	ActionSharePurged       AuditAction = "share.purged"
+
+	// Emergency access actions
+	ActionEmergencyAccessInvited           AuditAction = "emergency_access.invited"
+	ActionEmergencyAccessAccepted          AuditAction = "emergency_access.accepted"
+	ActionEmergencyAccessUpdated           AuditAction = "emergency_access.updated"
+	ActionEmergencyAccessRevoked           AuditAction = "emergency_access.revoked"
+	ActionEmergencyAccessRecoveryRequested AuditAction = "emergency_access.recovery_requested"
+	ActionEmergencyAccessRecoveryApproved  AuditAction = "emergency_access.recovery_approved"
+	ActionEmergencyAccessRecoveryRejected  AuditAction = "emergency_access.recovery_rejected"
+	ActionEmergencyAccessKeyAccessed       AuditAction = "emergency_access.key_accessed"
+	ActionEmergencyAccessVaultAccessed     AuditAction = "emergency_access.vault_accessed"
+
+	// Attachment actions
+	ActionAttachmentUploaded   AuditAction = "attachment.uploaded"
+	ActionAttachmentDownloaded AuditAction = "attachment.downloaded"
+	ActionAttachmentDeleted    AuditAction = "attachment.deleted"
+
+	// Trash actions
+	ActionTrashPurged AuditAction = "trash.purged"
+
+	// Security middleware actions
+	ActionSecurityCSRFRejected     AuditAction = "security.csrf_rejected"
+	ActionSecurityRateLimited      AuditAction = "security.rate_limited"
+	ActionSecuritySessionInvalid   AuditAction = "security.session_invalid"
+	ActionSecurityPanicRecovered   AuditAction = "security.panic_recovered"
+	ActionSecurityEventsSuppressed AuditAction = "security.events_suppressed"
+
+	// Audit trail actions
+	ActionAuditVerified AuditAction = "audit.verified"
+	ActionAuditPurged   AuditAction = "audit.purged"
+	ActionAuditExported AuditAction = "audit.exported"
 )
 
 // AuditLogRequest represents the request to create an audit log
@@ -67,3 +142,70 @@ type AuditLogResponse struct {
 	Details   map[string]interface{} `json:"details,omitempty"`
 	Timestamp time.Time              `json:"timestamp"`
 }
+
+// AuditLogListResponse represents a page of audit logs with the total number matching the filter
+type AuditLogListResponse struct {
+	Items      []*AuditLog `json:"items"`
+	NextCursor string      `json:"next_cursor,omitempty"` // Empty when there are no further pages
+	Total      int         `json:"total"`
+}
+
+// AuditPartition is a monthly partition of audit_logs holding the logs from From up to To
+type AuditPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// AuditOutboxItem is an audit log awaiting delivery to an external sink
+type AuditOutboxItem struct {
+	OutboxID int64 `db:"outbox_id"`
+	Attempts int   `db:"attempts"`
+	AuditLog
+}
+
+// AuditWriterMetrics reports the state of the asynchronous audit writer since startup
+type AuditWriterMetrics struct {
+	QueueDepth    int    `json:"queue_depth"`
+	QueueCapacity int    `json:"queue_capacity"`
+	Recorded      uint64 `json:"recorded"`       // Events accepted for writing
+	Written       uint64 `json:"written"`        // Events inserted from the queue
+	Spilled       uint64 `json:"spilled"`        // Events written to the spill file
+	Replayed      uint64 `json:"replayed"`       // Spilled events inserted later
+	Dropped       uint64 `json:"dropped"`        // Events lost
+	Rejected      uint64 `json:"rejected"`       // Events not in the catalog or permanently rejected by the database, also counted as dropped
+	Quarantined   uint64 `json:"quarantined"`    // Spilled events moved to the dead-letter file since their partition was dropped
+	Restamped     uint64 `json:"restamped"`      // Spilled events moved to the current time since their month has no partition
+	FailedBatches uint64 `json:"failed_batches"` // Failed insert attempts
+	SpillPending  bool   `json:"spill_pending"`  // The spill file holds events awaiting replay
+}
+
+// AuditCheckpoint pins the audit hash chain head with an Ed25519 signature
+type AuditCheckpoint struct {
+	ID        int64     `json:"id" db:"id"`
+	LogID     int64     `json:"log_id" db:"log_id"`
+	Hash      []byte    `json:"-" db:"hash"`
+	Signature []byte    `json:"-" db:"signature"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// AuditChainBreak describes the first inconsistency found in the audit trail
+type AuditChainBreak struct {
+	LogID        int64  `json:"log_id,omitempty"`
+	CheckpointID int64  `json:"checkpoint_id,omitempty"`
+	Reason       string `json:"reason"`
+}
+
+// AuditVerification represents the result of walking the audit hash chain
+type AuditVerification struct {
+	Valid              bool             `json:"valid"`
+	LegacyRows         int64            `json:"legacy_rows"` // Rows written before chaining was enabled
+	CheckedRows        int64            `json:"checked_rows"`
+	CheckedCheckpoints int              `json:"checked_checkpoints"`
+	LastLogID          int64            `json:"last_log_id,omitempty"`
+	LastCheckpointID   int64            `json:"last_checkpoint_id,omitempty"` // Rows after this checkpoint are not yet pinned
+	PurgedThroughID    int64            `json:"purged_through_id,omitempty"`  // Rows up to this ID were removed by retention
+	SignaturesVerified bool             `json:"signatures_verified"`
+	FirstBreak         *AuditChainBreak `json:"first_break,omitempty"`
+	VerifiedAt         time.Time        `json:"verified_at"`
+}
@@ -0,0 +1,626 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent is the typed details of an audit log. Every action has exactly
+// one event type, so the action of a recorded log always matches its details.
+// New event types must be added to AuditEvents; a test fails otherwise.
+type AuditEvent interface {
+	AuditAction() AuditAction
+}
+
+// AggregatableAuditEvent is an event that may be recorded as one summary of
+// repeated occurrences, see audit.ThrottledRecorder
+type AggregatableAuditEvent interface {
+	AuditEvent
+	Aggregate(suppressed int, windowStart, windowEnd time.Time) AuditEvent
+}
+
+// AuditEvents returns the zero value of every audit event type, the catalog
+// from which audit.Catalog builds its registry and JSON Schema
+func AuditEvents() []AuditEvent {
+	return []AuditEvent{
+		UserRegisteredEvent{}, UserLoginEvent{}, UserLogoutEvent{}, LoginFailedEvent{}, PasswordChangedEvent{},
+		MFASetupEvent{}, MFAEnabledEvent{}, MFADisabledEvent{}, MFAVerifiedEvent{}, MFAFailedEvent{},
+		VaultCreatedEvent{}, VaultUpdatedEvent{}, VaultDeletedEvent{}, VaultAccessedEvent{}, VaultRestoredEvent{},
+		VaultPurgedEvent{}, VaultSyncedEvent{}, VaultKeyRotatedEvent{}, VaultKDFUpgradedEvent{}, VaultSearchedEvent{},
+		EntryCreatedEvent{}, EntryUpdatedEvent{}, EntryDeletedEvent{}, EntryAccessedEvent{}, EntryRestoredEvent{},
+		EntryPurgedEvent{}, EntryBatchEvent{}, EntryMovedEvent{}, EntryCopiedEvent{}, EntryFolderChangedEvent{},
+		EntryTaggedEvent{}, EntryFavoriteEvent{}, EntryIndexedEvent{}, EntryAutofillUpdatedEvent{},
+		EntryVersionsListedEvent{}, EntryVersionAccessedEvent{}, EntryVersionRestoredEvent{},
+		FolderCreatedEvent{}, FolderRenamedEvent{}, FolderMovedEvent{}, FolderDeletedEvent{},
+		TagCreatedEvent{}, TagDeletedEvent{},
+		APITokenCreatedEvent{}, APITokenRevokedEvent{}, AutofillLookupEvent{},
+		ShareCreatedEvent{}, ShareAccessedEvent{}, ShareAccessDeniedEvent{}, ShareRevokedEvent{}, SharePurgedEvent{},
+		EmergencyAccessInvitedEvent{}, EmergencyAccessAcceptedEvent{}, EmergencyAccessUpdatedEvent{},
+		EmergencyAccessRevokedEvent{}, EmergencyAccessRecoveryRequestedEvent{}, EmergencyAccessRecoveryApprovedEvent{},
+		EmergencyAccessRecoveryRejectedEvent{}, EmergencyAccessKeyAccessedEvent{}, EmergencyAccessVaultAccessedEvent{},
+		AttachmentUploadedEvent{}, AttachmentDownloadedEvent{}, AttachmentDeletedEvent{},
+		TrashPurgedEvent{},
+		SecurityCSRFRejectedEvent{}, SecurityRateLimitedEvent{}, SecuritySessionInvalidEvent{},
+		SecurityPanicRecoveredEvent{}, SecurityEventsSuppressedEvent{},
+		AuditVerifiedEvent{}, AuditPurgedEvent{}, AuditExportedEvent{},
+	}
+}
+
+// Authentication events
+
+// UserRegisteredEvent records a new account
+type UserRegisteredEvent struct{}
+
+// UserLoginEvent records a successful login
+type UserLoginEvent struct{}
+
+// UserLogoutEvent records a logout
+type UserLogoutEvent struct{}
+
+// LoginFailedEvent records a rejected login; Email is only set for unknown accounts
+type LoginFailedEvent struct {
+	Email  string `json:"email,omitempty"`
+	Reason string `json:"reason"` // user_not_found or invalid_password
+}
+
+// PasswordChangedEvent records a changed account password
+type PasswordChangedEvent struct{}
+
+func (UserRegisteredEvent) AuditAction() AuditAction  { return ActionUserRegistered }
+func (UserLoginEvent) AuditAction() AuditAction       { return ActionUserLogin }
+func (UserLogoutEvent) AuditAction() AuditAction      { return ActionUserLogout }
+func (LoginFailedEvent) AuditAction() AuditAction     { return ActionLoginFailed }
+func (PasswordChangedEvent) AuditAction() AuditAction { return ActionPasswordChanged }
+
+// MFA events
+
+// MFASetupEvent records a generated MFA secret
+type MFASetupEvent struct{}
+
+// MFAEnabledEvent records enabled MFA
+type MFAEnabledEvent struct{}
+
+// MFADisabledEvent records disabled MFA
+type MFADisabledEvent struct{}
+
+// MFAVerifiedEvent records an accepted MFA code
+type MFAVerifiedEvent struct{}
+
+// MFAFailedEvent records a rejected MFA code
+type MFAFailedEvent struct{}
+
+func (MFASetupEvent) AuditAction() AuditAction    { return ActionMFASetup }
+func (MFAEnabledEvent) AuditAction() AuditAction  { return ActionMFAEnabled }
+func (MFADisabledEvent) AuditAction() AuditAction { return ActionMFADisabled }
+func (MFAVerifiedEvent) AuditAction() AuditAction { return ActionMFAVerified }
+func (MFAFailedEvent) AuditAction() AuditAction   { return ActionMFAFailed }
+
+// Vault events
+
+// VaultRef identifies the vault of an event
+type VaultRef struct {
+	VaultID uuid.UUID `json:"vault_id"`
+}
+
+// VaultCreatedEvent records a new vault
+type VaultCreatedEvent struct {
+	VaultID      uuid.UUID `json:"vault_id"`
+	VaultName    string    `json:"vault_name"`
+	KDFAlgorithm string    `json:"kdf_algorithm"`
+}
+
+// VaultUpdatedEvent records a renamed vault and a changed version retention
+type VaultUpdatedEvent struct {
+	VaultID          uuid.UUID `json:"vault_id"`
+	VersionRetention *int      `json:"version_retention,omitempty"`
+}
+
+// VaultDeletedEvent records a vault moved to the trash
+type VaultDeletedEvent VaultRef
+
+// VaultAccessedEvent records reading a vault's contents
+type VaultAccessedEvent struct {
+	VaultID   uuid.UUID `json:"vault_id"`
+	Operation string    `json:"operation"`
+}
+
+// VaultRestoredEvent records a vault restored from the trash
+type VaultRestoredEvent VaultRef
+
+// VaultPurgedEvent records a vault permanently deleted from the trash
+type VaultPurgedEvent VaultRef
+
+// VaultSyncedEvent records a sync of all vaults
+type VaultSyncedEvent struct {
+	Changes int  `json:"changes"`
+	Full    bool `json:"full"`
+}
+
+// VaultKeyChange describes a vault key rotation
+type VaultKeyChange struct {
+	VaultID       uuid.UUID `json:"vault_id"`
+	KeyGeneration int       `json:"key_generation"`
+	Entries       int       `json:"entries"`           // Entries re-encrypted
+	Folders       int       `json:"folders,omitempty"` // Folder names re-encrypted
+	Tags          int       `json:"tags,omitempty"`    // Tags re-keyed
+}
+
+// VaultKeyRotatedEvent records a rotated vault key
+type VaultKeyRotatedEvent VaultKeyChange
+
+// VaultKDFUpgradedEvent records a vault key rotated to a new key derivation function
+type VaultKDFUpgradedEvent struct {
+	VaultKeyChange
+	PreviousKDFAlgorithm string `json:"previous_kdf_algorithm"`
+	KDFAlgorithm         string `json:"kdf_algorithm"`
+}
+
+// VaultSearchedEvent records a blind index search
+type VaultSearchedEvent struct {
+	VaultID uuid.UUID `json:"vault_id"`
+	Tokens  int       `json:"tokens"`
+	Results int       `json:"results"`
+}
+
+func (VaultCreatedEvent) AuditAction() AuditAction     { return ActionVaultCreated }
+func (VaultUpdatedEvent) AuditAction() AuditAction     { return ActionVaultUpdated }
+func (VaultDeletedEvent) AuditAction() AuditAction     { return ActionVaultDeleted }
+func (VaultAccessedEvent) AuditAction() AuditAction    { return ActionVaultAccessed }
+func (VaultRestoredEvent) AuditAction() AuditAction    { return ActionVaultRestored }
+func (VaultPurgedEvent) AuditAction() AuditAction      { return ActionVaultPurged }
+func (VaultSyncedEvent) AuditAction() AuditAction      { return ActionVaultSynced }
+func (VaultKeyRotatedEvent) AuditAction() AuditAction  { return ActionVaultKeyRotated }
+func (VaultKDFUpgradedEvent) AuditAction() AuditAction { return ActionVaultKDFUpgraded }
+func (VaultSearchedEvent) AuditAction() AuditAction    { return ActionVaultSearched }
+
+// Entry events
+
+// EntryRef identifies the entry of an event and its vault
+type EntryRef struct {
+	EntryID uuid.UUID `json:"entry_id"`
+	VaultID uuid.UUID `json:"vault_id"`
+}
+
+// EntryCreatedEvent records a new entry
+type EntryCreatedEvent EntryRef
+
+// EntryUpdatedEvent records a changed entry
+type EntryUpdatedEvent struct {
+	EntryID uuid.UUID `json:"entry_id"`
+}
+
+// EntryDeletedEvent records an entry moved to the trash
+type EntryDeletedEvent struct {
+	EntryID uuid.UUID `json:"entry_id"`
+}
+
+// EntryAccessedEvent records a read entry
+type EntryAccessedEvent EntryRef
+
+// EntryRestoredEvent records an entry restored from the trash
+type EntryRestoredEvent EntryRef
+
+// EntryPurgedEvent records an entry permanently deleted from the trash
+type EntryPurgedEvent EntryRef
+
+// EntryBatchEvent summarises an applied batch of entry operations
+type EntryBatchEvent struct {
+	VaultID    uuid.UUID `json:"vault_id"`
+	Operations int       `json:"operations"`
+	Created    int       `json:"created"`
+	Updated    int       `json:"updated"`
+	Deleted    int       `json:"deleted"`
+	Moved      int       `json:"moved"`
+}
+
+// EntryTransfer describes an entry moved or copied between vaults. The event
+// is recorded once for each vault; VaultID and Direction name the vault.
+type EntryTransfer struct {
+	VaultID       uuid.UUID `json:"vault_id"`
+	Direction     string    `json:"direction"` // out of the source vault or in to the target vault
+	EntryID       uuid.UUID `json:"entry_id"`
+	TargetEntryID uuid.UUID `json:"target_entry_id"`
+	SourceVaultID uuid.UUID `json:"source_vault_id"`
+	TargetVaultID uuid.UUID `json:"target_vault_id"`
+}
+
+// EntryMovedEvent records an entry moved to another vault
+type EntryMovedEvent EntryTransfer
+
+// EntryCopiedEvent records an entry copied to another vault
+type EntryCopiedEvent EntryTransfer
+
+// EntryFolderChangedEvent records an entry filed into another folder; nil is the vault root
+type EntryFolderChangedEvent struct {
+	EntryRef
+	OldFolderID *uuid.UUID `json:"old_folder_id"`
+	NewFolderID *uuid.UUID `json:"new_folder_id"`
+}
+
+// EntryTaggedEvent records replaced entry tags
+type EntryTaggedEvent struct {
+	EntryRef
+	Tags int `json:"tags"`
+}
+
+// EntryFavoriteEvent records a changed favorite flag
+type EntryFavoriteEvent struct {
+	EntryRef
+	Favorite bool `json:"favorite"`
+}
+
+// EntryIndexedEvent records replaced blind index search tokens
+type EntryIndexedEvent struct {
+	EntryRef
+	Tokens int `json:"tokens"`
+}
+
+// EntryAutofillUpdatedEvent records replaced autofill domain hashes
+type EntryAutofillUpdatedEvent struct {
+	EntryRef
+	Hashes int `json:"hashes"`
+}
+
+// EntryVersionsListedEvent records a listed entry history
+type EntryVersionsListedEvent EntryRef
+
+// EntryVersionAccessedEvent records a read entry version
+type EntryVersionAccessedEvent struct {
+	EntryRef
+	Version int `json:"version"`
+}
+
+// EntryVersionRestoredEvent records an entry version restored as a new version
+type EntryVersionRestoredEvent struct {
+	EntryRef
+	RestoredVersion int `json:"restored_version"`
+	NewVersion      int `json:"new_version"`
+}
+
+func (EntryCreatedEvent) AuditAction() AuditAction         { return ActionEntryCreated }
+func (EntryUpdatedEvent) AuditAction() AuditAction         { return ActionEntryUpdated }
+func (EntryDeletedEvent) AuditAction() AuditAction         { return ActionEntryDeleted }
+func (EntryAccessedEvent) AuditAction() AuditAction        { return ActionEntryAccessed }
+func (EntryRestoredEvent) AuditAction() AuditAction        { return ActionEntryRestored }
+func (EntryPurgedEvent) AuditAction() AuditAction          { return ActionEntryPurged }
+func (EntryBatchEvent) AuditAction() AuditAction           { return ActionEntryBatch }
+func (EntryMovedEvent) AuditAction() AuditAction           { return ActionEntryMoved }
+func (EntryCopiedEvent) AuditAction() AuditAction          { return ActionEntryCopied }
+func (EntryFolderChangedEvent) AuditAction() AuditAction   { return ActionEntryFolderChanged }
+func (EntryTaggedEvent) AuditAction() AuditAction          { return ActionEntryTagged }
+func (EntryFavoriteEvent) AuditAction() AuditAction        { return ActionEntryFavorite }
+func (EntryIndexedEvent) AuditAction() AuditAction         { return ActionEntryIndexed }
+func (EntryAutofillUpdatedEvent) AuditAction() AuditAction { return ActionEntryAutofillSet }
+func (EntryVersionsListedEvent) AuditAction() AuditAction  { return ActionEntryVersionsListed }
+func (EntryVersionAccessedEvent) AuditAction() AuditAction { return ActionEntryVersionAccessed }
+func (EntryVersionRestoredEvent) AuditAction() AuditAction { return ActionEntryVersionRestored }
+
+// Folder and tag events
+
+// FolderRef identifies the folder of an event and its vault
+type FolderRef struct {
+	FolderID uuid.UUID `json:"folder_id"`
+	VaultID  uuid.UUID `json:"vault_id"`
+}
+
+// FolderCreatedEvent records a new folder
+type FolderCreatedEvent FolderRef
+
+// FolderRenamedEvent records a renamed folder
+type FolderRenamedEvent FolderRef
+
+// FolderMovedEvent records a folder moved to another parent; nil is the vault root
+type FolderMovedEvent struct {
+	FolderRef
+	OldParentID *uuid.UUID `json:"old_parent_id"`
+	NewParentID *uuid.UUID `json:"new_parent_id"`
+}
+
+// FolderDeletedEvent records a deleted folder
+type FolderDeletedEvent FolderRef
+
+// TagRef identifies the tag of an event and its vault
+type TagRef struct {
+	TagID   uuid.UUID `json:"tag_id"`
+	VaultID uuid.UUID `json:"vault_id"`
+}
+
+// TagCreatedEvent records a new tag
+type TagCreatedEvent TagRef
+
+// TagDeletedEvent records a deleted tag
+type TagDeletedEvent TagRef
+
+func (FolderCreatedEvent) AuditAction() AuditAction { return ActionFolderCreated }
+func (FolderRenamedEvent) AuditAction() AuditAction { return ActionFolderRenamed }
+func (FolderMovedEvent) AuditAction() AuditAction   { return ActionFolderMoved }
+func (FolderDeletedEvent) AuditAction() AuditAction { return ActionFolderDeleted }
+func (TagCreatedEvent) AuditAction() AuditAction    { return ActionTagCreated }
+func (TagDeletedEvent) AuditAction() AuditAction    { return ActionTagDeleted }
+
+// API token and autofill events
+
+// APITokenCreatedEvent records a new API token
+type APITokenCreatedEvent struct {
+	TokenID uuid.UUID `json:"token_id"`
+	Name    string    `json:"name"`
+	Scopes  []string  `json:"scopes"`
+}
+
+// APITokenRevokedEvent records a revoked API token
+type APITokenRevokedEvent struct {
+	TokenID uuid.UUID `json:"token_id"`
+}
+
+// AutofillLookupEvent records an autofill lookup; TokenID is set for API token requests
+type AutofillLookupEvent struct {
+	Matches int        `json:"matches"`
+	TokenID *uuid.UUID `json:"token_id,omitempty"`
+}
+
+func (APITokenCreatedEvent) AuditAction() AuditAction { return ActionAPITokenCreated }
+func (APITokenRevokedEvent) AuditAction() AuditAction { return ActionAPITokenRevoked }
+func (AutofillLookupEvent) AuditAction() AuditAction  { return ActionAutofillLookup }
+
+// Share link events
+
+// ShareRef identifies the share link of an event
+type ShareRef struct {
+	ShareID string `json:"share_id"`
+}
+
+// ShareCreatedEvent records a new share link
+type ShareCreatedEvent struct {
+	ShareID     string    `json:"share_id"`
+	MaxViews    *int      `json:"max_views"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	HasPassword bool      `json:"has_password"`
+}
+
+// ShareAccessedEvent records a viewed share link
+type ShareAccessedEvent struct {
+	ShareID   string `json:"share_id"`
+	ViewCount int    `json:"view_count"`
+}
+
+// ShareAccessDeniedEvent records a share link view with a wrong password
+type ShareAccessDeniedEvent ShareRef
+
+// ShareRevokedEvent records a revoked share link
+type ShareRevokedEvent ShareRef
+
+// SharePurgedEvent records share links deleted after expiry
+type SharePurgedEvent struct {
+	Shares int64     `json:"shares"`
+	Cutoff time.Time `json:"cutoff"`
+}
+
+func (ShareCreatedEvent) AuditAction() AuditAction      { return ActionShareCreated }
+func (ShareAccessedEvent) AuditAction() AuditAction     { return ActionShareAccessed }
+func (ShareAccessDeniedEvent) AuditAction() AuditAction { return ActionShareAccessDenied }
+func (ShareRevokedEvent) AuditAction() AuditAction      { return ActionShareRevoked }
+func (SharePurgedEvent) AuditAction() AuditAction       { return ActionSharePurged }
+
+// Emergency access events; each is recorded for both the grantor and the
+// grantee, for the grantee only once a user has the invited address
+
+// EmergencyAccessRef identifies the grant of an event and who acted on it
+type EmergencyAccessRef struct {
+	EmergencyAccessID uuid.UUID  `json:"emergency_access_id"`
+	GrantorID         uuid.UUID  `json:"grantor_id"`
+	GranteeID         *uuid.UUID `json:"grantee_id"` // Null for invitations, see EmergencyAccess.DisclosedGranteeID
+	Actor             string     `json:"actor"`      // grantor, grantee or system
+}
+
+// AuditRef returns the reference of grant e for an event performed by actor
+func (e *EmergencyAccess) AuditRef(actor string) EmergencyAccessRef {
+	return EmergencyAccessRef{
+		EmergencyAccessID: e.ID,
+		GrantorID:         e.GrantorID,
+		GranteeID:         e.DisclosedGranteeID(),
+		Actor:             actor,
+	}
+}
+
+// EmergencyAccessTransition describes a change of a grant's status
+type EmergencyAccessTransition struct {
+	EmergencyAccessRef
+	PreviousStatus string `json:"previous_status"`
+	Status         string `json:"status"`
+}
+
+// EmergencyAccessInvitedEvent records a new grant
+type EmergencyAccessInvitedEvent struct {
+	EmergencyAccessRef
+	WaitDays int `json:"wait_days"`
+}
+
+// EmergencyAccessAcceptedEvent records an accepted invitation
+type EmergencyAccessAcceptedEvent EmergencyAccessTransition
+
+// EmergencyAccessUpdatedEvent records a changed wait period or wrapped key
+type EmergencyAccessUpdatedEvent struct {
+	EmergencyAccessRef
+	WaitDays   int  `json:"wait_days"`
+	KeyUpdated bool `json:"key_updated"`
+}
+
+// EmergencyAccessRevokedEvent records a revoked grant
+type EmergencyAccessRevokedEvent struct {
+	EmergencyAccessRef
+	PreviousStatus string `json:"previous_status"`
+}
+
+// EmergencyAccessRecoveryRequestedEvent records a recovery request
+type EmergencyAccessRecoveryRequestedEvent EmergencyAccessTransition
+
+// EmergencyAccessRecoveryApprovedEvent records a recovery approved by the grantor or after the wait period
+type EmergencyAccessRecoveryApprovedEvent EmergencyAccessTransition
+
+// EmergencyAccessRecoveryRejectedEvent records a rejected recovery request
+type EmergencyAccessRecoveryRejectedEvent EmergencyAccessTransition
+
+// EmergencyAccessKeyAccessedEvent records the grantee reading the released key
+type EmergencyAccessKeyAccessedEvent EmergencyAccessRef
+
+// EmergencyAccessVaultAccessedEvent records the grantee reading a vault
+type EmergencyAccessVaultAccessedEvent struct {
+	EmergencyAccessRef
+	VaultID uuid.UUID `json:"vault_id"`
+}
+
+func (EmergencyAccessInvitedEvent) AuditAction() AuditAction  { return ActionEmergencyAccessInvited }
+func (EmergencyAccessAcceptedEvent) AuditAction() AuditAction { return ActionEmergencyAccessAccepted }
+func (EmergencyAccessUpdatedEvent) AuditAction() AuditAction  { return ActionEmergencyAccessUpdated }
+func (EmergencyAccessRevokedEvent) AuditAction() AuditAction  { return ActionEmergencyAccessRevoked }
+func (EmergencyAccessRecoveryRequestedEvent) AuditAction() AuditAction {
+	return ActionEmergencyAccessRecoveryRequested
+}
+func (EmergencyAccessRecoveryApprovedEvent) AuditAction() AuditAction {
+	return ActionEmergencyAccessRecoveryApproved
+}
+func (EmergencyAccessRecoveryRejectedEvent) AuditAction() AuditAction {
+	return ActionEmergencyAccessRecoveryRejected
+}
+func (EmergencyAccessKeyAccessedEvent) AuditAction() AuditAction {
+	return ActionEmergencyAccessKeyAccessed
+}
+func (EmergencyAccessVaultAccessedEvent) AuditAction() AuditAction {
+	return ActionEmergencyAccessVaultAccessed
+}
+
+// Attachment events
+
+// AttachmentRef identifies the attachment of an event, its entry and vault
+type AttachmentRef struct {
+	AttachmentID uuid.UUID `json:"attachment_id"`
+	EntryID      uuid.UUID `json:"entry_id"`
+	VaultID      uuid.UUID `json:"vault_id"`
+}
+
+// AttachmentUploadedEvent records an uploaded attachment
+type AttachmentUploadedEvent struct {
+	AttachmentRef
+	Size int64 `json:"size"`
+}
+
+// AttachmentDownloadedEvent records a downloaded attachment
+type AttachmentDownloadedEvent AttachmentRef
+
+// AttachmentDeletedEvent records a deleted attachment
+type AttachmentDeletedEvent AttachmentRef
+
+func (AttachmentUploadedEvent) AuditAction() AuditAction   { return ActionAttachmentUploaded }
+func (AttachmentDownloadedEvent) AuditAction() AuditAction { return ActionAttachmentDownloaded }
+func (AttachmentDeletedEvent) AuditAction() AuditAction    { return ActionAttachmentDeleted }
+
+// Trash events
+
+// TrashPurgedEvent records trashed items deleted after the retention period
+type TrashPurgedEvent struct {
+	Vaults  int64     `json:"vaults"`
+	Entries int64     `json:"entries"`
+	Cutoff  time.Time `json:"cutoff"`
+}
+
+func (TrashPurgedEvent) AuditAction() AuditAction { return ActionTrashPurged }
+
+// Security middleware events
+
+// SecurityRequest describes a request rejected by a security middleware. A
+// summary of repeated identical requests carries the number of suppressed
+// occurrences and the window in which they happened.
+type SecurityRequest struct {
+	Route       string     `json:"route"` // Route pattern, or unmatched
+	Method      string     `json:"method"`
+	Reason      string     `json:"reason"`
+	Suppressed  int        `json:"suppressed,omitempty"`
+	WindowStart *time.Time `json:"window_start,omitempty"`
+	WindowEnd   *time.Time `json:"window_end,omitempty"`
+}
+
+// aggregate returns the summary of r and suppressed repetitions
+func (r SecurityRequest) aggregate(suppressed int, windowStart, windowEnd time.Time) SecurityRequest {
+	r.Suppressed = suppressed
+	r.WindowStart = &windowStart
+	r.WindowEnd = &windowEnd
+	return r
+}
+
+// SecurityCSRFRejectedEvent records a request with a missing or invalid CSRF token
+type SecurityCSRFRejectedEvent SecurityRequest
+
+// SecurityRateLimitedEvent records a request over a rate limit; Reason names the limiter
+type SecurityRateLimitedEvent SecurityRequest
+
+// SecuritySessionInvalidEvent records a request with an unknown or expired session
+type SecuritySessionInvalidEvent SecurityRequest
+
+// SecurityPanicRecoveredEvent records a request that panicked; Reason is the panic value's type
+type SecurityPanicRecoveredEvent SecurityRequest
+
+// SecurityEventsSuppressedEvent records security events dropped because too
+// many distinct events occurred in one window
+type SecurityEventsSuppressedEvent struct {
+	EventAction AuditAction `json:"event_action"`
+	Suppressed  int         `json:"suppressed"`
+	Reason      string      `json:"reason"`
+}
+
+func (SecurityCSRFRejectedEvent) AuditAction() AuditAction     { return ActionSecurityCSRFRejected }
+func (SecurityRateLimitedEvent) AuditAction() AuditAction      { return ActionSecurityRateLimited }
+func (SecuritySessionInvalidEvent) AuditAction() AuditAction   { return ActionSecuritySessionInvalid }
+func (SecurityPanicRecoveredEvent) AuditAction() AuditAction   { return ActionSecurityPanicRecovered }
+func (SecurityEventsSuppressedEvent) AuditAction() AuditAction { return ActionSecurityEventsSuppressed }
+
+// Aggregate implements AggregatableAuditEvent
+func (e SecurityCSRFRejectedEvent) Aggregate(suppressed int, windowStart, windowEnd time.Time) AuditEvent {
+	return SecurityCSRFRejectedEvent(SecurityRequest(e).aggregate(suppressed, windowStart, windowEnd))
+}
+
+// Aggregate implements AggregatableAuditEvent
+func (e SecurityRateLimitedEvent) Aggregate(suppressed int, windowStart, windowEnd time.Time) AuditEvent {
+	return SecurityRateLimitedEvent(SecurityRequest(e).aggregate(suppressed, windowStart, windowEnd))
+}
+
+// Aggregate implements AggregatableAuditEvent
+func (e SecuritySessionInvalidEvent) Aggregate(suppressed int, windowStart, windowEnd time.Time) AuditEvent {
+	return SecuritySessionInvalidEvent(SecurityRequest(e).aggregate(suppressed, windowStart, windowEnd))
+}
+
+// Aggregate implements AggregatableAuditEvent
+func (e SecurityPanicRecoveredEvent) Aggregate(suppressed int, windowStart, windowEnd time.Time) AuditEvent {
+	return SecurityPanicRecoveredEvent(SecurityRequest(e).aggregate(suppressed, windowStart, windowEnd))
+}
+
+// Audit trail events
+
+// AuditVerifiedEvent records a hash chain verification; the break is set if it failed
+type AuditVerifiedEvent struct {
+	Valid       bool   `json:"valid"`
+	CheckedRows int64  `json:"checked_rows"`
+	BreakLogID  int64  `json:"break_log_id,omitempty"`
+	BreakReason string `json:"break_reason,omitempty"`
+}
+
+// AuditPurgedEvent records audit logs dropped by retention; the remaining
+// chain continues from ThroughHash, the hash of the last dropped log
+type AuditPurgedEvent struct {
+	ThroughID   int64     `json:"through_id"`
+	ThroughHash string    `json:"through_hash"` // hex, empty if the last dropped log was unchained
+	Rows        int64     `json:"rows"`
+	Cutoff      time.Time `json:"cutoff"`
+	Partitions  []string  `json:"partitions,omitempty"`
+}
+
+// AuditExportedEvent records an audit log export
+type AuditExportedEvent struct {
+	Format string `json:"format"`
+	Scope  string `json:"scope"` // own or all
+	Rows   int64  `json:"rows"`
+}
+
+func (AuditVerifiedEvent) AuditAction() AuditAction { return ActionAuditVerified }
+func (AuditPurgedEvent) AuditAction() AuditAction   { return ActionAuditPurged }
+func (AuditExportedEvent) AuditAction() AuditAction { return ActionAuditExported }
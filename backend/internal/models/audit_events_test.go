@@ -0,0 +1,81 @@
+package models
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestAuditEventsListsEveryEventType finds every type of this package with an
+// AuditAction method and checks that AuditEvents lists it. An event type left
+// off the list would compile but be rejected when recorded.
+func TestAuditEventsListsEveryEventType(t *testing.T) {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, ".", func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("failed to parse package: %v", err)
+	}
+
+	implemented := map[string]bool{}
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Name.Name != "AuditAction" || fn.Recv == nil || len(fn.Recv.List) != 1 {
+					continue
+				}
+				if name := receiverName(fn.Recv.List[0].Type); name != "" {
+					implemented[name] = true
+				}
+			}
+		}
+	}
+	if len(implemented) == 0 {
+		t.Fatal("found no AuditAction methods")
+	}
+
+	listed := map[string]bool{}
+	for _, event := range AuditEvents() {
+		name := reflect.TypeOf(event).Name()
+		if listed[name] {
+			t.Errorf("AuditEvents lists %s twice", name)
+		}
+		listed[name] = true
+	}
+
+	var missing []string
+	for name := range implemented {
+		if !listed[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("event types missing from AuditEvents: %s", strings.Join(missing, ", "))
+	}
+
+	for name := range listed {
+		if !implemented[name] {
+			t.Errorf("AuditEvents lists %s, which has no AuditAction method of its own", name)
+		}
+	}
+}
+
+// receiverName returns the type name of a method receiver, dereferencing pointers
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import "github.com/google/uuid"
+
+// EntryAutofillRequest represents the request to replace the autofill domain
+// hashes of an entry. Each hash is a client-computed HMAC-SHA256 of the
+// registrable domain (eTLD+1) of one of the entry's URLs.
+type EntryAutofillRequest struct {
+	DomainHashes []string `json:"domain_hashes" binding:"max=16,dive,len=64,hexadecimal"` // Hex-encoded 32-byte hashes
+}
+
+// AutofillLookupRequest represents an autofill lookup. Clients holding several
+// vault keys send one hash per key.
+type AutofillLookupRequest struct {
+	DomainHashes []string `json:"domain_hashes" binding:"required,min=1,max=32,dive,len=64,hexadecimal"` // Hex-encoded 32-byte hashes
+}
+
+// AutofillMatch is an entry matching an autofill lookup
+type AutofillMatch struct {
+	EntryID uuid.UUID `json:"entry_id" db:"entry_id"`
+	VaultID uuid.UUID `json:"vault_id" db:"vault_id"`
+}
+
+// AutofillLookupResponse represents the autofill lookup response
+type AutofillLookupResponse struct {
+	Matches []*AutofillMatch `json:"matches"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Emergency access states. A grantor invites a grantee, the grantee accepts,
+// and may later request recovery. The grantor can reject the request (back to
+// accepted) or approve it early; otherwise it is approved automatically once
+// the wait period has passed. An invitation to an address nobody has
+// registered has no grantee until a user registers with it; to keep addresses
+// from being probed, the grantee's ID is only disclosed once they accepted.
+const (
+	EmergencyAccessInvited           = "invited"
+	EmergencyAccessAccepted          = "accepted"
+	EmergencyAccessRecoveryRequested = "recovery_requested"
+	EmergencyAccessRecoveryApproved  = "recovery_approved"
+)
+
+// EmergencyAccess represents a grant of emergency access from a grantor to a trusted grantee
+type EmergencyAccess struct {
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	GrantorID           uuid.UUID  `json:"grantor_id" db:"grantor_id"`
+	GranteeID           *uuid.UUID `json:"grantee_id" db:"grantee_id"` // Nil until a user registers with GranteeEmail
+	GrantorEmail        string     `json:"grantor_email" db:"grantor_email"`
+	GranteeEmail        string     `json:"grantee_email" db:"grantee_email"`
+	Status              string     `json:"status" db:"status"`
+	WaitDays            int        `json:"wait_days" db:"wait_days"`
+	WrappedKey          []byte     `json:"-" db:"wrapped_key"`
+	RecoveryRequestedAt *time.Time `json:"recovery_requested_at,omitempty" db:"recovery_requested_at"`
+	RecoveryApprovedAt  *time.Time `json:"recovery_approved_at,omitempty" db:"recovery_approved_at"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// EmergencyAccessInviteRequest represents the request to invite a trusted contact
+type EmergencyAccessInviteRequest struct {
+	GranteeEmail string `json:"grantee_email" binding:"required,email"`
+	WaitDays     int    `json:"wait_days" binding:"required,min=1,max=90"`
+	WrappedKey   string `json:"wrapped_key" binding:"required,max=65536"` // Hex-encoded key blob wrapped for the grantee
+}
+
+// EmergencyAccessUpdateRequest represents the request to change the wait period or wrapped key
+type EmergencyAccessUpdateRequest struct {
+	WaitDays   *int   `json:"wait_days,omitempty" binding:"omitempty,min=1,max=90"`
+	WrappedKey string `json:"wrapped_key,omitempty" binding:"omitempty,max=65536"` // Hex-encoded; required again after a key rotation
+}
+
+// EmergencyAccessResponse represents an emergency access grant
+type EmergencyAccessResponse struct {
+	ID                  uuid.UUID  `json:"id"`
+	GrantorID           uuid.UUID  `json:"grantor_id"`
+	GrantorEmail        string     `json:"grantor_email"`
+	GranteeID           *uuid.UUID `json:"grantee_id,omitempty"` // Set once the grantee has accepted
+	GranteeEmail        string     `json:"grantee_email"`
+	Status              string     `json:"status"`
+	WaitDays            int        `json:"wait_days"`
+	RecoveryRequestedAt *time.Time `json:"recovery_requested_at,omitempty"`
+	RecoveryAvailableAt *time.Time `json:"recovery_available_at,omitempty"` // When the key is released unless the grantor rejects
+	RecoveryApprovedAt  *time.Time `json:"recovery_approved_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// EmergencyAccessKeyResponse carries the wrapped key released to the grantee
+type EmergencyAccessKeyResponse struct {
+	WrappedKey string `json:"wrapped_key"` // Hex-encoded
+}
+
+// ToResponse converts EmergencyAccess to EmergencyAccessResponse
+func (e *EmergencyAccess) ToResponse() EmergencyAccessResponse {
+	var availableAt *time.Time
+	if e.Status == EmergencyAccessRecoveryRequested && e.RecoveryRequestedAt != nil {
+		t := e.RecoveryRequestedAt.Add(time.Duration(e.WaitDays) * 24 * time.Hour)
+		availableAt = &t
+	}
+	return EmergencyAccessResponse{
+		ID:                  e.ID,
+		GrantorID:           e.GrantorID,
+		GrantorEmail:        e.GrantorEmail,
+		GranteeID:           e.DisclosedGranteeID(),
+		GranteeEmail:        e.GranteeEmail,
+		Status:              e.Status,
+		WaitDays:            e.WaitDays,
+		RecoveryRequestedAt: e.RecoveryRequestedAt,
+		RecoveryAvailableAt: availableAt,
+		RecoveryApprovedAt:  e.RecoveryApprovedAt,
+		CreatedAt:           e.CreatedAt,
+		UpdatedAt:           e.UpdatedAt,
+	}
+}
+
+// DisclosedGranteeID returns the grantee's ID once the invitation is
+// accepted, and nil before, whether or not a user has the invited address
+func (e *EmergencyAccess) DisclosedGranteeID() *uuid.UUID {
+	if e.Status == EmergencyAccessInvited {
+		return nil
+	}
+	return e.GranteeID
+}
+
+// IsGrantee reports whether userID is the grantee of e
+func (e *EmergencyAccess) IsGrantee(userID uuid.UUID) bool {
+	return e.GranteeID != nil && *e.GranteeID == userID
+}
+
+// ToKeyResponse converts EmergencyAccess to EmergencyAccessKeyResponse
+func (e *EmergencyAccess) ToKeyResponse() EmergencyAccessKeyResponse {
+	return EmergencyAccessKeyResponse{WrappedKey: hex.EncodeToString(e.WrappedKey)}
+}
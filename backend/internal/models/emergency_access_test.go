@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestEmergencyAccessInvitationHidesGrantee checks that an invitation reads the
+// same to the grantor whether or not the invited address is registered
+func TestEmergencyAccessInvitationHidesGrantee(t *testing.T) {
+	registered := uuid.New()
+	invite := func(granteeID *uuid.UUID) *EmergencyAccess {
+		return &EmergencyAccess{
+			ID:           uuid.MustParse("0d6f5c1e-2b0a-4c1f-9b7e-8a3d2c1b0a99"),
+			GrantorID:    uuid.MustParse("5a4b3c2d-1e0f-4a9b-8c7d-6e5f4a3b2c1d"),
+			GranteeID:    granteeID,
+			GrantorEmail: "grantor@example.com",
+			GranteeEmail: "contact@example.com",
+			Status:       EmergencyAccessInvited,
+			WaitDays:     7,
+			CreatedAt:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			UpdatedAt:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		}
+	}
+
+	pending, resolved := invite(nil), invite(&registered)
+
+	pendingJSON, err := json.Marshal(pending.ToResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvedJSON, err := json.Marshal(resolved.ToResponse())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(pendingJSON) != string(resolvedJSON) {
+		t.Errorf("responses differ:\n%s\n%s", pendingJSON, resolvedJSON)
+	}
+
+	pendingRef, _ := json.Marshal(pending.AuditRef("grantor"))
+	resolvedRef, _ := json.Marshal(resolved.AuditRef("grantor"))
+	if string(pendingRef) != string(resolvedRef) {
+		t.Errorf("audit references differ:\n%s\n%s", pendingRef, resolvedRef)
+	}
+
+	if !resolved.IsGrantee(registered) || pending.IsGrantee(registered) {
+		t.Error("IsGrantee does not follow the linked grantee")
+	}
+
+	resolved.Status = EmergencyAccessAccepted
+	if id := resolved.ToResponse().GranteeID; id == nil || *id != registered {
+		t.Errorf("accepted grant discloses grantee %v, want %s", id, registered)
+	}
+}
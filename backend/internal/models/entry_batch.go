@@ -0,0 +1,56 @@
+package models
+
+import "github.com/google/uuid"
+
+// EntryBatchOp is an operation within a batch entry request
+type EntryBatchOp string
+
+// Batch operations
+const (
+	EntryBatchCreate EntryBatchOp = "create"
+	EntryBatchUpdate EntryBatchOp = "update"
+	EntryBatchDelete EntryBatchOp = "delete"
+	EntryBatchMove   EntryBatchOp = "move"
+)
+
+// Batch item statuses
+const (
+	EntryBatchStatusOK         = "ok"
+	EntryBatchStatusFailed     = "failed"
+	EntryBatchStatusRolledBack = "rolled_back"
+	EntryBatchStatusSkipped    = "skipped"
+)
+
+// EntryBatchItem is a single operation of a batch request.
+// create, update and move require encrypted_data and nonce; move re-encrypts
+// the entry for the target vault.
+type EntryBatchItem struct {
+	Op            EntryBatchOp `json:"op" binding:"required,oneof=create update delete move"`
+	EntryID       *uuid.UUID   `json:"entry_id,omitempty"`        // Required for update, delete and move
+	TargetVaultID *uuid.UUID   `json:"target_vault_id,omitempty"` // Required for move
+	EncryptedData string       `json:"encrypted_data,omitempty"`  // Hex-encoded encrypted data
+	Nonce         string       `json:"nonce,omitempty"`           // Hex-encoded 12 bytes
+}
+
+// EntryBatchRequest represents a batch of entry operations applied atomically
+type EntryBatchRequest struct {
+	Operations []EntryBatchItem `json:"operations" binding:"required,min=1,dive"`
+}
+
+// EntryBatchResult is the outcome of a single batch operation
+type EntryBatchResult struct {
+	Index   int                 `json:"index"`
+	Op      EntryBatchOp        `json:"op"`
+	Status  string              `json:"status"` // ok, failed, rolled_back or skipped
+	EntryID *uuid.UUID          `json:"entry_id,omitempty"`
+	Entry   *VaultEntryResponse `json:"entry,omitempty"`
+	Error   string              `json:"error,omitempty"`
+	Code    string              `json:"code,omitempty"` // Quota error code, see the usage endpoint
+}
+
+// EntryBatchResponse represents the result of a batch request.
+// When committed is false no operation was applied.
+type EntryBatchResponse struct {
+	Committed bool               `json:"committed"`
+	Results   []EntryBatchResult `json:"results"`
+}
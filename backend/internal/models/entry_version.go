@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EntryVersion represents a retained revision of a vault entry
+type EntryVersion struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EntryID       uuid.UUID  `json:"entry_id" db:"entry_id"`
+	Version       int        `json:"version" db:"version"`
+	EncryptedData []byte     `json:"encrypted_data" db:"encrypted_data"`
+	Nonce         []byte     `json:"nonce" db:"nonce"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// EntryVersionSummary represents a revision in the version listing (without ciphertext)
+type EntryVersionSummary struct {
+	Version   int        `json:"version"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	Current   bool       `json:"current"`
+}
+
+// EntryVersionResponse represents a single revision including its ciphertext
+type EntryVersionResponse struct {
+	EntryID       uuid.UUID  `json:"entry_id"`
+	Version       int        `json:"version"`
+	EncryptedData string     `json:"encrypted_data"` // Hex-encoded
+	Nonce         string     `json:"nonce"`          // Hex-encoded
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ToResponse converts EntryVersion to EntryVersionResponse
+func (v *EntryVersion) ToResponse() EntryVersionResponse {
+	return EntryVersionResponse{
+		EntryID:       v.EntryID,
+		Version:       v.Version,
+		EncryptedData: hex.EncodeToString(v.EncryptedData),
+		Nonce:         hex.EncodeToString(v.Nonce),
+		CreatedBy:     v.CreatedBy,
+		CreatedAt:     v.CreatedAt,
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Folder represents a folder in a vault's folder tree.
+// Its name is encrypted client-side with the vault key.
+type Folder struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	VaultID       uuid.UUID  `json:"vault_id" db:"vault_id"`
+	ParentID      *uuid.UUID `json:"parent_id,omitempty" db:"parent_id"`
+	EncryptedName []byte     `json:"encrypted_name" db:"encrypted_name"`
+	Nonce         []byte     `json:"nonce" db:"nonce"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// FolderCreateRequest represents the request to create a folder
+type FolderCreateRequest struct {
+	ParentID      *uuid.UUID `json:"parent_id,omitempty"`                        // Null creates a top-level folder
+	EncryptedName string     `json:"encrypted_name" binding:"required,max=2048"` // Hex-encoded encrypted name
+	Nonce         string     `json:"nonce" binding:"required,len=24"`            // Hex-encoded 12 bytes
+}
+
+// FolderRenameRequest represents the request to rename a folder
+type FolderRenameRequest struct {
+	EncryptedName string `json:"encrypted_name" binding:"required,max=2048"` // Hex-encoded encrypted name
+	Nonce         string `json:"nonce" binding:"required,len=24"`            // Hex-encoded 12 bytes
+}
+
+// FolderMoveRequest represents the request to move a folder within the tree
+type FolderMoveRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"` // Null moves the folder to the top level
+}
+
+// FolderResponse represents the folder response
+type FolderResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	VaultID       uuid.UUID  `json:"vault_id"`
+	ParentID      *uuid.UUID `json:"parent_id"`
+	EncryptedName string     `json:"encrypted_name"` // Hex-encoded
+	Nonce         string     `json:"nonce"`          // Hex-encoded
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ToResponse converts Folder to FolderResponse
+func (f *Folder) ToResponse() FolderResponse {
+	return FolderResponse{
+		ID:            f.ID,
+		VaultID:       f.VaultID,
+		ParentID:      f.ParentID,
+		EncryptedName: hex.EncodeToString(f.EncryptedName),
+		Nonce:         hex.EncodeToString(f.Nonce),
+		CreatedAt:     f.CreatedAt,
+		UpdatedAt:     f.UpdatedAt,
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// KDF algorithms supported for client-side vault key derivation
+const (
+	KDFPBKDF2SHA256 = "pbkdf2-sha256"
+	KDFArgon2id     = "argon2id"
+)
+
+// DefaultPBKDF2Iterations is the iteration count used by vaults created without explicit KDF settings
+const DefaultPBKDF2Iterations = 600000
+
+// KDF parameter bounds
+const (
+	minPBKDF2Iterations = 100000
+	maxPBKDF2Iterations = 10000000
+	minArgon2Memory     = 19456   // KiB
+	maxArgon2Memory     = 4194304 // KiB (4 GiB)
+	maxArgon2Iterations = 100
+	maxArgon2Lanes      = 16
+)
+
+// KDFParams holds the parameters of a vault's key derivation function.
+// PBKDF2 only uses Iterations; Argon2id uses Iterations as time cost (t),
+// Memory in KiB (m) and Parallelism (p).
+type KDFParams struct {
+	Iterations  int `json:"iterations"`
+	Memory      int `json:"memory,omitempty"`
+	Parallelism int `json:"parallelism,omitempty"`
+}
+
+// Value implements driver.Valuer for storing KDFParams as JSONB
+func (p KDFParams) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan implements sql.Scanner for reading KDFParams from JSONB
+func (p *KDFParams) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("unsupported kdf_params type %T", src)
+	}
+}
+
+// DefaultKDFParams returns the default parameters of an algorithm
+func DefaultKDFParams(algorithm string) (KDFParams, bool) {
+	if algorithm == KDFPBKDF2SHA256 {
+		return KDFParams{Iterations: DefaultPBKDF2Iterations}, true
+	}
+	return KDFParams{}, false
+}
+
+// ValidateKDF checks that the parameters are complete and within safe bounds for the algorithm
+func ValidateKDF(algorithm string, params KDFParams) error {
+	switch algorithm {
+	case KDFPBKDF2SHA256:
+		if params.Iterations < minPBKDF2Iterations || params.Iterations > maxPBKDF2Iterations {
+			return fmt.Errorf("pbkdf2 iterations must be between %d and %d", minPBKDF2Iterations, maxPBKDF2Iterations)
+		}
+		if params.Memory != 0 || params.Parallelism != 0 {
+			return fmt.Errorf("pbkdf2 does not take memory or parallelism parameters")
+		}
+	case KDFArgon2id:
+		if params.Memory < minArgon2Memory || params.Memory > maxArgon2Memory {
+			return fmt.Errorf("argon2id memory must be between %d and %d KiB", minArgon2Memory, maxArgon2Memory)
+		}
+		if params.Iterations < 1 || params.Iterations > maxArgon2Iterations {
+			return fmt.Errorf("argon2id iterations must be between 1 and %d", maxArgon2Iterations)
+		}
+		if params.Parallelism < 1 || params.Parallelism > maxArgon2Lanes {
+			return fmt.Errorf("argon2id parallelism must be between 1 and %d", maxArgon2Lanes)
+		}
+	default:
+		return fmt.Errorf("unsupported kdf_algorithm %q", algorithm)
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package models
+
+// ListResponse represents a page of results from a list endpoint
+type ListResponse[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"` // Empty when there are no further pages
+}
@@ -0,0 +1,33 @@
+package models
+
+import "github.com/google/uuid"
+
+// Search match modes
+const (
+	SearchMatchAll = "all"
+	SearchMatchAny = "any"
+)
+
+// EntrySearchTokensRequest represents the request to replace the blind index
+// tokens of an entry. Each token is an HMAC-SHA256 computed by the client with
+// a search key that never leaves the client.
+type EntrySearchTokensRequest struct {
+	Tokens []string `json:"tokens" binding:"dive,len=64,hexadecimal"` // Hex-encoded 32-byte tokens
+}
+
+// VaultSearchRequest represents a blind index search within a vault
+type VaultSearchRequest struct {
+	Tokens []string `json:"tokens" binding:"required,min=1,dive,len=64,hexadecimal"` // Hex-encoded 32-byte tokens
+	Match  string   `json:"match" binding:"omitempty,oneof=all any"`                 // Defaults to all
+}
+
+// SearchResult is an entry matching a search together with its number of matched tokens
+type SearchResult struct {
+	EntryID uuid.UUID `json:"entry_id" db:"entry_id"`
+	Matches int       `json:"matches" db:"matches"`
+}
+
+// VaultSearchResponse represents the search response, ordered by matches descending
+type VaultSearchResponse struct {
+	Results []*SearchResult `json:"results"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Share represents a one-time share link. The server stores ciphertext only;
+// the key is carried in the URL fragment, which browsers never send.
+type Share struct {
+	ID            string     `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	EncryptedData []byte     `json:"-" db:"encrypted_data"`
+	Nonce         []byte     `json:"-" db:"nonce"`
+	PasswordHash  *string    `json:"-" db:"password_hash"`
+	MaxViews      *int       `json:"max_views,omitempty" db:"max_views"`
+	ViewCount     int        `json:"view_count" db:"view_count"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// Available reports whether the share can still be accessed
+func (s *Share) Available() bool {
+	return s.RevokedAt == nil &&
+		time.Now().Before(s.ExpiresAt) &&
+		(s.MaxViews == nil || s.ViewCount < *s.MaxViews)
+}
+
+// ShareCreateRequest represents the request to create a share link
+type ShareCreateRequest struct {
+	EncryptedData  string `json:"encrypted_data" binding:"required"`                      // Hex-encoded encrypted data
+	Nonce          string `json:"nonce" binding:"required,len=24"`                        // Hex-encoded 12 bytes
+	MaxViews       *int   `json:"max_views,omitempty" binding:"omitempty,min=1,max=1000"` // Omit for unlimited views until expiry
+	ExpiresInHours int    `json:"expires_in_hours" binding:"required,min=1"`
+	Password       string `json:"password,omitempty" binding:"omitempty,min=8,max=128"` // Optional access password
+}
+
+// ShareAccessRequest represents an anonymous share retrieval
+type ShareAccessRequest struct {
+	Password string `json:"password,omitempty" binding:"max=128"`
+}
+
+// ShareResponse represents a share link as shown to its owner
+type ShareResponse struct {
+	ID          string     `json:"id"`
+	MaxViews    *int       `json:"max_views,omitempty"`
+	ViewCount   int        `json:"view_count"`
+	HasPassword bool       `json:"has_password"`
+	Available   bool       `json:"available"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ShareContentResponse represents the ciphertext returned to a recipient
+type ShareContentResponse struct {
+	EncryptedData  string    `json:"encrypted_data"` // Hex-encoded
+	Nonce          string    `json:"nonce"`          // Hex-encoded
+	ViewsRemaining *int      `json:"views_remaining,omitempty"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// ToResponse converts Share to ShareResponse
+func (s *Share) ToResponse() ShareResponse {
+	return ShareResponse{
+		ID:          s.ID,
+		MaxViews:    s.MaxViews,
+		ViewCount:   s.ViewCount,
+		HasPassword: s.PasswordHash != nil,
+		Available:   s.Available(),
+		ExpiresAt:   s.ExpiresAt,
+		CreatedAt:   s.CreatedAt,
+		RevokedAt:   s.RevokedAt,
+	}
+}
+
+// ToContentResponse converts Share to ShareContentResponse
+func (s *Share) ToContentResponse() ShareContentResponse {
+	var remaining *int
+	if s.MaxViews != nil {
+		r := *s.MaxViews - s.ViewCount
+		remaining = &r
+	}
+	return ShareContentResponse{
+		EncryptedData:  hex.EncodeToString(s.EncryptedData),
+		Nonce:          hex.EncodeToString(s.Nonce),
+		ViewsRemaining: remaining,
+		ExpiresAt:      s.ExpiresAt,
+	}
+}
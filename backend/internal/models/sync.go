@@ -0,0 +1,53 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// SyncChangeType identifies the kind of object a sync change refers to
+type SyncChangeType string
+
+const (
+	SyncChangeVault SyncChangeType = "vault"
+	SyncChangeEntry SyncChangeType = "entry"
+)
+
+// SyncChange represents a single change row as read from the database
+type SyncChange struct {
+	Type      SyncChangeType `db:"type"`
+	ID        uuid.UUID      `db:"id"`
+	VaultID   uuid.UUID      `db:"vault_id"`
+	ChangeXID int64          `db:"change_xid"`
+	ChangeSeq int64          `db:"change_seq"`
+	Deleted   bool           `db:"deleted"`
+}
+
+// Cursor returns the sync position just after this change
+func (c *SyncChange) Cursor() SyncCursor {
+	return SyncCursor{XID: c.ChangeXID, Seq: c.ChangeSeq}
+}
+
+// SyncCursor is a position in a user's change feed. Changes are ordered by the
+// transaction that made them, then by change_seq; the zero cursor is the start.
+type SyncCursor struct {
+	XID int64
+	Seq int64
+}
+
+// SyncChangeResponse represents a change returned to sync clients.
+// Deleted changes (tombstones) carry no vault or entry payload.
+type SyncChangeResponse struct {
+	Type    SyncChangeType      `json:"type"`
+	ID      uuid.UUID           `json:"id"`
+	VaultID uuid.UUID           `json:"vault_id"`
+	Deleted bool                `json:"deleted"`
+	Vault   *VaultResponse      `json:"vault,omitempty"`
+	Entry   *VaultEntryResponse `json:"entry,omitempty"`
+}
+
+// SyncResponse represents a page of changes since a cursor
+type SyncResponse struct {
+	Changes    []SyncChangeResponse `json:"changes"`
+	NextCursor string               `json:"next_cursor"` // Pass as cursor to fetch subsequent changes
+	HasMore    bool                 `json:"has_more"`
+}
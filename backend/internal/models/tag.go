@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag represents a tag in a vault. BlindID is a client-computed keyed hash
+// of the tag name; the readable name is optional and encrypted client-side.
+type Tag struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	VaultID       uuid.UUID `json:"vault_id" db:"vault_id"`
+	BlindID       []byte    `json:"blind_id" db:"blind_id"`
+	EncryptedName []byte    `json:"encrypted_name,omitempty" db:"encrypted_name"`
+	Nonce         []byte    `json:"nonce,omitempty" db:"nonce"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// EntryTag links an entry to a tag
+type EntryTag struct {
+	EntryID uuid.UUID `db:"entry_id"`
+	TagID   uuid.UUID `db:"tag_id"`
+}
+
+// TagCreateRequest represents the request to create a tag
+type TagCreateRequest struct {
+	BlindID       string `json:"blind_id" binding:"required,len=64"`                    // Hex-encoded 32-byte keyed hash
+	EncryptedName string `json:"encrypted_name,omitempty" binding:"omitempty,max=2048"` // Hex-encoded encrypted name
+	Nonce         string `json:"nonce,omitempty" binding:"omitempty,len=24"`            // Hex-encoded 12 bytes, required with encrypted_name
+}
+
+// TagResponse represents the tag response
+type TagResponse struct {
+	ID            uuid.UUID   `json:"id"`
+	VaultID       uuid.UUID   `json:"vault_id"`
+	BlindID       string      `json:"blind_id"`                 // Hex-encoded
+	EncryptedName string      `json:"encrypted_name,omitempty"` // Hex-encoded
+	Nonce         string      `json:"nonce,omitempty"`          // Hex-encoded
+	EntryIDs      []uuid.UUID `json:"entry_ids"`                // Live entries carrying the tag
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// ToResponse converts Tag to TagResponse
+func (t *Tag) ToResponse(entryIDs []uuid.UUID) TagResponse {
+	if entryIDs == nil {
+		entryIDs = []uuid.UUID{}
+	}
+	return TagResponse{
+		ID:            t.ID,
+		VaultID:       t.VaultID,
+		BlindID:       hex.EncodeToString(t.BlindID),
+		EncryptedName: hex.EncodeToString(t.EncryptedName),
+		Nonce:         hex.EncodeToString(t.Nonce),
+		EntryIDs:      entryIDs,
+		CreatedAt:     t.CreatedAt,
+	}
+}
@@ -0,0 +1,8 @@
+package models
+
+// TrashResponse represents the contents of a user's trash
+type TrashResponse struct {
+	Vaults        []VaultResponse      `json:"vaults"`
+	Entries       []VaultEntryResponse `json:"entries"`
+	RetentionDays int                  `json:"retention_days"` // Items are purged automatically after this many days
+}
@@ -0,0 +1,38 @@
+package models
+
+import "github.com/google/uuid"
+
+// Usage represents a user's current storage consumption.
+// Trashed vaults and entries count until they are purged.
+type Usage struct {
+	VaultCount      int   `db:"vault_count"`
+	EntryBytes      int64 `db:"entry_bytes"`
+	VersionBytes    int64 `db:"version_bytes"`
+	AttachmentBytes int64 `db:"attachment_bytes"`
+	Vaults          []VaultUsage
+}
+
+// VaultUsage represents the number of entries in a vault
+type VaultUsage struct {
+	VaultID    uuid.UUID `db:"vault_id" json:"vault_id"`
+	EntryCount int       `db:"entry_count" json:"entry_count"`
+	Trashed    bool      `db:"trashed" json:"trashed"`
+}
+
+// UsageCounter pairs a consumed amount with its limit
+type UsageCounter struct {
+	Used  int64 `json:"used"`
+	Limit int64 `json:"limit"`
+}
+
+// UsageResponse represents a user's consumption against the configured limits
+type UsageResponse struct {
+	Vaults             UsageCounter `json:"vaults"`
+	StorageBytes       UsageCounter `json:"storage_bytes"`    // Entries, revisions and attachments
+	EntryBytes         int64        `json:"entry_bytes"`      // Entry ciphertext
+	VersionBytes       int64        `json:"version_bytes"`    // Retained revisions
+	AttachmentBytes    int64        `json:"attachment_bytes"` // Attachments, including uploads in progress
+	MaxEntrySize       int          `json:"max_entry_size"`
+	MaxEntriesPerVault int          `json:"max_entries_per_vault"`
+	VaultEntries       []VaultUsage `json:"vault_entries"`
+}
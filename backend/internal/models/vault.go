@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/hex"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,32 +9,138 @@ import (
 
 // Vault represents a password vault container
 type Vault struct {
-	ID             uuid.UUID `json:"id" db:"id"`
-	UserID         uuid.UUID `json:"user_id" db:"user_id"`
-	Name           string    `json:"name" db:"name"`
-	EncryptionSalt []byte    `json:"encryption_salt" db:"encryption_salt"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID               uuid.UUID  `json:"id" db:"id"`
+	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
+	Name             string     `json:"name" db:"name"`
+	EncryptionSalt   []byte     `json:"encryption_salt" db:"encryption_salt"`
+	VersionRetention int        `json:"version_retention" db:"version_retention"`
+	KeyGeneration    int        `json:"key_generation" db:"key_generation"`
+	KDFAlgorithm     string     `json:"kdf_algorithm" db:"kdf_algorithm"`
+	KDFParams        KDFParams  `json:"kdf_params" db:"kdf_params"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 // VaultCreateRequest represents the request to create a new vault
 type VaultCreateRequest struct {
-	Name           string `json:"name" binding:"required,min=1,max=255"`
-	EncryptionSalt string `json:"encryption_salt" binding:"required,len=64"` // Hex-encoded 32 bytes
+	Name           string     `json:"name" binding:"required,min=1,max=255"`
+	EncryptionSalt string     `json:"encryption_salt" binding:"required,len=64"`                                // Hex-encoded 32 bytes
+	KDFAlgorithm   string     `json:"kdf_algorithm,omitempty" binding:"omitempty,oneof=pbkdf2-sha256 argon2id"` // Defaults to pbkdf2-sha256
+	KDFParams      *KDFParams `json:"kdf_params,omitempty"`                                                     // Defaults to the algorithm's defaults
 }
 
 // VaultUpdateRequest represents the request to update a vault
 type VaultUpdateRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=255"`
+	Name             string `json:"name" binding:"required,min=1,max=255"`
+	VersionRetention *int   `json:"version_retention,omitempty" binding:"omitempty,min=1,max=100"` // Revisions kept per entry
 }
 
 // VaultResponse represents the vault response
 type VaultResponse struct {
-	ID             uuid.UUID `json:"id"`
-	UserID         uuid.UUID `json:"user_id"`
-	Name           string    `json:"name"`
-	EncryptionSalt string    `json:"encryption_salt"` // Hex-encoded
-	EntriesCount   int       `json:"entries_count"`   // Number of entries in vault
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID               uuid.UUID  `json:"id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	Name             string     `json:"name"`
+	EncryptionSalt   string     `json:"encryption_salt"`   // Hex-encoded
+	EntriesCount     int        `json:"entries_count"`     // Number of entries in vault
+	VersionRetention int        `json:"version_retention"` // Revisions kept per entry
+	KeyGeneration    int        `json:"key_generation"`    // Incremented on every key rotation
+	KDFAlgorithm     string     `json:"kdf_algorithm"`     // Client key derivation function
+	KDFParams        KDFParams  `json:"kdf_params"`        // Client key derivation parameters
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"` // Set when the vault is in the trash
+}
+
+// ToResponse converts Vault to VaultResponse
+func (v *Vault) ToResponse(entriesCount int) VaultResponse {
+	return VaultResponse{
+		ID:               v.ID,
+		UserID:           v.UserID,
+		Name:             v.Name,
+		EncryptionSalt:   hex.EncodeToString(v.EncryptionSalt),
+		EntriesCount:     entriesCount,
+		VersionRetention: v.VersionRetention,
+		KeyGeneration:    v.KeyGeneration,
+		KDFAlgorithm:     v.KDFAlgorithm,
+		KDFParams:        v.KDFParams,
+		CreatedAt:        v.CreatedAt,
+		UpdatedAt:        v.UpdatedAt,
+		DeletedAt:        v.DeletedAt,
+	}
+}
+
+// VaultKeyRotationEntry is an entry re-encrypted under the rotated vault key
+type VaultKeyRotationEntry struct {
+	EntryID       uuid.UUID `json:"entry_id" binding:"required"`
+	EncryptedData string    `json:"encrypted_data" binding:"required"` // Hex-encoded encrypted data
+	Nonce         string    `json:"nonce" binding:"required,len=24"`   // Hex-encoded 12 bytes
+}
+
+// VaultKeyRotationFolder is a folder name re-encrypted under the rotated vault key
+type VaultKeyRotationFolder struct {
+	FolderID      uuid.UUID `json:"folder_id" binding:"required"`
+	EncryptedName string    `json:"encrypted_name" binding:"required,max=2048"` // Hex-encoded encrypted name
+	Nonce         string    `json:"nonce" binding:"required,len=24"`            // Hex-encoded 12 bytes
+}
+
+// VaultKeyRotationTag is a tag re-keyed under the rotated vault key
+type VaultKeyRotationTag struct {
+	TagID         uuid.UUID `json:"tag_id" binding:"required"`
+	BlindID       string    `json:"blind_id" binding:"required,len=64"`                    // Hex-encoded 32-byte keyed hash
+	EncryptedName string    `json:"encrypted_name,omitempty" binding:"omitempty,max=2048"` // Hex-encoded encrypted name
+	Nonce         string    `json:"nonce,omitempty" binding:"omitempty,len=24"`            // Hex-encoded 12 bytes, required with encrypted_name
+}
+
+// VaultKeyRotationRequest represents the request to rotate a vault key.
+// Entries must contain every entry of the vault, including trashed ones, and
+// Folders and Tags every folder and tag of the vault.
+type VaultKeyRotationRequest struct {
+	EncryptionSalt        string                   `json:"encryption_salt" binding:"required,len=64"` // Hex-encoded 32 bytes
+	ExpectedKeyGeneration *int                     `json:"expected_key_generation,omitempty"`         // Rejects the rotation if the vault was rotated meanwhile
+	Entries               []VaultKeyRotationEntry  `json:"entries" binding:"dive"`
+	Folders               []VaultKeyRotationFolder `json:"folders" binding:"dive"`
+	Tags                  []VaultKeyRotationTag    `json:"tags" binding:"dive"`
+}
+
+// VaultKDFUpgradeRequest represents the request to change a vault's KDF.
+// The vault key changes with the KDF, so every entry must be re-encrypted.
+type VaultKDFUpgradeRequest struct {
+	VaultKeyRotationRequest
+	KDFAlgorithm string    `json:"kdf_algorithm" binding:"required,oneof=pbkdf2-sha256 argon2id"`
+	KDFParams    KDFParams `json:"kdf_params"`
+}
+
+// VaultKeyRotation holds decoded key rotation data for the repository.
+// KDFParams is nil when the KDF is left unchanged.
+type VaultKeyRotation struct {
+	EncryptionSalt        []byte
+	ExpectedKeyGeneration *int
+	KDFAlgorithm          string
+	KDFParams             *KDFParams
+	Entries               []RotatedEntry
+	Folders               []RotatedFolder
+	Tags                  []RotatedTag
+}
+
+// RotatedEntry is a decoded re-encrypted entry
+type RotatedEntry struct {
+	ID            uuid.UUID
+	EncryptedData []byte
+	Nonce         []byte
+}
+
+// RotatedFolder is a decoded re-encrypted folder name
+type RotatedFolder struct {
+	ID            uuid.UUID
+	EncryptedName []byte
+	Nonce         []byte
+}
+
+// RotatedTag is a decoded re-keyed tag; EncryptedName and Nonce are nil for tags without a readable name
+type RotatedTag struct {
+	ID            uuid.UUID
+	BlindID       []byte
+	EncryptedName []byte
+	Nonce         []byte
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/hex"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,12 +9,16 @@ import (
 
 // VaultEntry represents an encrypted password entry within a vault
 type VaultEntry struct {
-	ID            uuid.UUID `json:"id" db:"id"`
-	VaultID       uuid.UUID `json:"vault_id" db:"vault_id"`
-	EncryptedData []byte    `json:"encrypted_data" db:"encrypted_data"`
-	Nonce         []byte    `json:"nonce" db:"nonce"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	ID            uuid.UUID  `json:"id" db:"id"`
+	VaultID       uuid.UUID  `json:"vault_id" db:"vault_id"`
+	EncryptedData []byte     `json:"encrypted_data" db:"encrypted_data"`
+	Nonce         []byte     `json:"nonce" db:"nonce"`
+	Version       int        `json:"version" db:"version"`
+	FolderID      *uuid.UUID `json:"folder_id,omitempty" db:"folder_id"`
+	Favorite      bool       `json:"favorite" db:"favorite"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 // VaultEntryCreateRequest represents the request to create a new vault entry
@@ -30,10 +35,53 @@ type VaultEntryUpdateRequest struct {
 
 // VaultEntryResponse represents the vault entry response
 type VaultEntryResponse struct {
-	ID            uuid.UUID `json:"id"`
-	VaultID       uuid.UUID `json:"vault_id"`
-	EncryptedData string    `json:"encrypted_data"` // Hex-encoded
-	Nonce         string    `json:"nonce"`          // Hex-encoded
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            uuid.UUID  `json:"id"`
+	VaultID       uuid.UUID  `json:"vault_id"`
+	EncryptedData string     `json:"encrypted_data"` // Hex-encoded
+	Nonce         string     `json:"nonce"`          // Hex-encoded
+	Version       int        `json:"version"`        // Current revision number
+	FolderID      *uuid.UUID `json:"folder_id"`      // Null for entries at the vault root
+	Favorite      bool       `json:"favorite"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"` // Set when the entry is in the trash
+}
+
+// ToResponse converts VaultEntry to VaultEntryResponse
+func (e *VaultEntry) ToResponse() VaultEntryResponse {
+	return VaultEntryResponse{
+		ID:            e.ID,
+		VaultID:       e.VaultID,
+		EncryptedData: hex.EncodeToString(e.EncryptedData),
+		Nonce:         hex.EncodeToString(e.Nonce),
+		Version:       e.Version,
+		FolderID:      e.FolderID,
+		Favorite:      e.Favorite,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+		DeletedAt:     e.DeletedAt,
+	}
+}
+
+// EntryFolderRequest represents the request to move an entry into a folder
+type EntryFolderRequest struct {
+	FolderID *uuid.UUID `json:"folder_id"` // Null moves the entry to the vault root
+}
+
+// EntryFavoriteRequest represents the request to mark or unmark an entry as favorite
+type EntryFavoriteRequest struct {
+	Favorite *bool `json:"favorite" binding:"required"`
+}
+
+// EntryTagsRequest represents the request to replace the tags of an entry
+type EntryTagsRequest struct {
+	TagIDs []uuid.UUID `json:"tag_ids" binding:"max=100"`
+}
+
+// EntryTransferRequest represents the request to move or copy an entry into
+// another vault. The data must be re-encrypted client-side for the target vault.
+type EntryTransferRequest struct {
+	TargetVaultID uuid.UUID `json:"target_vault_id" binding:"required"`
+	EncryptedData string    `json:"encrypted_data" binding:"required"` // Hex-encoded, encrypted with the target vault's key
+	Nonce         string    `json:"nonce" binding:"required,len=24"`   // Hex-encoded 12 bytes
 }
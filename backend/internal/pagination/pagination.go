@@ -0,0 +1,212 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Sort orders
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// Limits holds the default and server-enforced maximum page size
+type Limits struct {
+	Default int
+	Max     int
+}
+
+// SQL types of cursor values
+const (
+	TypeTimestamp = "timestamptz"
+	TypeText      = "text"
+	TypeUUID      = "uuid"
+	TypeBigint    = "bigint"
+)
+
+// Column is a sort column and the SQL type of its values
+type Column struct {
+	Name string
+	Type string
+}
+
+// Sorts lists the sort columns of a list, the first being the default,
+// and the SQL type of the row ID used as tie-breaker
+type Sorts struct {
+	Columns []Column
+	IDType  string
+}
+
+// Cursor is the keyset position of the last row of a page: the sort column
+// and order, the column value and the row ID as tie-breaker
+type Cursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Params holds the parsed pagination parameters of a list request
+type Params struct {
+	Cursor *Cursor
+	Limit  int
+	Sort   string
+	Order  string
+
+	sortType string
+	idType   string
+}
+
+// Desc reports whether results are sorted in descending order
+func (p Params) Desc() bool {
+	return p.Order == OrderDesc
+}
+
+// Next encodes the cursor pointing after the row with the given sort column
+// value and ID, for the sort and order of p
+func (p Params) Next(value, id string) string {
+	return Encode(Cursor{Sort: p.Sort, Order: p.Order, Value: value, ID: id})
+}
+
+// Parse validates raw query values against the allowed sort columns.
+// Limits above the maximum are clamped; the first allowed sort is the default.
+// A cursor must have been issued for the same sort and order and hold values
+// of the sort column's and ID's types, so it cannot fail in the database.
+func Parse(cursor, limit, sort, order string, limits Limits, sorts Sorts) (Params, error) {
+	p := Params{
+		Limit:    limits.Default,
+		Sort:     sorts.Columns[0].Name,
+		Order:    OrderDesc,
+		sortType: sorts.Columns[0].Type,
+		idType:   sorts.IDType,
+	}
+
+	l, err := ParseLimit(limit, limits)
+	if err != nil {
+		return p, err
+	}
+	p.Limit = l
+
+	if sort != "" {
+		valid := false
+		for _, column := range sorts.Columns {
+			if column.Name == sort {
+				p.Sort, p.sortType = column.Name, column.Type
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			names := make([]string, len(sorts.Columns))
+			for i, column := range sorts.Columns {
+				names[i] = column.Name
+			}
+			return p, fmt.Errorf("invalid sort: must be one of %v", names)
+		}
+	}
+
+	switch order {
+	case "":
+	case OrderAsc, OrderDesc:
+		p.Order = order
+	default:
+		return p, fmt.Errorf("invalid order: must be asc or desc")
+	}
+
+	if cursor != "" {
+		c, err := Decode(cursor)
+		if err != nil || !validValue(p.sortType, c.Value) || !validValue(p.idType, c.ID) {
+			return p, fmt.Errorf("invalid cursor")
+		}
+		if c.Sort != p.Sort {
+			return p, fmt.Errorf("invalid cursor: it was issued for a different sort")
+		}
+		if c.Order != p.Order {
+			return p, fmt.Errorf("invalid cursor: it was issued for a different order")
+		}
+		p.Cursor = c
+	}
+
+	return p, nil
+}
+
+// ParseLimit parses a page size, defaulting to the default limit when empty
+// and clamping it to the maximum
+func ParseLimit(limit string, limits Limits) (int, error) {
+	if limit == "" {
+		return limits.Default, nil
+	}
+
+	l, err := strconv.Atoi(limit)
+	if err != nil || l < 1 {
+		return 0, fmt.Errorf("invalid limit")
+	}
+	return min(l, limits.Max), nil
+}
+
+// validValue reports whether v can be cast to the SQL type typ
+func validValue(typ, v string) bool {
+	switch typ {
+	case TypeTimestamp:
+		_, err := time.Parse(time.RFC3339Nano, v)
+		return err == nil
+	case TypeText:
+		return utf8.ValidString(v) && !strings.ContainsRune(v, 0)
+	case TypeUUID:
+		_, err := uuid.Parse(v)
+		return err == nil
+	case TypeBigint:
+		_, err := strconv.ParseInt(v, 10, 64)
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// Encode encodes a cursor as an opaque URL-safe string
+func Encode(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Decode decodes an opaque cursor string
+func Decode(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Keyset builds the WHERE condition and ORDER BY clause for keyset pagination.
+// column and idColumn must be trusted identifiers; the cursor values are cast
+// to the types of the sort column and ID given to Parse. Placeholders start at argPos.
+func Keyset(p Params, column, idColumn string, argPos int) (where string, orderBy string, args []interface{}) {
+	dir, cmp := "ASC", ">"
+	if p.Desc() {
+		dir, cmp = "DESC", "<"
+	}
+
+	orderBy = fmt.Sprintf("ORDER BY %s %s, %s %s", column, dir, idColumn, dir)
+
+	if p.Cursor == nil {
+		return "TRUE", orderBy, nil
+	}
+
+	where = fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d::%s)", column, idColumn, cmp, argPos, p.sortType, argPos+1, p.idType)
+	return where, orderBy, []interface{}{p.Cursor.Value, p.Cursor.ID}
+}
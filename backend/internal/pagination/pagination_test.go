@@ -0,0 +1,177 @@
+package pagination
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var testLimits = Limits{Default: 20, Max: 100}
+
+var testSorts = Sorts{
+	Columns: []Column{
+		{Name: "created_at", Type: TypeTimestamp},
+		{Name: "name", Type: TypeText},
+	},
+	IDType: TypeUUID,
+}
+
+func TestParseDefaults(t *testing.T) {
+	p, err := Parse("", "", "", "", testLimits, testSorts)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Limit != 20 || p.Sort != "created_at" || p.Order != OrderDesc || p.Cursor != nil {
+		t.Errorf("unexpected defaults %+v", p)
+	}
+
+	p, err = Parse("", "500", "name", "asc", testLimits, testSorts)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Limit != 100 || p.Sort != "name" || p.Desc() {
+		t.Errorf("unexpected params %+v", p)
+	}
+}
+
+func TestParseRejectsInvalidParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit string
+		sort  string
+		order string
+	}{
+		{"zero limit", "0", "", ""},
+		{"non-numeric limit", "ten", "", ""},
+		{"unknown sort", "", "password", ""},
+		{"unknown order", "", "", "sideways"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse("", tt.limit, tt.sort, tt.order, testLimits, testSorts); err == nil {
+				t.Error("Parse accepted invalid parameters")
+			}
+		})
+	}
+}
+
+func TestParseCursor(t *testing.T) {
+	id := uuid.New().String()
+	valid := Cursor{Sort: "created_at", Order: OrderDesc, Value: "2024-05-01T10:00:00.123456Z", ID: id}
+
+	p, err := Parse(Encode(valid), "", "", "", testLimits, testSorts)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.Cursor == nil || *p.Cursor != valid {
+		t.Errorf("cursor = %+v, want %+v", p.Cursor, valid)
+	}
+
+	tests := []struct {
+		name    string
+		cursor  string
+		sort    string
+		order   string
+		wantErr string
+	}{
+		{"not base64", "!!!", "", "", "invalid cursor"},
+		{"not json", Encode(valid)[:10], "", "", "invalid cursor"},
+		{"tampered value", Encode(Cursor{Sort: "created_at", Order: OrderDesc, Value: "'; DROP TABLE vaults; --", ID: id}), "", "", "invalid cursor"},
+		{"tampered id", Encode(Cursor{Sort: "created_at", Order: OrderDesc, Value: valid.Value, ID: "1 OR 1=1"}), "", "", "invalid cursor"},
+		{"text with nul", Encode(Cursor{Sort: "name", Order: OrderDesc, Value: "a\x00b", ID: id}), "name", "", "invalid cursor"},
+		{"sort mismatch", Encode(valid), "name", "", "different sort"},
+		{"order mismatch", Encode(valid), "", OrderAsc, "different order"},
+		{"order missing", Encode(Cursor{Sort: "created_at", Value: valid.Value, ID: id}), "", "", "different order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.cursor, "", tt.sort, tt.order, testLimits, testSorts)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNextRoundTrips(t *testing.T) {
+	p, err := Parse("", "", "name", OrderAsc, testLimits, testSorts)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	id := uuid.New().String()
+	next, err := Parse(p.Next("Personal", id), "", "name", OrderAsc, testLimits, testSorts)
+	if err != nil {
+		t.Fatalf("Parse of the next cursor: %v", err)
+	}
+	want := Cursor{Sort: "name", Order: OrderAsc, Value: "Personal", ID: id}
+	if *next.Cursor != want {
+		t.Errorf("cursor = %+v, want %+v", *next.Cursor, want)
+	}
+
+	if _, err := Parse(p.Next("Personal", id), "", "name", OrderDesc, testLimits, testSorts); err == nil {
+		t.Error("a cursor issued for ascending order was accepted for descending order")
+	}
+}
+
+// TestKeyset checks that pages are ordered and continued on the sort column
+// and the ID, so rows sharing the cursor's sort value are neither skipped nor
+// repeated across pages
+func TestKeyset(t *testing.T) {
+	id := uuid.New().String()
+	value := "2024-05-01T10:00:00Z"
+
+	tests := []struct {
+		name        string
+		order       string
+		cursor      bool
+		wantWhere   string
+		wantOrderBy string
+	}{
+		{"first page desc", OrderDesc, false, "TRUE", "ORDER BY v.created_at DESC, v.id DESC"},
+		{"first page asc", OrderAsc, false, "TRUE", "ORDER BY v.created_at ASC, v.id ASC"},
+		{
+			"next page desc", OrderDesc, true,
+			"(v.created_at, v.id) < ($3::timestamptz, $4::uuid)",
+			"ORDER BY v.created_at DESC, v.id DESC",
+		},
+		{
+			"next page asc", OrderAsc, true,
+			"(v.created_at, v.id) > ($3::timestamptz, $4::uuid)",
+			"ORDER BY v.created_at ASC, v.id ASC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cursor string
+			if tt.cursor {
+				cursor = Encode(Cursor{Sort: "created_at", Order: tt.order, Value: value, ID: id})
+			}
+			p, err := Parse(cursor, "", "", tt.order, testLimits, testSorts)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			where, orderBy, args := Keyset(p, "v.created_at", "v.id", 3)
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if orderBy != tt.wantOrderBy {
+				t.Errorf("orderBy = %q, want %q", orderBy, tt.wantOrderBy)
+			}
+			if !tt.cursor {
+				if args != nil {
+					t.Errorf("args = %v, want none", args)
+				}
+				return
+			}
+			if len(args) != 2 || args[0] != value || args[1] != id {
+				t.Errorf("args = %v, want [%s %s]", args, value, id)
+			}
+		})
+	}
+}
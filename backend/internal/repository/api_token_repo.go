@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// APITokenRepository handles API token persistence
+type APITokenRepository struct {
+	db *sqlx.DB
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(db *sqlx.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+// Create stores a new API token by its hash
+func (r *APITokenRepository) Create(ctx context.Context, userID uuid.UUID, name string, tokenHash []byte, scopes []string, expiresAt *time.Time) (*models.APIToken, error) {
+	token := &models.APIToken{}
+
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, name, token_hash, scopes, created_at, expires_at, last_used_at, revoked_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, userID, name, tokenHash, pq.StringArray(scopes), expiresAt).StructScan(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetActiveByHash retrieves an unrevoked, unexpired API token by its hash
+func (r *APITokenRepository) GetActiveByHash(ctx context.Context, tokenHash []byte) (*models.APIToken, error) {
+	token := &models.APIToken{}
+
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, created_at, expires_at, last_used_at, revoked_at
+		FROM api_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	err := r.db.GetContext(ctx, token, query, tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("API token not found")
+		}
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetByUserID retrieves all API tokens of a user, including revoked ones
+func (r *APITokenRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIToken, error) {
+	tokens := []*models.APIToken{}
+
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, created_at, expires_at, last_used_at, revoked_at
+		FROM api_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &tokens, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes an API token of a user
+func (r *APITokenRepository) Revoke(ctx context.Context, id, userID uuid.UUID) error {
+	query := `UPDATE api_tokens SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("API token not found")
+	}
+
+	return nil
+}
+
+// UpdateLastUsed records that a token was used
+func (r *APITokenRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to update API token last used: %w", err)
+	}
+
+	return nil
+}
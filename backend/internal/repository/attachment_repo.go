@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// AttachmentRepository handles attachment metadata persistence
+type AttachmentRepository struct {
+	db    *sqlx.DB
+	quota Quota
+}
+
+// NewAttachmentRepository creates a new attachment repository enforcing the given quota
+func NewAttachmentRepository(db *sqlx.DB, quota Quota) *AttachmentRepository {
+	return &AttachmentRepository{db: db, quota: quota}
+}
+
+// Reserve records a pending upload after checking it fits into the user's storage quota.
+// Pending uploads count against the quota so concurrent uploads cannot overshoot it.
+func (r *AttachmentRepository) Reserve(ctx context.Context, userID, entryID uuid.UUID, encryptedName, nonce []byte, size int64) (*models.Attachment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := r.quota.reserveStorage(ctx, tx, userID, size); err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	attachment := &models.Attachment{}
+
+	query := `
+		INSERT INTO attachments (id, entry_id, user_id, storage_key, encrypted_name, nonce, size)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, entry_id, user_id, storage_key, encrypted_name, nonce, size, created_at, completed_at
+	`
+
+	err = tx.QueryRowxContext(ctx, query, id, entryID, userID, id.String(), encryptedName, nonce, size).StructScan(attachment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// MarkCompleted marks an upload as stored
+func (r *AttachmentRepository) MarkCompleted(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE attachments SET completed_at = NOW() WHERE id = $1 AND completed_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete attachment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("attachment not found")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a completed attachment that still belongs to an entry
+func (r *AttachmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Attachment, error) {
+	attachment := &models.Attachment{}
+
+	query := `
+		SELECT id, entry_id, user_id, storage_key, encrypted_name, nonce, size, created_at, completed_at
+		FROM attachments
+		WHERE id = $1 AND entry_id IS NOT NULL AND completed_at IS NOT NULL
+	`
+
+	err := r.db.GetContext(ctx, attachment, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("attachment not found")
+		}
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+// GetByEntryID retrieves all completed attachments of an entry
+func (r *AttachmentRepository) GetByEntryID(ctx context.Context, entryID uuid.UUID) ([]*models.Attachment, error) {
+	attachments := []*models.Attachment{}
+
+	query := `
+		SELECT id, entry_id, user_id, storage_key, encrypted_name, nonce, size, created_at, completed_at
+		FROM attachments
+		WHERE entry_id = $1 AND completed_at IS NOT NULL
+		ORDER BY created_at
+	`
+
+	err := r.db.SelectContext(ctx, &attachments, query, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// Detach unlinks an attachment from its entry so it is garbage collected
+func (r *AttachmentRepository) Detach(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE attachments SET entry_id = NULL WHERE id = $1 AND entry_id IS NOT NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to detach attachment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("attachment not found")
+	}
+
+	return nil
+}
+
+// Delete removes attachment metadata; the blob must be deleted first
+func (r *AttachmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM attachments WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	return nil
+}
+
+// GetOrphaned retrieves attachments whose entry was purged or deleted and
+// uploads that never completed before staleBefore
+func (r *AttachmentRepository) GetOrphaned(ctx context.Context, staleBefore time.Time, limit int) ([]*models.Attachment, error) {
+	attachments := []*models.Attachment{}
+
+	query := `
+		SELECT id, entry_id, user_id, storage_key, encrypted_name, nonce, size, created_at, completed_at
+		FROM attachments
+		WHERE entry_id IS NULL OR (completed_at IS NULL AND created_at < $1)
+		ORDER BY created_at
+		LIMIT $2
+	`
+
+	err := r.db.SelectContext(ctx, &attachments, query, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orphaned attachments: %w", err)
+	}
+
+	return attachments, nil
+}
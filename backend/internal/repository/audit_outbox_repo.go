@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// AuditOutboxRepository handles pending deliveries of audit logs to external sinks.
+// Audit logs are enqueued for every registered sink by a database trigger.
+type AuditOutboxRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditOutboxRepository creates a new audit outbox repository
+func NewAuditOutboxRepository(db *sqlx.DB) *AuditOutboxRepository {
+	return &AuditOutboxRepository{db: db}
+}
+
+// SetSinks registers exactly the given sinks. New sinks receive audit logs
+// written from now on; pending deliveries of removed sinks are dropped.
+func (r *AuditOutboxRepository) SetSinks(ctx context.Context, names []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM audit_sinks WHERE NOT (name = ANY($1))`, pq.Array(names)); err != nil {
+		return fmt.Errorf("failed to remove audit sinks: %w", err)
+	}
+
+	for _, name := range names {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO audit_sinks (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, name); err != nil {
+			return fmt.Errorf("failed to register audit sink: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit sinks: %w", err)
+	}
+
+	return nil
+}
+
+// Claim returns up to limit due deliveries of a sink in log order and leases
+// them for the given duration, so concurrent dispatchers skip them. Items that
+// are neither deleted nor failed within the lease become due again.
+func (r *AuditOutboxRepository) Claim(ctx context.Context, sink string, limit int, lease time.Duration) ([]*models.AuditOutboxItem, error) {
+	items := []*models.AuditOutboxItem{}
+
+	query := `
+		WITH due AS (
+			SELECT id FROM audit_outbox
+			WHERE sink = $1 AND next_attempt_at <= NOW()
+			ORDER BY log_id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE audit_outbox o
+			SET next_attempt_at = NOW() + make_interval(secs => $3)
+			FROM due
+			WHERE o.id = due.id
+			RETURNING o.id, o.log_id, o.attempts
+		)
+		SELECT c.id AS outbox_id, c.attempts, l.id, l.user_id, l.action, COALESCE(host(l.ip_address), '') AS ip_address,
+		       COALESCE(l.user_agent, '') AS user_agent, l.details, l.timestamp
+		FROM claimed c
+		JOIN audit_logs l ON l.id = c.log_id
+		ORDER BY l.id
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, sink, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim audit outbox: %w", err)
+	}
+
+	return items, nil
+}
+
+// Delete removes delivered items from the outbox
+func (r *AuditOutboxRepository) Delete(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM audit_outbox WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to delete audit outbox items: %w", err)
+	}
+
+	return nil
+}
+
+// Fail records a failed delivery of ids[0] and postpones it together with the
+// remaining claimed items until retryAt, keeping them in log order
+func (r *AuditOutboxRepository) Fail(ctx context.Context, ids []int64, retryAt time.Time, lastError string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE audit_outbox
+		SET attempts = attempts + CASE WHEN id = $1 THEN 1 ELSE 0 END,
+		    last_error = CASE WHEN id = $1 THEN $2 ELSE last_error END,
+		    next_attempt_at = $3
+		WHERE id = ANY($4)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, ids[0], lastError, retryAt, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to postpone audit outbox items: %w", err)
+	}
+
+	return nil
+}
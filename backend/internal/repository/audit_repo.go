@@ -2,12 +2,21 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/SecurityByDesign/pwmanager/internal/audit"
 	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // AuditRepository handles audit log data persistence
@@ -20,44 +29,443 @@ func NewAuditRepository(db *sqlx.DB) *AuditRepository {
 	return &AuditRepository{db: db}
 }
 
-// Create creates a new audit log entry
-func (r *AuditRepository) Create(ctx context.Context, userID *uuid.UUID, action models.AuditAction, ipAddress, userAgent string, details map[string]interface{}) error {
-	var detailsJSON []byte
-	var err error
+// auditChainLockKey is the advisory lock serialising appends to the audit hash chain
+const auditChainLockKey = 0x61756469740001
 
-	if details != nil {
-		detailsJSON, err = json.Marshal(details)
+// Create appends a new audit log entry to the hash chain.
+// An empty ipAddress is stored as NULL (e.g. for background jobs).
+// Appends are serialised by an advisory lock so IDs and chain order agree.
+func (r *AuditRepository) Create(ctx context.Context, userID *uuid.UUID, ipAddress, userAgent string, event models.AuditEvent) error {
+	log, err := audit.NewLog(userID, ipAddress, userAgent, event)
+	if err != nil {
+		return err
+	}
+
+	return r.CreateBatch(ctx, []*models.AuditLog{log})
+}
+
+// CreateBatch appends audit logs to the hash chain in order with a single
+// multi-row insert. Logs carry their own timestamps; IDs and hashes are assigned here.
+func (r *AuditRepository) CreateBatch(ctx context.Context, logs []*models.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockAuditChain(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := appendAuditLogs(ctx, tx, logs...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit log: %w", err)
+	}
+
+	return nil
+}
+
+// auditPartitionLayout is the time layout of audit_logs partition names, see
+// create_audit_log_partition; partitions cover a UTC calendar month
+const auditPartitionLayout = "audit_logs_2006_01"
+
+// ListPartitions returns the monthly partitions of audit_logs, oldest first
+func (r *AuditRepository) ListPartitions(ctx context.Context) ([]models.AuditPartition, error) {
+	var names []string
+	query := `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'audit_logs'::regclass
+	`
+	if err := r.db.SelectContext(ctx, &names, query); err != nil {
+		return nil, fmt.Errorf("failed to list audit partitions: %w", err)
+	}
+
+	partitions := make([]models.AuditPartition, 0, len(names))
+	for _, name := range names {
+		// Partitions not created by create_audit_log_partition are left alone
+		from, err := time.Parse(auditPartitionLayout, name)
 		if err != nil {
-			return fmt.Errorf("failed to marshal details: %w", err)
+			continue
 		}
+		partitions = append(partitions, models.AuditPartition{Name: name, From: from, To: from.AddDate(0, 1, 0)})
 	}
+	slices.SortFunc(partitions, func(a, b models.AuditPartition) int { return a.From.Compare(b.From) })
+
+	return partitions, nil
+}
+
+// CreatePartitions creates the missing partitions for the month containing
+// from and the following months, returning the names of those created
+func (r *AuditRepository) CreatePartitions(ctx context.Context, from time.Time, months int) ([]string, error) {
+	from = from.UTC()
+	start := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var created []string
+	for i := 0; i <= months; i++ {
+		month := start.AddDate(0, i, 0)
 
+		var ok bool
+		if err := r.db.GetContext(ctx, &ok, `SELECT create_audit_log_partition($1::date)`, month.Format(time.DateOnly)); err != nil {
+			return created, fmt.Errorf("failed to create audit partition for %s: %w", month.Format("2006-01"), err)
+		}
+		if ok {
+			created = append(created, month.Format(auditPartitionLayout))
+		}
+	}
+
+	return created, nil
+}
+
+// ExportPartition streams the audit logs of a partition to fn in ID order,
+// including the hash chain columns, for archiving
+func (r *AuditRepository) ExportPartition(ctx context.Context, partition models.AuditPartition, fn func(*models.AuditLog) error) error {
 	query := `
-		INSERT INTO audit_logs (user_id, action, ip_address, user_agent, details)
-		VALUES ($1, $2, $3, $4, $5)
+		DECLARE audit_export NO SCROLL CURSOR FOR
+		SELECT id, user_id, action, COALESCE(host(ip_address), '') AS ip_address, COALESCE(user_agent, '') AS user_agent,
+		       details, timestamp, prev_hash, hash
+		FROM audit_logs
+		WHERE timestamp >= $1 AND timestamp < $2
+		ORDER BY id
 	`
 
-	_, err = r.db.ExecContext(ctx, query, userID, action, ipAddress, userAgent, detailsJSON)
+	return r.stream(ctx, query, []interface{}{partition.From, partition.To}, fn)
+}
+
+// DropPartitions permanently drops expired partitions, oldest first, together
+// with the checkpoints pinning their logs and any deliveries of them still
+// pending in the outbox. partitions must be the oldest partitions in order.
+//
+// Only a prefix of the hash chain may be removed. A log written late, e.g.
+// replayed from the spill file, can be chained after logs of the next month;
+// the partitions up to and including its month are then kept until the
+// next one expires as well. An audit.purged event recording the hash of the
+// last dropped row is appended first so the remaining chain still verifies.
+//
+// If archived is not nil it holds the number of logs archived per partition,
+// and nothing is dropped if a partition has changed since it was archived.
+// The database only allows this for members of the pwmanager_maintenance role.
+// It returns the dropped partitions and the number of logs they held.
+func (r *AuditRepository) DropPartitions(ctx context.Context, partitions []models.AuditPartition, archived map[string]int64, userAgent string) ([]models.AuditPartition, int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The lock also keeps logs from being inserted into the partitions meanwhile
+	if err := lockAuditChain(ctx, tx); err != nil {
+		return nil, 0, err
+	}
+
+	var drop []models.AuditPartition
+	for n := len(partitions); n > 0; n-- {
+		boundary := partitions[n-1].To
+
+		var bounds struct {
+			LastDropped sql.NullInt64 `db:"last_dropped"`
+			FirstKept   sql.NullInt64 `db:"first_kept"`
+		}
+		boundsQuery := `
+			SELECT (SELECT MAX(id) FROM audit_logs WHERE timestamp < $1) AS last_dropped,
+			       (SELECT MIN(id) FROM audit_logs WHERE timestamp >= $1) AS first_kept
+		`
+		if err := tx.GetContext(ctx, &bounds, boundsQuery, boundary); err != nil {
+			return nil, 0, fmt.Errorf("failed to find purge boundary: %w", err)
+		}
+
+		if !bounds.LastDropped.Valid || !bounds.FirstKept.Valid || bounds.LastDropped.Int64 < bounds.FirstKept.Int64 {
+			drop = partitions[:n]
+			break
+		}
+	}
+	if len(drop) == 0 {
+		return nil, 0, nil
+	}
+
+	var rows int64
+	for _, partition := range drop {
+		var count int64
+		countQuery := `SELECT COUNT(*) FROM audit_logs WHERE timestamp >= $1 AND timestamp < $2`
+		if err := tx.GetContext(ctx, &count, countQuery, partition.From, partition.To); err != nil {
+			return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+		}
+		if expected, ok := archived[partition.Name]; archived != nil && (!ok || expected != count) {
+			return nil, 0, fmt.Errorf("audit partition %s has changed since it was archived", partition.Name)
+		}
+		rows += count
+	}
+
+	if rows > 0 {
+		boundary := drop[len(drop)-1].To
+
+		through := &models.AuditLog{}
+		throughQuery := `SELECT id, hash FROM audit_logs WHERE timestamp < $1 ORDER BY id DESC LIMIT 1`
+		if err := tx.GetContext(ctx, through, throughQuery, boundary); err != nil {
+			return nil, 0, fmt.Errorf("failed to find purge boundary: %w", err)
+		}
+
+		names := make([]string, 0, len(drop))
+		for _, partition := range drop {
+			names = append(names, partition.Name)
+		}
+
+		event, err := audit.NewLog(nil, "", userAgent, models.AuditPurgedEvent{
+			ThroughID:   through.ID,
+			ThroughHash: hex.EncodeToString(through.Hash),
+			Rows:        rows,
+			Cutoff:      boundary.UTC(),
+			Partitions:  names,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if err := appendAuditLogs(ctx, tx, event); err != nil {
+			return nil, 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM audit_outbox WHERE log_id <= $1`, through.ID); err != nil {
+			return nil, 0, fmt.Errorf("failed to purge audit outbox: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM audit_checkpoints WHERE log_id <= $1`, through.ID); err != nil {
+			return nil, 0, fmt.Errorf("failed to purge audit checkpoints: %w", err)
+		}
+	}
+
+	for _, partition := range drop {
+		if _, err := tx.ExecContext(ctx, `SELECT drop_audit_log_partition($1)`, partition.Name); err != nil {
+			return nil, 0, fmt.Errorf("failed to drop audit partition %s: %w", partition.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit audit purge: %w", err)
+	}
+
+	return drop, rows, nil
+}
+
+// GetPurgeAnchor returns the boundary of the latest retention purge: the ID and
+// hash of the last deleted row. It returns 0 and nil if the chain was never purged.
+func (r *AuditRepository) GetPurgeAnchor(ctx context.Context) (int64, []byte, error) {
+	var anchor struct {
+		ThroughID   int64  `db:"through_id"`
+		ThroughHash string `db:"through_hash"`
+	}
+
+	query := `
+		SELECT (details->>'through_id')::bigint AS through_id, COALESCE(details->>'through_hash', '') AS through_hash
+		FROM audit_logs
+		WHERE action = $1
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	err := r.db.GetContext(ctx, &anchor, query, models.ActionAuditPurged)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil, nil
+		}
+		return 0, nil, fmt.Errorf("failed to get audit purge anchor: %w", err)
+	}
+
+	hash, err := hex.DecodeString(anchor.ThroughHash)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid audit purge anchor: %w", err)
+	}
+	if len(hash) == 0 {
+		hash = nil
+	}
+
+	return anchor.ThroughID, hash, nil
+}
+
+// lockAuditChain takes the advisory lock serialising changes to the audit hash chain
+func lockAuditChain(ctx context.Context, tx *sqlx.Tx) error {
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLockKey); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+	return nil
+}
+
+// appendAuditLogs inserts audit logs linked to the current chain head and to
+// each other, assigning their IDs, previous hashes and hashes.
+// The caller must hold the audit chain lock.
+func appendAuditLogs(ctx context.Context, tx *sqlx.Tx, logs ...*models.AuditLog) error {
+	var prevHash []byte
+	headQuery := `SELECT hash FROM audit_logs WHERE hash IS NOT NULL ORDER BY id DESC LIMIT 1`
+	if err := tx.GetContext(ctx, &prevHash, headQuery); err != nil {
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get audit chain head: %w", err)
+		}
+		prevHash = audit.GenesisHash
+	}
+
+	var ids []int64
+	idQuery := `SELECT nextval(pg_get_serial_sequence('audit_logs', 'id')) FROM generate_series(1, $1)`
+	if err := tx.SelectContext(ctx, &ids, idQuery, len(logs)); err != nil {
+		return fmt.Errorf("failed to allocate audit log IDs: %w", err)
+	}
+	slices.Sort(ids)
+
+	values := make([]string, 0, len(logs))
+	args := make([]interface{}, 0, len(logs)*9)
+	for i, log := range logs {
+		log.ID = ids[i]
+		log.Timestamp = log.Timestamp.UTC().Truncate(time.Microsecond)
+		log.PrevHash = prevHash
+
+		hash, err := audit.Hash(log)
+		if err != nil {
+			return err
+		}
+		log.Hash = hash
+		prevHash = hash
+
+		n := len(args)
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, NULLIF($%d, '')::inet, $%d, $%d, $%d, $%d, $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9))
+		args = append(args, log.ID, log.UserID, log.Action, log.IPAddress, log.UserAgent,
+			nullableJSON(log.Details), log.Timestamp, log.PrevHash, log.Hash)
+	}
+
+	query := `
+		INSERT INTO audit_logs (id, user_id, action, ip_address, user_agent, details, timestamp, prev_hash, hash)
+		VALUES ` + strings.Join(values, ", ")
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		// Data exceptions and integrity violations, including a timestamp
+		// outside every partition, fail again on every retry
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && (pqErr.Code.Class() == "22" || pqErr.Code.Class() == "23") {
+			return fmt.Errorf("failed to create audit log: %w: %w", audit.ErrRejected, err)
+		}
 		return fmt.Errorf("failed to create audit log: %w", err)
 	}
 
 	return nil
 }
 
-// GetByUserID retrieves audit logs for a user with pagination
-func (r *AuditRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.AuditLog, error) {
+// nullableJSON returns nil for empty JSON so it is stored as NULL
+func nullableJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return []byte(data)
+}
+
+// AuditSorts lists the sort columns supported by GetByUserID; the first is the default
+var AuditSorts = pagination.Sorts{
+	Columns: []pagination.Column{{Name: "timestamp", Type: pagination.TypeTimestamp}},
+	IDType:  pagination.TypeBigint,
+}
+
+// AuditFilter restricts the audit logs returned by GetByUserID and CountByUserID; zero fields are ignored
+type AuditFilter struct {
+	Actions []string   // exact actions, or prefixes ending in ".*" such as "vault.*"
+	From    *time.Time // inclusive
+	To      *time.Time // exclusive
+	VaultID *uuid.UUID // matches details.vault_id
+	EntryID *uuid.UUID // matches details.entry_id
+	IP      string     // address or CIDR network
+}
+
+// conditions returns the SQL condition for the filter, appending its values to
+// args so that placeholders continue after the caller's arguments
+func (f AuditFilter) conditions(args []interface{}) (string, []interface{}) {
+	conds := []string{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if len(f.Actions) > 0 {
+		var exact, alternatives []string
+		for _, action := range f.Actions {
+			if prefix, ok := strings.CutSuffix(action, "*"); ok {
+				alternatives = append(alternatives, "action LIKE "+arg(likeEscaper.Replace(prefix)+"%"))
+			} else {
+				exact = append(exact, action)
+			}
+		}
+		if len(exact) > 0 {
+			alternatives = append(alternatives, "action = ANY("+arg(pq.Array(exact))+")")
+		}
+		conds = append(conds, "("+strings.Join(alternatives, " OR ")+")")
+	}
+	if f.From != nil {
+		conds = append(conds, "timestamp >= "+arg(*f.From))
+	}
+	if f.To != nil {
+		conds = append(conds, "timestamp < "+arg(*f.To))
+	}
+	if f.VaultID != nil {
+		conds = append(conds, "details->>'vault_id' = "+arg(f.VaultID.String()))
+	}
+	if f.EntryID != nil {
+		conds = append(conds, "details->>'entry_id' = "+arg(f.EntryID.String()))
+	}
+	if f.IP != "" {
+		conds = append(conds, "ip_address <<= "+arg(f.IP)+"::inet")
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// likeEscaper escapes LIKE wildcards in literal prefixes
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// GetByUserID retrieves a page of a user's audit logs matching the filter.
+// It returns up to page.Limit+1 rows so callers can detect a further page.
+func (r *AuditRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filter AuditFilter, page pagination.Params) ([]*models.AuditLog, error) {
+	logs := []*models.AuditLog{}
+
+	filterWhere, args := filter.conditions([]interface{}{userID})
+	where, orderBy, keysetArgs := pagination.Keyset(page, page.Sort, "id", len(args)+1)
+	args = append(args, keysetArgs...)
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, action, COALESCE(host(ip_address), '') AS ip_address, user_agent, details, timestamp
+		FROM audit_logs
+		WHERE user_id = $1 AND %s AND %s
+		%s
+		LIMIT %d
+	`, filterWhere, where, orderBy, page.Limit+1)
+
+	err := r.db.SelectContext(ctx, &logs, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// GetByUserIDAfter retrieves up to limit of a user's audit logs with IDs after afterID, in ID order
+func (r *AuditRepository) GetByUserIDAfter(ctx context.Context, userID uuid.UUID, afterID int64, limit int) ([]*models.AuditLog, error) {
 	logs := []*models.AuditLog{}
 
 	query := `
-		SELECT id, user_id, action, ip_address, user_agent, details, timestamp
+		SELECT id, user_id, action, COALESCE(host(ip_address), '') AS ip_address, user_agent, details, timestamp
 		FROM audit_logs
-		WHERE user_id = $1
-		ORDER BY timestamp DESC
-		LIMIT $2 OFFSET $3
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
 	`
 
-	err := r.db.SelectContext(ctx, &logs, query, userID, limit, offset)
+	err := r.db.SelectContext(ctx, &logs, query, userID, afterID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get audit logs: %w", err)
 	}
@@ -70,7 +478,7 @@ func (r *AuditRepository) GetRecent(ctx context.Context, limit, offset int) ([]*
 	logs := []*models.AuditLog{}
 
 	query := `
-		SELECT id, user_id, action, ip_address, user_agent, details, timestamp
+		SELECT id, user_id, action, COALESCE(host(ip_address), '') AS ip_address, user_agent, details, timestamp
 		FROM audit_logs
 		ORDER BY timestamp DESC
 		LIMIT $1 OFFSET $2
@@ -84,12 +492,13 @@ func (r *AuditRepository) GetRecent(ctx context.Context, limit, offset int) ([]*
 	return logs, nil
 }
 
-// CountByUserID counts audit logs for a user
-func (r *AuditRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+// CountByUserID counts a user's audit logs matching the filter
+func (r *AuditRepository) CountByUserID(ctx context.Context, userID uuid.UUID, filter AuditFilter) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM audit_logs WHERE user_id = $1`
+	where, args := filter.conditions([]interface{}{userID})
+	query := `SELECT COUNT(*) FROM audit_logs WHERE user_id = $1 AND ` + where
 
-	err := r.db.GetContext(ctx, &count, query, userID)
+	err := r.db.GetContext(ctx, &count, query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count audit logs: %w", err)
 	}
@@ -97,12 +506,78 @@ func (r *AuditRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (
 	return count, nil
 }
 
+// exportFetchSize is the number of rows fetched from the export cursor at a time
+const exportFetchSize = 500
+
+// Export streams the audit logs matching the filter to fn in chronological order,
+// restricted to a user's logs unless userID is nil. Rows are fetched in batches
+// from a server-side cursor inside a read-only transaction, so the export sees
+// a consistent snapshot without holding it in memory.
+func (r *AuditRepository) Export(ctx context.Context, userID *uuid.UUID, filter AuditFilter, fn func(*models.AuditLog) error) error {
+	var args []interface{}
+	scope := "TRUE"
+	if userID != nil {
+		args = append(args, *userID)
+		scope = "user_id = $1"
+	}
+	where, args := filter.conditions(args)
+
+	query := fmt.Sprintf(`
+		DECLARE audit_export NO SCROLL CURSOR FOR
+		SELECT id, user_id, action, COALESCE(host(ip_address), '') AS ip_address, COALESCE(user_agent, '') AS user_agent,
+		       details, timestamp
+		FROM audit_logs
+		WHERE %s AND %s
+		ORDER BY timestamp, id
+	`, scope, where)
+
+	return r.stream(ctx, query, args, fn)
+}
+
+// stream declares the audit_export cursor with query inside a read-only
+// transaction and passes its rows to fn, fetching them in batches
+func (r *AuditRepository) stream(ctx context.Context, query string, args []interface{}, fn func(*models.AuditLog) error) error {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to open audit export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf(`FETCH FORWARD %d FROM audit_export`, exportFetchSize)
+	for {
+		logs := []*models.AuditLog{}
+		if err := tx.SelectContext(ctx, &logs, fetch); err != nil {
+			return fmt.Errorf("failed to fetch audit logs: %w", err)
+		}
+
+		for _, log := range logs {
+			if err := fn(log); err != nil {
+				return err
+			}
+		}
+
+		if len(logs) < exportFetchSize {
+			break
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit export: %w", err)
+	}
+
+	return nil
+}
+
 // GetByID retrieves a single audit log by ID
 func (r *AuditRepository) GetByID(ctx context.Context, logID string) (*models.AuditLog, error) {
 	log := &models.AuditLog{}
 
 	query := `
-		SELECT id, user_id, action, ip_address, user_agent, details, timestamp
+		SELECT id, user_id, action, COALESCE(host(ip_address), '') AS ip_address, user_agent, details, timestamp
 		FROM audit_logs
 		WHERE id = $1
 	`
@@ -117,3 +592,99 @@ func (r *AuditRepository) GetByID(ctx context.Context, logID string) (*models.Au
 
 	return log, nil
 }
+
+// WalkChain calls fn for every audit log in ID order, including the hash chain
+// columns, until fn returns an error. Rows are streamed from a single query.
+func (r *AuditRepository) WalkChain(ctx context.Context, fn func(*models.AuditLog) error) error {
+	query := `
+		SELECT id, user_id, action, COALESCE(host(ip_address), '') AS ip_address, COALESCE(user_agent, '') AS user_agent,
+		       details, timestamp, prev_hash, hash
+		FROM audit_logs
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryxContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to query audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log := &models.AuditLog{}
+		if err := rows.StructScan(log); err != nil {
+			return fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read audit logs: %w", err)
+	}
+
+	return nil
+}
+
+// GetChainHead retrieves the ID and hash of the latest chained audit log, or nil if there is none
+func (r *AuditRepository) GetChainHead(ctx context.Context) (*models.AuditLog, error) {
+	head := &models.AuditLog{}
+
+	query := `SELECT id, hash FROM audit_logs WHERE hash IS NOT NULL ORDER BY id DESC LIMIT 1`
+
+	err := r.db.GetContext(ctx, head, query)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+
+	return head, nil
+}
+
+// CreateCheckpoint stores a signed checkpoint
+func (r *AuditRepository) CreateCheckpoint(ctx context.Context, checkpoint *models.AuditCheckpoint) error {
+	query := `
+		INSERT INTO audit_checkpoints (log_id, hash, signature, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, checkpoint.LogID, checkpoint.Hash, checkpoint.Signature, checkpoint.CreatedAt).Scan(&checkpoint.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create audit checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestCheckpoint retrieves the most recent checkpoint, or nil if there is none
+func (r *AuditRepository) GetLatestCheckpoint(ctx context.Context) (*models.AuditCheckpoint, error) {
+	checkpoint := &models.AuditCheckpoint{}
+
+	query := `SELECT id, log_id, hash, signature, created_at FROM audit_checkpoints ORDER BY id DESC LIMIT 1`
+
+	err := r.db.GetContext(ctx, checkpoint, query)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get audit checkpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// GetCheckpoints retrieves all checkpoints ordered by ID
+func (r *AuditRepository) GetCheckpoints(ctx context.Context) ([]*models.AuditCheckpoint, error) {
+	checkpoints := []*models.AuditCheckpoint{}
+
+	query := `SELECT id, log_id, hash, signature, created_at FROM audit_checkpoints ORDER BY id`
+
+	if err := r.db.SelectContext(ctx, &checkpoints, query); err != nil {
+		return nil, fmt.Errorf("failed to get audit checkpoints: %w", err)
+	}
+
+	return checkpoints, nil
+}
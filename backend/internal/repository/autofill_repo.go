@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// AutofillRepository handles autofill domain hash persistence and lookup
+type AutofillRepository struct {
+	db *sqlx.DB
+}
+
+// NewAutofillRepository creates a new autofill repository
+func NewAutofillRepository(db *sqlx.DB) *AutofillRepository {
+	return &AutofillRepository{db: db}
+}
+
+// SetEntryHashes replaces the autofill domain hashes of an entry
+func (r *AutofillRepository) SetEntryHashes(ctx context.Context, entryID, vaultID uuid.UUID, domainHashes [][]byte) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entry_autofill_hashes WHERE entry_id = $1`, entryID); err != nil {
+		return fmt.Errorf("failed to clear autofill hashes: %w", err)
+	}
+
+	if len(domainHashes) > 0 {
+		query := `
+			INSERT INTO entry_autofill_hashes (entry_id, vault_id, domain_hash)
+			SELECT $1, $2, h FROM unnest($3::bytea[]) AS h
+			ON CONFLICT DO NOTHING
+		`
+
+		if _, err := tx.ExecContext(ctx, query, entryID, vaultID, pq.ByteaArray(domainHashes)); err != nil {
+			return fmt.Errorf("failed to set autofill hashes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit autofill hashes: %w", err)
+	}
+
+	return nil
+}
+
+// Lookup returns the live entries in the user's live vaults that carry any of the given hashes
+func (r *AutofillRepository) Lookup(ctx context.Context, userID uuid.UUID, domainHashes [][]byte) ([]*models.AutofillMatch, error) {
+	matches := []*models.AutofillMatch{}
+
+	query := `
+		SELECT DISTINCT h.entry_id, h.vault_id
+		FROM entry_autofill_hashes h
+		JOIN vault_entries e ON e.id = h.entry_id
+		JOIN vaults v ON v.id = h.vault_id
+		WHERE h.domain_hash = ANY($1::bytea[]) AND v.user_id = $2
+		  AND e.deleted_at IS NULL AND v.deleted_at IS NULL
+		ORDER BY h.vault_id, h.entry_id
+	`
+
+	err := r.db.SelectContext(ctx, &matches, query, pq.ByteaArray(domainHashes), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up autofill entries: %w", err)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Emergency access errors
+var (
+	// ErrEmergencyAccessExists is returned when the grantor already has a grant for the email address
+	ErrEmergencyAccessExists = errors.New("emergency access already granted to this email address")
+	// ErrEmergencyAccessSelf is returned when the grantor invites their own email address
+	ErrEmergencyAccessSelf = errors.New("cannot grant emergency access to yourself")
+	// ErrEmergencyAccessState is returned when a transition is not allowed from the current state
+	ErrEmergencyAccessState = errors.New("emergency access is not in the required state")
+)
+
+// emergencyAccessSelect selects grants together with both parties' email
+// addresses; the grantee's is the invited one
+const emergencyAccessSelect = `
+	SELECT ea.id, ea.grantor_id, ea.grantee_id, gr.email AS grantor_email, ea.grantee_email,
+	       ea.status, ea.wait_days, ea.wrapped_key, ea.recovery_requested_at, ea.recovery_approved_at,
+	       ea.created_at, ea.updated_at
+	FROM emergency_access ea
+	JOIN users gr ON gr.id = ea.grantor_id
+`
+
+// EmergencyAccessRepository handles emergency access persistence
+type EmergencyAccessRepository struct {
+	db *sqlx.DB
+}
+
+// NewEmergencyAccessRepository creates a new emergency access repository
+func NewEmergencyAccessRepository(db *sqlx.DB) *EmergencyAccessRepository {
+	return &EmergencyAccessRepository{db: db}
+}
+
+// Create invites a grantee by email address. The grant is linked to the user
+// registered with the address, or to the user registering with it later.
+func (r *EmergencyAccessRepository) Create(ctx context.Context, grantorID uuid.UUID, granteeEmail string, waitDays int, wrappedKey []byte) (*models.EmergencyAccess, error) {
+	var id uuid.UUID
+
+	query := `
+		INSERT INTO emergency_access (grantor_id, grantee_id, grantee_email, wait_days, wrapped_key)
+		VALUES ($1, (SELECT id FROM users WHERE email = $2), $2, $3, $4)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, grantorID, granteeEmail, waitDays, wrappedKey).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrEmergencyAccessExists
+		}
+		if errors.As(err, &pqErr) && pqErr.Constraint == "chk_emergency_access_not_self" {
+			return nil, ErrEmergencyAccessSelf
+		}
+		return nil, fmt.Errorf("failed to create emergency access: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// GetByID retrieves a grant by ID
+func (r *EmergencyAccessRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.EmergencyAccess, error) {
+	grant := &models.EmergencyAccess{}
+
+	err := r.db.GetContext(ctx, grant, emergencyAccessSelect+` WHERE ea.id = $1`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("emergency access not found")
+		}
+		return nil, fmt.Errorf("failed to get emergency access: %w", err)
+	}
+
+	return grant, nil
+}
+
+// GetByGrantorID retrieves the grants a user has given
+func (r *EmergencyAccessRepository) GetByGrantorID(ctx context.Context, grantorID uuid.UUID) ([]*models.EmergencyAccess, error) {
+	grants := []*models.EmergencyAccess{}
+
+	err := r.db.SelectContext(ctx, &grants, emergencyAccessSelect+` WHERE ea.grantor_id = $1 ORDER BY ea.created_at`, grantorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emergency access grants: %w", err)
+	}
+
+	return grants, nil
+}
+
+// GetByGranteeID retrieves the grants a user has received
+func (r *EmergencyAccessRepository) GetByGranteeID(ctx context.Context, granteeID uuid.UUID) ([]*models.EmergencyAccess, error) {
+	grants := []*models.EmergencyAccess{}
+
+	err := r.db.SelectContext(ctx, &grants, emergencyAccessSelect+` WHERE ea.grantee_id = $1 ORDER BY ea.created_at`, granteeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emergency access grants: %w", err)
+	}
+
+	return grants, nil
+}
+
+// Update changes the wait period and/or wrapped key of a grant
+func (r *EmergencyAccessRepository) Update(ctx context.Context, id uuid.UUID, waitDays *int, wrappedKey []byte) (*models.EmergencyAccess, error) {
+	query := `
+		UPDATE emergency_access
+		SET wait_days = COALESCE($1, wait_days), wrapped_key = COALESCE($2, wrapped_key), updated_at = NOW()
+		WHERE id = $3
+		RETURNING id
+	`
+	return r.transition(ctx, query, waitDays, wrappedKey, id)
+}
+
+// Accept moves an invited grant to accepted
+func (r *EmergencyAccessRepository) Accept(ctx context.Context, id uuid.UUID) (*models.EmergencyAccess, error) {
+	query := `
+		UPDATE emergency_access
+		SET status = 'accepted', updated_at = NOW()
+		WHERE id = $1 AND status = 'invited'
+		RETURNING id
+	`
+	return r.transition(ctx, query, id)
+}
+
+// RequestRecovery starts the wait period of an accepted grant
+func (r *EmergencyAccessRepository) RequestRecovery(ctx context.Context, id uuid.UUID) (*models.EmergencyAccess, error) {
+	query := `
+		UPDATE emergency_access
+		SET status = 'recovery_requested', recovery_requested_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'accepted'
+		RETURNING id
+	`
+	return r.transition(ctx, query, id)
+}
+
+// ApproveRecovery releases the key of a pending request before the wait period ends
+func (r *EmergencyAccessRepository) ApproveRecovery(ctx context.Context, id uuid.UUID) (*models.EmergencyAccess, error) {
+	query := `
+		UPDATE emergency_access
+		SET status = 'recovery_approved', recovery_approved_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'recovery_requested'
+		RETURNING id
+	`
+	return r.transition(ctx, query, id)
+}
+
+// RejectRecovery cancels a pending request or ends released access, returning the grant to accepted
+func (r *EmergencyAccessRepository) RejectRecovery(ctx context.Context, id uuid.UUID) (*models.EmergencyAccess, error) {
+	query := `
+		UPDATE emergency_access
+		SET status = 'accepted', recovery_requested_at = NULL, recovery_approved_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status IN ('recovery_requested', 'recovery_approved')
+		RETURNING id
+	`
+	return r.transition(ctx, query, id)
+}
+
+// ApproveExpiredRequests releases the key of every request whose wait period has passed
+func (r *EmergencyAccessRepository) ApproveExpiredRequests(ctx context.Context) ([]*models.EmergencyAccess, error) {
+	grants := []*models.EmergencyAccess{}
+
+	query := `
+		UPDATE emergency_access
+		SET status = 'recovery_approved', recovery_approved_at = NOW(), updated_at = NOW()
+		WHERE status = 'recovery_requested'
+		  AND recovery_requested_at + wait_days * INTERVAL '1 day' <= NOW()
+		RETURNING id, grantor_id, grantee_id, status, wait_days, wrapped_key,
+		          recovery_requested_at, recovery_approved_at, created_at, updated_at
+	`
+
+	err := r.db.SelectContext(ctx, &grants, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve expired recovery requests: %w", err)
+	}
+
+	return grants, nil
+}
+
+// Delete deletes a grant
+func (r *EmergencyAccessRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM emergency_access WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete emergency access: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("emergency access not found")
+	}
+
+	return nil
+}
+
+// transition runs a conditional update returning the grant ID and reloads the grant
+func (r *EmergencyAccessRepository) transition(ctx context.Context, query string, args ...interface{}) (*models.EmergencyAccess, error) {
+	var id uuid.UUID
+	if err := r.db.QueryRowxContext(ctx, query, args...).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEmergencyAccessState
+		}
+		return nil, fmt.Errorf("failed to update emergency access: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
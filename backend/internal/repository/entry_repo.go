@@ -4,35 +4,41 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // EntryRepository handles vault entry data persistence
 type EntryRepository struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	quota Quota
 }
 
-// NewEntryRepository creates a new entry repository
-func NewEntryRepository(db *sqlx.DB) *EntryRepository {
-	return &EntryRepository{db: db}
+// NewEntryRepository creates a new entry repository enforcing the given quota
+func NewEntryRepository(db *sqlx.DB, quota Quota) *EntryRepository {
+	return &EntryRepository{db: db, quota: quota}
 }
 
-// Create creates a new vault entry
-func (r *EntryRepository) Create(ctx context.Context, vaultID uuid.UUID, encryptedData, nonce []byte) (*models.VaultEntry, error) {
-	entry := &models.VaultEntry{}
-
-	query := `
-		INSERT INTO vault_entries (vault_id, encrypted_data, nonce)
-		VALUES ($1, $2, $3)
-		RETURNING id, vault_id, encrypted_data, nonce, created_at, updated_at
-	`
+// Create creates a new vault entry and records it as the first revision
+func (r *EntryRepository) Create(ctx context.Context, vaultID uuid.UUID, encryptedData, nonce []byte, actorID uuid.UUID) (*models.VaultEntry, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	err := r.db.QueryRowxContext(ctx, query, vaultID, encryptedData, nonce).StructScan(entry)
+	entry, err := createEntry(ctx, tx, r.quota, vaultID, encryptedData, nonce, actorID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create entry: %w", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit entry: %w", err)
 	}
 
 	return entry, nil
@@ -43,9 +49,10 @@ func (r *EntryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Va
 	entry := &models.VaultEntry{}
 
 	query := `
-		SELECT id, vault_id, encrypted_data, nonce, created_at, updated_at
-		FROM vault_entries
-		WHERE id = $1
+		SELECT e.id, e.vault_id, e.encrypted_data, e.nonce, e.version, e.folder_id, e.favorite, e.created_at, e.updated_at
+		FROM vault_entries e
+		JOIN vaults v ON v.id = e.vault_id
+		WHERE e.id = $1 AND e.deleted_at IS NULL AND v.deleted_at IS NULL
 	`
 
 	err := r.db.GetContext(ctx, entry, query, id)
@@ -59,18 +66,52 @@ func (r *EntryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Va
 	return entry, nil
 }
 
-// GetByVaultID retrieves all entries for a vault
-func (r *EntryRepository) GetByVaultID(ctx context.Context, vaultID uuid.UUID) ([]*models.VaultEntry, error) {
+// EntrySorts lists the sort columns supported by GetByVaultID; the first is the default
+var EntrySorts = pagination.Sorts{
+	Columns: []pagination.Column{
+		{Name: "created_at", Type: pagination.TypeTimestamp},
+		{Name: "updated_at", Type: pagination.TypeTimestamp},
+	},
+	IDType: pagination.TypeUUID,
+}
+
+// EntryFilter restricts the entries returned by GetByVaultID; nil fields are ignored
+type EntryFilter struct {
+	FolderID *uuid.UUID
+	Favorite *bool
+	TagID    *uuid.UUID
+}
+
+// GetByVaultID retrieves a page of entries for a vault.
+// It returns up to page.Limit+1 rows so callers can detect a further page.
+func (r *EntryRepository) GetByVaultID(ctx context.Context, vaultID uuid.UUID, filter EntryFilter, page pagination.Params) ([]*models.VaultEntry, error) {
 	entries := []*models.VaultEntry{}
 
-	query := `
-		SELECT id, vault_id, encrypted_data, nonce, created_at, updated_at
+	where, orderBy, args := pagination.Keyset(page, page.Sort, "id", 2)
+	args = append([]interface{}{vaultID}, args...)
+
+	if filter.FolderID != nil {
+		args = append(args, *filter.FolderID)
+		where += fmt.Sprintf(" AND folder_id = $%d", len(args))
+	}
+	if filter.Favorite != nil {
+		args = append(args, *filter.Favorite)
+		where += fmt.Sprintf(" AND favorite = $%d", len(args))
+	}
+	if filter.TagID != nil {
+		args = append(args, *filter.TagID)
+		where += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM entry_tags et WHERE et.entry_id = vault_entries.id AND et.tag_id = $%d)", len(args))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, vault_id, encrypted_data, nonce, version, folder_id, favorite, created_at, updated_at
 		FROM vault_entries
-		WHERE vault_id = $1
-		ORDER BY created_at DESC
-	`
+		WHERE vault_id = $1 AND deleted_at IS NULL AND %s
+		%s
+		LIMIT %d
+	`, where, orderBy, page.Limit+1)
 
-	err := r.db.SelectContext(ctx, &entries, query, vaultID)
+	err := r.db.SelectContext(ctx, &entries, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get entries: %w", err)
 	}
@@ -78,17 +119,124 @@ func (r *EntryRepository) GetByVaultID(ctx context.Context, vaultID uuid.UUID) (
 	return entries, nil
 }
 
-// Update updates an entry's encrypted data
-func (r *EntryRepository) Update(ctx context.Context, id uuid.UUID, encryptedData, nonce []byte) error {
+// Update updates an entry's encrypted data as a new revision.
+// Revisions beyond the vault's version_retention are pruned.
+func (r *EntryRepository) Update(ctx context.Context, id uuid.UUID, encryptedData, nonce []byte, actorID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := updateEntry(ctx, tx, r.quota, id, encryptedData, nonce, actorID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit entry: %w", err)
+	}
+
+	return nil
+}
+
+// Delete moves an entry to the trash
+func (r *EntryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return deleteEntry(ctx, r.db, id)
+}
+
+// SetFolder moves an entry into a folder, or to the vault root when folderID is nil.
+// The caller must ensure the folder belongs to the entry's vault.
+func (r *EntryRepository) SetFolder(ctx context.Context, id uuid.UUID, folderID *uuid.UUID) error {
+	query := `UPDATE vault_entries SET folder_id = $1 WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, folderID, id)
+	if err != nil {
+		return fmt.Errorf("failed to set entry folder: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("entry not found")
+	}
+
+	return nil
+}
+
+// SetFavorite marks or unmarks an entry as favorite
+func (r *EntryRepository) SetFavorite(ctx context.Context, id uuid.UUID, favorite bool) error {
+	query := `UPDATE vault_entries SET favorite = $1 WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, favorite, id)
+	if err != nil {
+		return fmt.Errorf("failed to set entry favorite: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("entry not found")
+	}
+
+	return nil
+}
+
+// GetTrashedByID retrieves an entry from the trash by ID.
+// Entries inside a trashed vault are only reachable through the vault.
+func (r *EntryRepository) GetTrashedByID(ctx context.Context, id uuid.UUID) (*models.VaultEntry, error) {
+	entry := &models.VaultEntry{}
+
 	query := `
-		UPDATE vault_entries
-		SET encrypted_data = $1, nonce = $2, updated_at = NOW()
-		WHERE id = $3
+		SELECT e.id, e.vault_id, e.encrypted_data, e.nonce, e.version, e.folder_id, e.favorite, e.created_at, e.updated_at, e.deleted_at
+		FROM vault_entries e
+		JOIN vaults v ON v.id = e.vault_id
+		WHERE e.id = $1 AND e.deleted_at IS NOT NULL AND v.deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, encryptedData, nonce, id)
+	err := r.db.GetContext(ctx, entry, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("entry not found")
+		}
+		return nil, fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// GetTrashedByUserID retrieves all trashed entries in a user's vaults
+func (r *EntryRepository) GetTrashedByUserID(ctx context.Context, userID uuid.UUID) ([]*models.VaultEntry, error) {
+	entries := []*models.VaultEntry{}
+
+	query := `
+		SELECT e.id, e.vault_id, e.encrypted_data, e.nonce, e.version, e.folder_id, e.favorite, e.created_at, e.updated_at, e.deleted_at
+		FROM vault_entries e
+		JOIN vaults v ON v.id = e.vault_id
+		WHERE v.user_id = $1 AND e.deleted_at IS NOT NULL AND v.deleted_at IS NULL
+		ORDER BY e.deleted_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &entries, query, userID)
 	if err != nil {
-		return fmt.Errorf("failed to update entry: %w", err)
+		return nil, fmt.Errorf("failed to get trashed entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Restore moves an entry out of the trash
+func (r *EntryRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE vault_entries SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore entry: %w", err)
 	}
 
 	rows, err := result.RowsAffected()
@@ -103,13 +251,13 @@ func (r *EntryRepository) Update(ctx context.Context, id uuid.UUID, encryptedDat
 	return nil
 }
 
-// Delete deletes an entry
-func (r *EntryRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM vault_entries WHERE id = $1`
+// Purge permanently deletes a trashed entry
+func (r *EntryRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM vault_entries WHERE id = $1 AND deleted_at IS NOT NULL`
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete entry: %w", err)
+		return fmt.Errorf("failed to purge entry: %w", err)
 	}
 
 	rows, err := result.RowsAffected()
@@ -124,10 +272,27 @@ func (r *EntryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// PurgeDeletedBefore permanently deletes all entries trashed before the cutoff
+func (r *EntryRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `DELETE FROM vault_entries WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge entries: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
 // CountByVaultID counts entries in a vault
 func (r *EntryRepository) CountByVaultID(ctx context.Context, vaultID uuid.UUID) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM vault_entries WHERE vault_id = $1`
+	query := `SELECT COUNT(*) FROM vault_entries WHERE vault_id = $1 AND deleted_at IS NULL`
 
 	err := r.db.GetContext(ctx, &count, query, vaultID)
 	if err != nil {
@@ -136,3 +301,319 @@ func (r *EntryRepository) CountByVaultID(ctx context.Context, vaultID uuid.UUID)
 
 	return count, nil
 }
+
+// BeginTx starts a transaction for a series of entry changes that must be
+// applied atomically. Callers must Commit or Rollback the returned EntryTx.
+func (r *EntryRepository) BeginTx(ctx context.Context) (*EntryTx, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	return &EntryTx{tx: tx, quota: r.quota}, nil
+}
+
+// EntryTx applies entry changes within a single database transaction
+type EntryTx struct {
+	tx    *sqlx.Tx
+	quota Quota
+}
+
+// Commit commits the transaction
+func (t *EntryTx) Commit() error {
+	if err := t.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit entries: %w", err)
+	}
+	return nil
+}
+
+// Rollback aborts the transaction; it is a no-op after Commit
+func (t *EntryTx) Rollback() error {
+	err := t.tx.Rollback()
+	if err != nil && err != sql.ErrTxDone {
+		return fmt.Errorf("failed to roll back entries: %w", err)
+	}
+	return nil
+}
+
+// LockOwnedVaults share-locks the user's usage counters and the given vaults
+// until the transaction ends and reports whether all vaults are live and owned
+// by the user. The share lock keeps the vaults from being trashed or key-rotated while entries move.
+// Call it before locking any entry when the transaction writes several entries.
+func (t *EntryTx) LockOwnedVaults(ctx context.Context, userID uuid.UUID, vaultIDs ...uuid.UUID) (bool, error) {
+	if err := lockUsage(ctx, t.tx, userID); err != nil {
+		return false, err
+	}
+
+	ids := uniqueUUIDs(vaultIDs)
+
+	var locked []uuid.UUID
+	query := `
+		SELECT id FROM vaults
+		WHERE id = ANY($1::uuid[]) AND user_id = $2 AND deleted_at IS NULL
+		ORDER BY id
+		FOR SHARE
+	`
+
+	if err := t.tx.SelectContext(ctx, &locked, query, pq.Array(uuidStrings(ids)), userID); err != nil {
+		return false, fmt.Errorf("failed to lock vaults: %w", err)
+	}
+
+	return len(locked) == len(ids), nil
+}
+
+// GetForUpdate retrieves a live entry and locks it until the transaction ends
+func (t *EntryTx) GetForUpdate(ctx context.Context, id uuid.UUID) (*models.VaultEntry, error) {
+	entry := &models.VaultEntry{}
+
+	query := `
+		SELECT e.id, e.vault_id, e.encrypted_data, e.nonce, e.version, e.folder_id, e.favorite, e.created_at, e.updated_at
+		FROM vault_entries e
+		JOIN vaults v ON v.id = e.vault_id
+		WHERE e.id = $1 AND e.deleted_at IS NULL AND v.deleted_at IS NULL
+		FOR UPDATE OF e
+	`
+
+	err := t.tx.GetContext(ctx, entry, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("entry not found")
+		}
+		return nil, fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// GetVersion retrieves a retained revision of an entry within the transaction.
+// Lock the entry's vault first so a concurrent key rotation cannot replace the revision.
+func (t *EntryTx) GetVersion(ctx context.Context, entryID uuid.UUID, version int) (*models.EntryVersion, error) {
+	v := &models.EntryVersion{}
+
+	query := `
+		SELECT id, entry_id, version, encrypted_data, nonce, created_by, created_at
+		FROM entry_versions
+		WHERE entry_id = $1 AND version = $2
+	`
+
+	err := t.tx.GetContext(ctx, v, query, entryID, version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("entry version not found")
+		}
+		return nil, fmt.Errorf("failed to get entry version: %w", err)
+	}
+
+	return v, nil
+}
+
+// Create creates a new vault entry within the transaction
+func (t *EntryTx) Create(ctx context.Context, vaultID uuid.UUID, encryptedData, nonce []byte, actorID uuid.UUID) (*models.VaultEntry, error) {
+	return createEntry(ctx, t.tx, t.quota, vaultID, encryptedData, nonce, actorID)
+}
+
+// Update writes a new revision of an entry within the transaction
+func (t *EntryTx) Update(ctx context.Context, id uuid.UUID, encryptedData, nonce []byte, actorID uuid.UUID) (*models.VaultEntry, error) {
+	return updateEntry(ctx, t.tx, t.quota, id, encryptedData, nonce, actorID)
+}
+
+// Delete moves an entry to the trash within the transaction
+func (t *EntryTx) Delete(ctx context.Context, id uuid.UUID) error {
+	return deleteEntry(ctx, t.tx, id)
+}
+
+// Move moves an entry into another vault with data re-encrypted for that vault.
+// Earlier revisions are encrypted under the source vault's key and are dropped;
+// folder, tags, search tokens and autofill hashes belong to the source vault and are cleared.
+func (t *EntryTx) Move(ctx context.Context, id, targetVaultID uuid.UUID, encryptedData, nonce []byte, actorID uuid.UUID) (*models.VaultEntry, error) {
+	if err := t.quota.checkEntrySize(encryptedData); err != nil {
+		return nil, err
+	}
+
+	vaultID, size, err := entrySize(ctx, t.tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := versionsSize(ctx, t.tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	added := 0
+	if vaultID != targetVaultID {
+		added = 1
+	}
+	// The data and its first revision replace the current data and all revisions
+	if err := t.quota.reserveEntries(ctx, t.tx, targetVaultID, added, 2*int64(len(encryptedData))-size-versions); err != nil {
+		return nil, err
+	}
+
+	entry := &models.VaultEntry{}
+
+	query := `
+		UPDATE vault_entries
+		SET vault_id = $1, encrypted_data = $2, nonce = $3, version = version + 1,
+		    folder_id = NULL, updated_at = NOW()
+		WHERE id = $4 AND deleted_at IS NULL
+		RETURNING id, vault_id, encrypted_data, nonce, version, folder_id, favorite, created_at, updated_at
+	`
+
+	err = t.tx.QueryRowxContext(ctx, query, targetVaultID, encryptedData, nonce, id).StructScan(entry)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("entry not found")
+		}
+		return nil, fmt.Errorf("failed to move entry: %w", err)
+	}
+
+	if _, err := t.tx.ExecContext(ctx, `DELETE FROM entry_versions WHERE entry_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to drop entry versions: %w", err)
+	}
+
+	if _, err := t.tx.ExecContext(ctx, `DELETE FROM entry_tags WHERE entry_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to clear entry tags: %w", err)
+	}
+
+	if _, err := t.tx.ExecContext(ctx, `DELETE FROM entry_search_tokens WHERE entry_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to clear search tokens: %w", err)
+	}
+
+	if _, err := t.tx.ExecContext(ctx, `DELETE FROM entry_autofill_hashes WHERE entry_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to clear autofill hashes: %w", err)
+	}
+
+	if err := insertVersion(ctx, t.tx, entry, actorID); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// createEntry inserts an entry within the quota and records it as the first revision
+func createEntry(ctx context.Context, tx *sqlx.Tx, quota Quota, vaultID uuid.UUID, encryptedData, nonce []byte, actorID uuid.UUID) (*models.VaultEntry, error) {
+	if err := quota.checkEntrySize(encryptedData); err != nil {
+		return nil, err
+	}
+
+	// The data is stored twice, in the entry and its first revision
+	if err := quota.reserveEntries(ctx, tx, vaultID, 1, 2*int64(len(encryptedData))); err != nil {
+		return nil, err
+	}
+
+	entry := &models.VaultEntry{}
+
+	query := `
+		INSERT INTO vault_entries (vault_id, encrypted_data, nonce)
+		VALUES ($1, $2, $3)
+		RETURNING id, vault_id, encrypted_data, nonce, version, folder_id, favorite, created_at, updated_at
+	`
+
+	err := tx.QueryRowxContext(ctx, query, vaultID, encryptedData, nonce).StructScan(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create entry: %w", err)
+	}
+
+	if err := insertVersion(ctx, tx, entry, actorID); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// updateEntry writes a new revision of an entry within the quota and prunes old revisions
+func updateEntry(ctx context.Context, tx *sqlx.Tx, quota Quota, id uuid.UUID, encryptedData, nonce []byte, actorID uuid.UUID) (*models.VaultEntry, error) {
+	if err := quota.checkEntrySize(encryptedData); err != nil {
+		return nil, err
+	}
+
+	vaultID, size, err := entrySize(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The data replaces the current data and adds a revision; revisions pruned
+	// afterwards are not credited in advance
+	if err := quota.reserveEntries(ctx, tx, vaultID, 0, 2*int64(len(encryptedData))-size); err != nil {
+		return nil, err
+	}
+
+	entry := &models.VaultEntry{}
+
+	query := `
+		UPDATE vault_entries
+		SET encrypted_data = $1, nonce = $2, version = version + 1, updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING id, vault_id, encrypted_data, nonce, version, folder_id, favorite, created_at, updated_at
+	`
+
+	err = tx.QueryRowxContext(ctx, query, encryptedData, nonce, id).StructScan(entry)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("entry not found")
+		}
+		return nil, fmt.Errorf("failed to update entry: %w", err)
+	}
+
+	if err := insertVersion(ctx, tx, entry, actorID); err != nil {
+		return nil, err
+	}
+
+	if err := pruneVersions(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// deleteEntry moves an entry to the trash
+func deleteEntry(ctx context.Context, db sqlx.ExecerContext, id uuid.UUID) error {
+	query := `UPDATE vault_entries SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete entry: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("entry not found")
+	}
+
+	return nil
+}
+
+// insertVersion records the entry's current state in entry_versions
+func insertVersion(ctx context.Context, tx *sqlx.Tx, entry *models.VaultEntry, actorID uuid.UUID) error {
+	query := `
+		INSERT INTO entry_versions (entry_id, version, encrypted_data, nonce, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := tx.ExecContext(ctx, query, entry.ID, entry.Version, entry.EncryptedData, entry.Nonce, actorID, entry.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record entry version: %w", err)
+	}
+
+	return nil
+}
+
+// pruneVersions removes revisions older than the vault's retention limit
+func pruneVersions(ctx context.Context, tx *sqlx.Tx, entry *models.VaultEntry) error {
+	query := `
+		DELETE FROM entry_versions
+		WHERE entry_id = $1
+		  AND version <= $2 - (SELECT version_retention FROM vaults WHERE id = $3)
+	`
+
+	_, err := tx.ExecContext(ctx, query, entry.ID, entry.Version, entry.VaultID)
+	if err != nil {
+		return fmt.Errorf("failed to prune entry versions: %w", err)
+	}
+
+	return nil
+}
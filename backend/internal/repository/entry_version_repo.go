@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// EntryVersionRepository handles entry revision history persistence.
+// Revisions are written by EntryRepository as part of Create and Update.
+type EntryVersionRepository struct {
+	db *sqlx.DB
+}
+
+// NewEntryVersionRepository creates a new entry version repository
+func NewEntryVersionRepository(db *sqlx.DB) *EntryVersionRepository {
+	return &EntryVersionRepository{db: db}
+}
+
+// GetByEntryID retrieves all retained revisions of an entry, newest first
+func (r *EntryVersionRepository) GetByEntryID(ctx context.Context, entryID uuid.UUID) ([]*models.EntryVersion, error) {
+	versions := []*models.EntryVersion{}
+
+	query := `
+		SELECT id, entry_id, version, encrypted_data, nonce, created_by, created_at
+		FROM entry_versions
+		WHERE entry_id = $1
+		ORDER BY version DESC
+	`
+
+	err := r.db.SelectContext(ctx, &versions, query, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetByVersion retrieves a single revision of an entry
+func (r *EntryVersionRepository) GetByVersion(ctx context.Context, entryID uuid.UUID, version int) (*models.EntryVersion, error) {
+	v := &models.EntryVersion{}
+
+	query := `
+		SELECT id, entry_id, version, encrypted_data, nonce, created_by, created_at
+		FROM entry_versions
+		WHERE entry_id = $1 AND version = $2
+	`
+
+	err := r.db.GetContext(ctx, v, query, entryID, version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("entry version not found")
+		}
+		return nil, fmt.Errorf("failed to get entry version: %w", err)
+	}
+
+	return v, nil
+}
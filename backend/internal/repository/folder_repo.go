@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrFolderCycle is returned when a folder would be moved below itself
+var ErrFolderCycle = errors.New("folder cannot be moved into itself or its descendants")
+
+// FolderRepository handles folder data persistence
+type FolderRepository struct {
+	db *sqlx.DB
+}
+
+// NewFolderRepository creates a new folder repository
+func NewFolderRepository(db *sqlx.DB) *FolderRepository {
+	return &FolderRepository{db: db}
+}
+
+// Create creates a new folder
+func (r *FolderRepository) Create(ctx context.Context, vaultID uuid.UUID, parentID *uuid.UUID, encryptedName, nonce []byte) (*models.Folder, error) {
+	folder := &models.Folder{}
+
+	query := `
+		INSERT INTO folders (vault_id, parent_id, encrypted_name, nonce)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, vault_id, parent_id, encrypted_name, nonce, created_at, updated_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, vaultID, parentID, encryptedName, nonce).StructScan(folder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+// GetByID retrieves a folder by ID
+func (r *FolderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Folder, error) {
+	folder := &models.Folder{}
+
+	query := `
+		SELECT id, vault_id, parent_id, encrypted_name, nonce, created_at, updated_at
+		FROM folders
+		WHERE id = $1
+	`
+
+	err := r.db.GetContext(ctx, folder, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("folder not found")
+		}
+		return nil, fmt.Errorf("failed to get folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+// GetByVaultID retrieves all folders of a vault as a flat list
+func (r *FolderRepository) GetByVaultID(ctx context.Context, vaultID uuid.UUID) ([]*models.Folder, error) {
+	folders := []*models.Folder{}
+
+	query := `
+		SELECT id, vault_id, parent_id, encrypted_name, nonce, created_at, updated_at
+		FROM folders
+		WHERE vault_id = $1
+		ORDER BY created_at
+	`
+
+	err := r.db.SelectContext(ctx, &folders, query, vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folders: %w", err)
+	}
+
+	return folders, nil
+}
+
+// Rename replaces a folder's encrypted name
+func (r *FolderRepository) Rename(ctx context.Context, id uuid.UUID, encryptedName, nonce []byte) error {
+	query := `
+		UPDATE folders
+		SET encrypted_name = $1, nonce = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, encryptedName, nonce, id)
+	if err != nil {
+		return fmt.Errorf("failed to rename folder: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("folder not found")
+	}
+
+	return nil
+}
+
+// Move changes a folder's parent, rejecting moves that would create a cycle.
+// The caller must ensure the new parent belongs to the same vault.
+func (r *FolderRepository) Move(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Serialise tree changes within the vault
+	lockQuery := `SELECT 1 FROM vaults WHERE id = (SELECT vault_id FROM folders WHERE id = $1) FOR UPDATE`
+	if _, err := tx.ExecContext(ctx, lockQuery, id); err != nil {
+		return fmt.Errorf("failed to lock vault: %w", err)
+	}
+
+	if parentID != nil {
+		var cycle bool
+		cycleQuery := `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM folders WHERE id = $1
+				UNION ALL
+				SELECT f.id, f.parent_id FROM folders f JOIN ancestors a ON f.id = a.parent_id
+			)
+			SELECT EXISTS(SELECT 1 FROM ancestors WHERE id = $2)
+		`
+		if err := tx.GetContext(ctx, &cycle, cycleQuery, *parentID, id); err != nil {
+			return fmt.Errorf("failed to check folder tree: %w", err)
+		}
+		if cycle {
+			return ErrFolderCycle
+		}
+	}
+
+	query := `UPDATE folders SET parent_id = $1, updated_at = NOW() WHERE id = $2`
+
+	result, err := tx.ExecContext(ctx, query, parentID, id)
+	if err != nil {
+		return fmt.Errorf("failed to move folder: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("folder not found")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit folder move: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a folder. Its subfolders and entries move up to its parent.
+func (r *FolderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var parentID *uuid.UUID
+	if err := tx.GetContext(ctx, &parentID, `SELECT parent_id FROM folders WHERE id = $1 FOR UPDATE`, id); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("folder not found")
+		}
+		return fmt.Errorf("failed to get folder: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE folders SET parent_id = $1, updated_at = NOW() WHERE parent_id = $2`, parentID, id); err != nil {
+		return fmt.Errorf("failed to move subfolders: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE vault_entries SET folder_id = $1 WHERE folder_id = $2`, parentID, id); err != nil {
+		return fmt.Errorf("failed to move folder entries: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM folders WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit folder delete: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Quota error codes returned to clients
+const (
+	QuotaEntryTooLarge = "entry_too_large"
+	QuotaVaultEntries  = "vault_entry_limit"
+	QuotaUserVaults    = "vault_limit"
+	QuotaUserStorage   = "storage_limit"
+)
+
+// Quota holds the storage limits enforced on vaults, entries and attachments.
+// Usage counters are maintained by database triggers; trashed vaults and
+// entries keep counting until they are purged. Storage counts the ciphertext
+// of entries, their retained revisions and attachments.
+type Quota struct {
+	MaxEntrySize       int   // in bytes, per entry ciphertext
+	MaxEntriesPerVault int   // entries per vault
+	MaxVaultsPerUser   int   // vaults per user
+	MaxUserStorage     int64 // in bytes, entries, revisions and attachments per user
+}
+
+// QuotaError is returned when a change would exceed one of the limits in Quota
+type QuotaError struct {
+	Code  string
+	Limit int64
+}
+
+// Error implements error
+func (e *QuotaError) Error() string {
+	switch e.Code {
+	case QuotaEntryTooLarge:
+		return fmt.Sprintf("entry too large: maximum is %d bytes", e.Limit)
+	case QuotaVaultEntries:
+		return fmt.Sprintf("vault entry limit reached: maximum is %d entries", e.Limit)
+	case QuotaUserVaults:
+		return fmt.Sprintf("vault limit reached: maximum is %d vaults", e.Limit)
+	default:
+		return fmt.Sprintf("storage quota exceeded: maximum is %d bytes", e.Limit)
+	}
+}
+
+// checkEntrySize rejects ciphertext larger than MaxEntrySize
+func (q Quota) checkEntrySize(encryptedData []byte) error {
+	if len(encryptedData) > q.MaxEntrySize {
+		return &QuotaError{Code: QuotaEntryTooLarge, Limit: int64(q.MaxEntrySize)}
+	}
+	return nil
+}
+
+// reserveEntries checks that adding entries to a vault and storage bytes to
+// its owner stays within the limits. It locks the owner's usage row until the transaction ends, so
+// concurrent writers of the same user are serialised until their counters are updated.
+// Shrinking changes are always allowed, even when the user is over a lowered limit.
+func (q Quota) reserveEntries(ctx context.Context, tx *sqlx.Tx, vaultID uuid.UUID, entries int, bytes int64) error {
+	var usage struct {
+		StorageBytes int64 `db:"storage_bytes"`
+		EntryCount   int   `db:"entry_count"`
+	}
+
+	query := `
+		SELECT u.entry_bytes + u.version_bytes + u.attachment_bytes AS storage_bytes, vu.entry_count
+		FROM vaults v
+		JOIN user_usage u ON u.user_id = v.user_id
+		JOIN vault_usage vu ON vu.vault_id = v.id
+		WHERE v.id = $1
+		FOR UPDATE OF u
+	`
+
+	if err := tx.GetContext(ctx, &usage, query, vaultID); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("vault not found")
+		}
+		return fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	if entries > 0 && usage.EntryCount+entries > q.MaxEntriesPerVault {
+		return &QuotaError{Code: QuotaVaultEntries, Limit: int64(q.MaxEntriesPerVault)}
+	}
+
+	if bytes > 0 && usage.StorageBytes+bytes > q.MaxUserStorage {
+		return &QuotaError{Code: QuotaUserStorage, Limit: q.MaxUserStorage}
+	}
+
+	return nil
+}
+
+// lockUsage locks the user's usage row until the transaction ends. Counter
+// triggers lock it on every entry write, so transactions that write several
+// rows take it first to keep a consistent lock order.
+func lockUsage(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID) error {
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM user_usage WHERE user_id = $1 FOR UPDATE`, userID); err != nil {
+		return fmt.Errorf("failed to lock usage: %w", err)
+	}
+	return nil
+}
+
+// reserveStorage checks that adding bytes to the user's storage stays within
+// MaxUserStorage, locking the usage row like reserveEntries
+func (q Quota) reserveStorage(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID, bytes int64) error {
+	var used int64
+
+	query := `
+		SELECT entry_bytes + version_bytes + attachment_bytes
+		FROM user_usage
+		WHERE user_id = $1
+		FOR UPDATE
+	`
+
+	if err := tx.GetContext(ctx, &used, query, userID); err != nil {
+		return fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	if bytes > 0 && used+bytes > q.MaxUserStorage {
+		return &QuotaError{Code: QuotaUserStorage, Limit: q.MaxUserStorage}
+	}
+
+	return nil
+}
+
+// versionsSize returns the ciphertext size of an entry's retained revisions
+func versionsSize(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) (int64, error) {
+	var size int64
+
+	query := `SELECT COALESCE(SUM(octet_length(encrypted_data)), 0) FROM entry_versions WHERE entry_id = $1`
+
+	if err := tx.GetContext(ctx, &size, query, id); err != nil {
+		return 0, fmt.Errorf("failed to get entry versions size: %w", err)
+	}
+
+	return size, nil
+}
+
+// entrySize returns the vault and ciphertext size of a live entry
+func entrySize(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) (uuid.UUID, int64, error) {
+	var current struct {
+		VaultID uuid.UUID `db:"vault_id"`
+		Size    int64     `db:"size"`
+	}
+
+	query := `SELECT vault_id, octet_length(encrypted_data) AS size FROM vault_entries WHERE id = $1 AND deleted_at IS NULL`
+
+	if err := tx.GetContext(ctx, &current, query, id); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, 0, fmt.Errorf("entry not found")
+		}
+		return uuid.Nil, 0, fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	return current.VaultID, current.Size, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// SearchRepository handles blind index token persistence and lookup
+type SearchRepository struct {
+	db *sqlx.DB
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *sqlx.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// SetEntryTokens replaces the search tokens of an entry
+func (r *SearchRepository) SetEntryTokens(ctx context.Context, entryID, vaultID uuid.UUID, tokens [][]byte) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entry_search_tokens WHERE entry_id = $1`, entryID); err != nil {
+		return fmt.Errorf("failed to clear search tokens: %w", err)
+	}
+
+	if len(tokens) > 0 {
+		query := `
+			INSERT INTO entry_search_tokens (entry_id, vault_id, token)
+			SELECT $1, $2, t FROM unnest($3::bytea[]) AS t
+			ON CONFLICT DO NOTHING
+		`
+
+		if _, err := tx.ExecContext(ctx, query, entryID, vaultID, pq.ByteaArray(tokens)); err != nil {
+			return fmt.Errorf("failed to set search tokens: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit search tokens: %w", err)
+	}
+
+	return nil
+}
+
+// Search returns the live entries of a vault carrying at least minMatches of
+// the given tokens, ordered by the number of matched tokens
+func (r *SearchRepository) Search(ctx context.Context, vaultID uuid.UUID, tokens [][]byte, minMatches, limit int) ([]*models.SearchResult, error) {
+	results := []*models.SearchResult{}
+
+	query := `
+		SELECT t.entry_id, COUNT(*) AS matches
+		FROM entry_search_tokens t
+		JOIN vault_entries e ON e.id = t.entry_id
+		WHERE t.vault_id = $1 AND t.token = ANY($2::bytea[]) AND e.deleted_at IS NULL
+		GROUP BY t.entry_id
+		HAVING COUNT(*) >= $3
+		ORDER BY matches DESC, t.entry_id
+		LIMIT $4
+	`
+
+	err := r.db.SelectContext(ctx, &results, query, vaultID, pq.ByteaArray(tokens), minMatches, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search entries: %w", err)
+	}
+
+	return results, nil
+}
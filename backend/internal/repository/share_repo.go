@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrShareUnavailable is returned when a share is revoked, expired or out of views
+var ErrShareUnavailable = errors.New("share no longer available")
+
+// ShareRepository handles share link persistence
+type ShareRepository struct {
+	db *sqlx.DB
+}
+
+// NewShareRepository creates a new share repository
+func NewShareRepository(db *sqlx.DB) *ShareRepository {
+	return &ShareRepository{db: db}
+}
+
+// Create creates a new share
+func (r *ShareRepository) Create(ctx context.Context, share *models.Share) (*models.Share, error) {
+	created := &models.Share{}
+
+	query := `
+		INSERT INTO shares (id, user_id, encrypted_data, nonce, password_hash, max_views, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, user_id, encrypted_data, nonce, password_hash, max_views, view_count, expires_at, created_at, revoked_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, share.ID, share.UserID, share.EncryptedData, share.Nonce,
+		share.PasswordHash, share.MaxViews, share.ExpiresAt).StructScan(created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByID retrieves a share by ID regardless of its state
+func (r *ShareRepository) GetByID(ctx context.Context, id string) (*models.Share, error) {
+	share := &models.Share{}
+
+	query := `
+		SELECT id, user_id, encrypted_data, nonce, password_hash, max_views, view_count, expires_at, created_at, revoked_at
+		FROM shares
+		WHERE id = $1
+	`
+
+	err := r.db.GetContext(ctx, share, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("share not found")
+		}
+		return nil, fmt.Errorf("failed to get share: %w", err)
+	}
+
+	return share, nil
+}
+
+// GetByUserID retrieves all shares of a user that have not been purged
+func (r *ShareRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Share, error) {
+	shares := []*models.Share{}
+
+	query := `
+		SELECT id, user_id, encrypted_data, nonce, password_hash, max_views, view_count, expires_at, created_at, revoked_at
+		FROM shares
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &shares, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+// Consume counts a view and returns the share with its ciphertext. The
+// ciphertext is cleared when the last allowed view is used.
+func (r *ShareRepository) Consume(ctx context.Context, id string) (*models.Share, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	share := &models.Share{}
+	query := `
+		UPDATE shares
+		SET view_count = view_count + 1
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		  AND (max_views IS NULL OR view_count < max_views)
+		RETURNING id, user_id, encrypted_data, nonce, password_hash, max_views, view_count, expires_at, created_at, revoked_at
+	`
+
+	err = tx.QueryRowxContext(ctx, query, id).StructScan(share)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrShareUnavailable
+		}
+		return nil, fmt.Errorf("failed to consume share: %w", err)
+	}
+
+	if share.MaxViews != nil && share.ViewCount >= *share.MaxViews {
+		if _, err := tx.ExecContext(ctx, `UPDATE shares SET encrypted_data = NULL WHERE id = $1`, id); err != nil {
+			return nil, fmt.Errorf("failed to clear share data: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit share view: %w", err)
+	}
+
+	return share, nil
+}
+
+// Revoke revokes a share of a user and clears its ciphertext
+func (r *ShareRepository) Revoke(ctx context.Context, id string, userID uuid.UUID) error {
+	query := `
+		UPDATE shares
+		SET revoked_at = NOW(), encrypted_data = NULL
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("share not found")
+	}
+
+	return nil
+}
+
+// PurgeExpiredBefore permanently deletes shares that expired before cutoff
+func (r *ShareRepository) PurgeExpiredBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM shares WHERE expires_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired shares: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
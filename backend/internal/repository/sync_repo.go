@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// SyncRepository reads change feeds for delta synchronisation
+type SyncRepository struct {
+	db *sqlx.DB
+}
+
+// NewSyncRepository creates a new sync repository
+func NewSyncRepository(db *sqlx.DB) *SyncRepository {
+	return &SyncRepository{db: db}
+}
+
+// GetChanges retrieves up to limit changes after the cursor, ordered by
+// (change_xid, change_seq). Trashed rows and tombstones are reported as deleted.
+// change_seq is taken before commit, so only changes of transactions older than
+// the oldest running transaction are returned: every change committed later has
+// a newer transaction ID and sorts after the returned ones. A long-running
+// transaction therefore delays the changes of later transactions until it ends.
+func (r *SyncRepository) GetChanges(ctx context.Context, userID uuid.UUID, since models.SyncCursor, limit int) ([]*models.SyncChange, error) {
+	changes := []*models.SyncChange{}
+
+	query := `
+		SELECT c.type, c.id, c.vault_id, c.change_xid::text::bigint AS change_xid, c.change_seq, c.deleted
+		FROM (
+			SELECT 'vault' AS type, v.id, v.id AS vault_id, v.change_xid, v.change_seq, v.deleted_at IS NOT NULL AS deleted
+			FROM vaults v
+			WHERE v.user_id = $1 AND (v.change_xid, v.change_seq) > ($2::text::xid8, $3)
+			UNION ALL
+			SELECT 'entry' AS type, e.id, e.vault_id, e.change_xid, e.change_seq,
+			       e.deleted_at IS NOT NULL OR v.deleted_at IS NOT NULL AS deleted
+			FROM vault_entries e
+			JOIN vaults v ON v.id = e.vault_id
+			WHERE v.user_id = $1 AND (e.change_xid, e.change_seq) > ($2::text::xid8, $3)
+			UNION ALL
+			SELECT CASE WHEN t.entry_id IS NULL THEN 'vault' ELSE 'entry' END AS type,
+			       COALESCE(t.entry_id, t.vault_id) AS id, t.vault_id, t.change_xid, t.change_seq, TRUE AS deleted
+			FROM sync_tombstones t
+			WHERE t.user_id = $1 AND (t.change_xid, t.change_seq) > ($2::text::xid8, $3)
+		) c
+		WHERE c.change_xid < pg_snapshot_xmin(pg_current_snapshot())
+		ORDER BY c.change_xid, c.change_seq
+		LIMIT $4
+	`
+
+	err := r.db.SelectContext(ctx, &changes, query, userID, strconv.FormatInt(since.XID, 10), since.Seq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// GetVaultsByIDs retrieves the non-trashed vaults of a user with the given IDs
+func (r *SyncRepository) GetVaultsByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.Vault, error) {
+	vaults := []*models.Vault{}
+	if len(ids) == 0 {
+		return vaults, nil
+	}
+
+	query := `
+		SELECT id, user_id, name, encryption_salt, version_retention, key_generation, kdf_algorithm, kdf_params, created_at, updated_at
+		FROM vaults
+		WHERE user_id = $1 AND id = ANY($2::uuid[]) AND deleted_at IS NULL
+	`
+
+	err := r.db.SelectContext(ctx, &vaults, query, userID, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vaults: %w", err)
+	}
+
+	return vaults, nil
+}
+
+// GetEntriesByIDs retrieves the non-trashed entries of a user with the given IDs
+func (r *SyncRepository) GetEntriesByIDs(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) ([]*models.VaultEntry, error) {
+	entries := []*models.VaultEntry{}
+	if len(ids) == 0 {
+		return entries, nil
+	}
+
+	query := `
+		SELECT e.id, e.vault_id, e.encrypted_data, e.nonce, e.version, e.folder_id, e.favorite, e.created_at, e.updated_at
+		FROM vault_entries e
+		JOIN vaults v ON v.id = e.vault_id
+		WHERE v.user_id = $1 AND e.id = ANY($2::uuid[]) AND e.deleted_at IS NULL AND v.deleted_at IS NULL
+	`
+
+	err := r.db.SelectContext(ctx, &entries, query, userID, pq.Array(uuidStrings(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// uuidStrings converts UUIDs to strings for use with pq.Array
+func uuidStrings(ids []uuid.UUID) []string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = id.String()
+	}
+	return s
+}
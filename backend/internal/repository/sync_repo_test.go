@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// testDB connects to the migrated database in TEST_DATABASE_URL, skipping the
+// test if it is not set
+func testDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sqlx.Connect("postgres", url)
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// TestGetChangesWaitsForRunningTransactions checks that a change made by a
+// transaction still running when a cursor is issued is delivered on the next
+// sync, although a later transaction committed a change first
+func TestGetChangesWaitsForRunningTransactions(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := NewSyncRepository(db)
+
+	var userID, vaultID, slowID uuid.UUID
+	if err := db.GetContext(ctx, &userID, `INSERT INTO users (email, password_hash) VALUES ($1, 'x') RETURNING id`,
+		"sync-"+uuid.NewString()+"@example.com"); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	t.Cleanup(func() { _, _ = db.Exec(`DELETE FROM users WHERE id = $1`, userID) })
+
+	if err := db.GetContext(ctx, &vaultID, `
+		INSERT INTO vaults (user_id, name, encryption_salt, kdf_algorithm, kdf_params)
+		VALUES ($1, 'sync', '\x00', 'argon2id', '{"iterations":3}') RETURNING id`, userID); err != nil {
+		t.Fatalf("failed to create vault: %v", err)
+	}
+	if err := db.GetContext(ctx, &slowID, `
+		INSERT INTO vault_entries (vault_id, encrypted_data, nonce) VALUES ($1, '\x01', '\x02') RETURNING id`, vaultID); err != nil {
+		t.Fatalf("failed to create entry: %v", err)
+	}
+
+	// Wait until the setup is older than every running transaction
+	cursor := models.SyncCursor{}
+	for range 50 {
+		changes, err := repo.GetChanges(ctx, userID, cursor, 100)
+		if err != nil {
+			t.Fatalf("GetChanges: %v", err)
+		}
+		for _, change := range changes {
+			cursor = change.Cursor()
+		}
+		if len(changes) == 0 && cursor != (models.SyncCursor{}) {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if cursor == (models.SyncCursor{}) {
+		t.Fatal("the initial changes were never delivered")
+	}
+
+	// The slow transaction takes its change sequence number first
+	slow, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer func() { _ = slow.Rollback() }()
+	if _, err := slow.ExecContext(ctx, `UPDATE vault_entries SET encrypted_data = '\x03' WHERE id = $1`, slowID); err != nil {
+		t.Fatalf("failed to update entry: %v", err)
+	}
+
+	var fastID uuid.UUID
+	if err := db.GetContext(ctx, &fastID, `
+		INSERT INTO vault_entries (vault_id, encrypted_data, nonce) VALUES ($1, '\x04', '\x05') RETURNING id`, vaultID); err != nil {
+		t.Fatalf("failed to create entry: %v", err)
+	}
+
+	// Handing out the committed change now would move the cursor past the
+	// slow transaction's change
+	changes, err := repo.GetChanges(ctx, userID, cursor, 100)
+	if err != nil {
+		t.Fatalf("GetChanges: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("got %d changes while an older transaction is running, want none", len(changes))
+	}
+
+	if err := slow.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	changes, err = repo.GetChanges(ctx, userID, cursor, 100)
+	if err != nil {
+		t.Fatalf("GetChanges: %v", err)
+	}
+	if len(changes) != 2 || changes[0].ID != slowID || changes[1].ID != fastID {
+		t.Fatalf("got changes %+v, want the slow then the fast entry", changes)
+	}
+}
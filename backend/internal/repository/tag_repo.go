@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Tag errors
+var (
+	// ErrTagExists is returned when a vault already has a tag with the same blind ID
+	ErrTagExists = errors.New("tag already exists")
+	// ErrTagNotInVault is returned when an entry is tagged with a tag of another vault
+	ErrTagNotInVault = errors.New("tag not found in vault")
+)
+
+// TagRepository handles tag data persistence
+type TagRepository struct {
+	db *sqlx.DB
+}
+
+// NewTagRepository creates a new tag repository
+func NewTagRepository(db *sqlx.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// Create creates a new tag
+func (r *TagRepository) Create(ctx context.Context, vaultID uuid.UUID, blindID, encryptedName, nonce []byte) (*models.Tag, error) {
+	tag := &models.Tag{}
+
+	query := `
+		INSERT INTO tags (vault_id, blind_id, encrypted_name, nonce)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, vault_id, blind_id, encrypted_name, nonce, created_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, vaultID, blindID, encryptedName, nonce).StructScan(tag)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrTagExists
+		}
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// GetByID retrieves a tag by ID
+func (r *TagRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Tag, error) {
+	tag := &models.Tag{}
+
+	query := `
+		SELECT id, vault_id, blind_id, encrypted_name, nonce, created_at
+		FROM tags
+		WHERE id = $1
+	`
+
+	err := r.db.GetContext(ctx, tag, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tag not found")
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+// GetByVaultID retrieves all tags of a vault
+func (r *TagRepository) GetByVaultID(ctx context.Context, vaultID uuid.UUID) ([]*models.Tag, error) {
+	tags := []*models.Tag{}
+
+	query := `
+		SELECT id, vault_id, blind_id, encrypted_name, nonce, created_at
+		FROM tags
+		WHERE vault_id = $1
+		ORDER BY created_at
+	`
+
+	err := r.db.SelectContext(ctx, &tags, query, vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetAssignments retrieves the tags of all live entries in a vault
+func (r *TagRepository) GetAssignments(ctx context.Context, vaultID uuid.UUID) ([]*models.EntryTag, error) {
+	assignments := []*models.EntryTag{}
+
+	query := `
+		SELECT et.entry_id, et.tag_id
+		FROM entry_tags et
+		JOIN vault_entries e ON e.id = et.entry_id
+		WHERE e.vault_id = $1 AND e.deleted_at IS NULL
+	`
+
+	err := r.db.SelectContext(ctx, &assignments, query, vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag assignments: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// Delete deletes a tag and removes it from all entries
+func (r *TagRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM tags WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("tag not found")
+	}
+
+	return nil
+}
+
+// SetEntryTags replaces the tags of an entry. All tags must belong to the entry's vault.
+func (r *TagRepository) SetEntryTags(ctx context.Context, entryID, vaultID uuid.UUID, tagIDs []uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entry_tags WHERE entry_id = $1`, entryID); err != nil {
+		return fmt.Errorf("failed to clear entry tags: %w", err)
+	}
+
+	if len(tagIDs) > 0 {
+		query := `
+			INSERT INTO entry_tags (entry_id, tag_id)
+			SELECT $1, id FROM tags WHERE vault_id = $2 AND id = ANY($3::uuid[])
+		`
+
+		result, err := tx.ExecContext(ctx, query, entryID, vaultID, pq.Array(uuidStrings(tagIDs)))
+		if err != nil {
+			return fmt.Errorf("failed to set entry tags: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rows != int64(len(uniqueUUIDs(tagIDs))) {
+			return ErrTagNotInVault
+		}
+	}
+
+	// Bump the entry's change sequence so sync clients pick up the new tags
+	if _, err := tx.ExecContext(ctx, `UPDATE vault_entries SET favorite = favorite WHERE id = $1`, entryID); err != nil {
+		return fmt.Errorf("failed to touch entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit entry tags: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueUUIDs removes duplicate IDs
+func uniqueUUIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
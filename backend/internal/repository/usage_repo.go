@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// UsageRepository reads the usage counters maintained by database triggers
+type UsageRepository struct {
+	db *sqlx.DB
+}
+
+// NewUsageRepository creates a new usage repository
+func NewUsageRepository(db *sqlx.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// GetByUserID retrieves a user's usage, including the entry count of every vault
+func (r *UsageRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.Usage, error) {
+	usage := &models.Usage{}
+
+	query := `
+		SELECT u.vault_count, u.entry_bytes, u.version_bytes, u.attachment_bytes
+		FROM user_usage u
+		WHERE u.user_id = $1
+	`
+
+	err := r.db.GetContext(ctx, usage, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("usage not found")
+		}
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	usage.Vaults = []models.VaultUsage{}
+	vaultsQuery := `
+		SELECT v.id AS vault_id, vu.entry_count, v.deleted_at IS NOT NULL AS trashed
+		FROM vaults v
+		JOIN vault_usage vu ON vu.vault_id = v.id
+		WHERE v.user_id = $1
+		ORDER BY v.created_at, v.id
+	`
+
+	if err := r.db.SelectContext(ctx, &usage.Vaults, vaultsQuery, userID); err != nil {
+		return nil, fmt.Errorf("failed to get vault usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -55,6 +55,22 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	return user, nil
 }
 
+// IsAdmin reports whether the user is an administrator
+func (r *UserRepository) IsAdmin(ctx context.Context, id uuid.UUID) (bool, error) {
+	var isAdmin bool
+	query := `SELECT is_admin FROM users WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &isAdmin, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get user role: %w", err)
+	}
+
+	return isAdmin, nil
+}
+
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
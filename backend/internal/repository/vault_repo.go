@@ -3,38 +3,70 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/SecurityByDesign/pwmanager/internal/models"
+	"github.com/SecurityByDesign/pwmanager/internal/pagination"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 )
 
+// Key rotation errors
+var (
+	// ErrKeyGenerationMismatch is returned when the vault was rotated after the client read it
+	ErrKeyGenerationMismatch = errors.New("key generation mismatch")
+	// ErrIncompleteRotation is returned when the rotated entries, folders or tags do not match the vault's
+	ErrIncompleteRotation = errors.New("rotation does not match the vault's entries, folders and tags")
+)
+
 // VaultRepository handles vault data persistence
 type VaultRepository struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	quota Quota
 }
 
-// NewVaultRepository creates a new vault repository
-func NewVaultRepository(db *sqlx.DB) *VaultRepository {
-	return &VaultRepository{db: db}
+// NewVaultRepository creates a new vault repository enforcing the given quota
+func NewVaultRepository(db *sqlx.DB, quota Quota) *VaultRepository {
+	return &VaultRepository{db: db, quota: quota}
 }
 
-// Create creates a new vault
-func (r *VaultRepository) Create(ctx context.Context, userID uuid.UUID, name string, encryptionSalt []byte) (*models.Vault, error) {
+// Create creates a new vault unless the user has reached the vault limit
+func (r *VaultRepository) Create(ctx context.Context, userID uuid.UUID, name string, encryptionSalt []byte, kdfAlgorithm string, kdfParams models.KDFParams) (*models.Vault, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var vaultCount int
+	usageQuery := `SELECT vault_count FROM user_usage WHERE user_id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &vaultCount, usageQuery, userID); err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+
+	if vaultCount >= r.quota.MaxVaultsPerUser {
+		return nil, &QuotaError{Code: QuotaUserVaults, Limit: int64(r.quota.MaxVaultsPerUser)}
+	}
+
 	vault := &models.Vault{}
 
 	query := `
-		INSERT INTO vaults (user_id, name, encryption_salt)
-		VALUES ($1, $2, $3)
-		RETURNING id, user_id, name, encryption_salt, created_at, updated_at
+		INSERT INTO vaults (user_id, name, encryption_salt, kdf_algorithm, kdf_params)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, name, encryption_salt, version_retention, key_generation, kdf_algorithm, kdf_params, created_at, updated_at
 	`
 
-	err := r.db.QueryRowxContext(ctx, query, userID, name, encryptionSalt).StructScan(vault)
+	err = tx.QueryRowxContext(ctx, query, userID, name, encryptionSalt, kdfAlgorithm, kdfParams).StructScan(vault)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vault: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit vault: %w", err)
+	}
+
 	return vault, nil
 }
 
@@ -43,9 +75,9 @@ func (r *VaultRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Va
 	vault := &models.Vault{}
 
 	query := `
-		SELECT id, user_id, name, encryption_salt, created_at, updated_at
+		SELECT id, user_id, name, encryption_salt, version_retention, key_generation, kdf_algorithm, kdf_params, created_at, updated_at
 		FROM vaults
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	err := r.db.GetContext(ctx, vault, query, id)
@@ -59,18 +91,32 @@ func (r *VaultRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Va
 	return vault, nil
 }
 
-// GetByUserID retrieves all vaults for a user
-func (r *VaultRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Vault, error) {
+// VaultSorts lists the sort columns supported by GetByUserID; the first is the default
+var VaultSorts = pagination.Sorts{
+	Columns: []pagination.Column{
+		{Name: "created_at", Type: pagination.TypeTimestamp},
+		{Name: "updated_at", Type: pagination.TypeTimestamp},
+		{Name: "name", Type: pagination.TypeText},
+	},
+	IDType: pagination.TypeUUID,
+}
+
+// GetByUserID retrieves a page of vaults for a user.
+// It returns up to page.Limit+1 rows so callers can detect a further page.
+func (r *VaultRepository) GetByUserID(ctx context.Context, userID uuid.UUID, page pagination.Params) ([]*models.Vault, error) {
 	vaults := []*models.Vault{}
 
-	query := `
-		SELECT id, user_id, name, encryption_salt, created_at, updated_at
+	where, orderBy, args := pagination.Keyset(page, page.Sort, "id", 2)
+
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, encryption_salt, version_retention, key_generation, kdf_algorithm, kdf_params, created_at, updated_at
 		FROM vaults
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-	`
+		WHERE user_id = $1 AND deleted_at IS NULL AND %s
+		%s
+		LIMIT %d
+	`, where, orderBy, page.Limit+1)
 
-	err := r.db.SelectContext(ctx, &vaults, query, userID)
+	err := r.db.SelectContext(ctx, &vaults, query, append([]interface{}{userID}, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get vaults: %w", err)
 	}
@@ -78,15 +124,23 @@ func (r *VaultRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]
 	return vaults, nil
 }
 
-// Update updates a vault's name
-func (r *VaultRepository) Update(ctx context.Context, id uuid.UUID, name string) error {
+// Update updates a vault's name and, if retention is set, how many revisions
+// are kept per entry, pruning revisions that fall outside the new limit. Both
+// changes are applied in one transaction.
+func (r *VaultRepository) Update(ctx context.Context, id uuid.UUID, name string, retention *int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
 		UPDATE vaults
-		SET name = $1, updated_at = NOW()
-		WHERE id = $2
+		SET name = $1, version_retention = COALESCE($2, version_retention), updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.ExecContext(ctx, query, name, id)
+	result, err := tx.ExecContext(ctx, query, name, retention, id)
 	if err != nil {
 		return fmt.Errorf("failed to update vault: %w", err)
 	}
@@ -100,12 +154,209 @@ func (r *VaultRepository) Update(ctx context.Context, id uuid.UUID, name string)
 		return fmt.Errorf("vault not found")
 	}
 
+	if retention != nil {
+		pruneQuery := `
+			DELETE FROM entry_versions ev
+			USING vault_entries e
+			WHERE ev.entry_id = e.id
+			  AND e.vault_id = $1
+			  AND ev.version <= e.version - $2
+		`
+
+		if _, err := tx.ExecContext(ctx, pruneQuery, id, *retention); err != nil {
+			return fmt.Errorf("failed to prune entry versions: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit vault update: %w", err)
+	}
+
+	return nil
+}
+
+// RotateKey atomically replaces the vault's salt and the ciphertext of every
+// entry, including trashed ones, folder name and tag, and increments the key generation. Retained
+// revisions are encrypted under the old key and are replaced by the new ciphertext;
+// blind index search tokens and autofill hashes are dropped and must be re-uploaded.
+// The KDF is changed as well when rotation.KDFParams is set. Each ciphertext must
+// fit MaxEntrySize, but the storage quota is not enforced so rotation is always possible.
+func (r *VaultRepository) RotateKey(ctx context.Context, id uuid.UUID, rotation *models.VaultKeyRotation, actorID uuid.UUID) (*models.Vault, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, rotated := range rotation.Entries {
+		if err := r.quota.checkEntrySize(rotated.EncryptedData); err != nil {
+			return nil, err
+		}
+	}
+
+	// Lock the owner's usage counters first; re-encrypting entries updates them
+	usageQuery := `
+		SELECT 1 FROM user_usage u
+		JOIN vaults v ON v.user_id = u.user_id
+		WHERE v.id = $1
+		FOR UPDATE OF u
+	`
+	if _, err := tx.ExecContext(ctx, usageQuery, id); err != nil {
+		return nil, fmt.Errorf("failed to lock usage: %w", err)
+	}
+
+	// Lock the vault; this also blocks concurrent entry inserts via the foreign key
+	var generation int
+	lockQuery := `SELECT key_generation FROM vaults WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	if err := tx.GetContext(ctx, &generation, lockQuery, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("vault not found")
+		}
+		return nil, fmt.Errorf("failed to lock vault: %w", err)
+	}
+
+	if rotation.ExpectedKeyGeneration != nil && *rotation.ExpectedKeyGeneration != generation {
+		return nil, fmt.Errorf("%w: vault is at generation %d", ErrKeyGenerationMismatch, generation)
+	}
+
+	var entryIDs []uuid.UUID
+	entriesQuery := `SELECT id FROM vault_entries WHERE vault_id = $1 FOR UPDATE`
+	if err := tx.SelectContext(ctx, &entryIDs, entriesQuery, id); err != nil {
+		return nil, fmt.Errorf("failed to lock entries: %w", err)
+	}
+
+	var folderIDs []uuid.UUID
+	foldersQuery := `SELECT id FROM folders WHERE vault_id = $1 FOR UPDATE`
+	if err := tx.SelectContext(ctx, &folderIDs, foldersQuery, id); err != nil {
+		return nil, fmt.Errorf("failed to lock folders: %w", err)
+	}
+
+	var tagIDs []uuid.UUID
+	tagsQuery := `SELECT id FROM tags WHERE vault_id = $1 FOR UPDATE`
+	if err := tx.SelectContext(ctx, &tagIDs, tagsQuery, id); err != nil {
+		return nil, fmt.Errorf("failed to lock tags: %w", err)
+	}
+
+	rotatedEntryIDs := make([]uuid.UUID, len(rotation.Entries))
+	for i, entry := range rotation.Entries {
+		rotatedEntryIDs[i] = entry.ID
+	}
+	if err := checkRotationComplete("entries", entryIDs, rotatedEntryIDs); err != nil {
+		return nil, err
+	}
+
+	rotatedFolderIDs := make([]uuid.UUID, len(rotation.Folders))
+	for i, folder := range rotation.Folders {
+		rotatedFolderIDs[i] = folder.ID
+	}
+	if err := checkRotationComplete("folders", folderIDs, rotatedFolderIDs); err != nil {
+		return nil, err
+	}
+
+	rotatedTagIDs := make([]uuid.UUID, len(rotation.Tags))
+	for i, tag := range rotation.Tags {
+		rotatedTagIDs[i] = tag.ID
+	}
+	if err := checkRotationComplete("tags", tagIDs, rotatedTagIDs); err != nil {
+		return nil, err
+	}
+
+	dropQuery := `
+		DELETE FROM entry_versions ev
+		USING vault_entries e
+		WHERE ev.entry_id = e.id AND e.vault_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, dropQuery, id); err != nil {
+		return nil, fmt.Errorf("failed to drop entry versions: %w", err)
+	}
+
+	// Search tokens and autofill hashes are keyed by material derived from the old key; clients re-index
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entry_search_tokens WHERE vault_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to drop search tokens: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM entry_autofill_hashes WHERE vault_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to drop autofill hashes: %w", err)
+	}
+
+	updateQuery := `
+		UPDATE vault_entries
+		SET encrypted_data = $1, nonce = $2, version = version + 1, updated_at = NOW()
+		WHERE id = $3
+		RETURNING id, vault_id, encrypted_data, nonce, version, folder_id, favorite, created_at, updated_at
+	`
+	for _, rotated := range rotation.Entries {
+		entry := &models.VaultEntry{}
+		err := tx.QueryRowxContext(ctx, updateQuery, rotated.EncryptedData, rotated.Nonce, rotated.ID).StructScan(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-encrypt entry: %w", err)
+		}
+
+		if err := insertVersion(ctx, tx, entry, actorID); err != nil {
+			return nil, err
+		}
+	}
+
+	folderQuery := `UPDATE folders SET encrypted_name = $1, nonce = $2, updated_at = NOW() WHERE id = $3`
+	for _, rotated := range rotation.Folders {
+		if _, err := tx.ExecContext(ctx, folderQuery, rotated.EncryptedName, rotated.Nonce, rotated.ID); err != nil {
+			return nil, fmt.Errorf("failed to re-encrypt folder: %w", err)
+		}
+	}
+
+	// Entry tags reference tags by ID, so re-keying a tag keeps its assignments
+	tagQuery := `UPDATE tags SET blind_id = $1, encrypted_name = $2, nonce = $3 WHERE id = $4`
+	for _, rotated := range rotation.Tags {
+		if _, err := tx.ExecContext(ctx, tagQuery, rotated.BlindID, rotated.EncryptedName, rotated.Nonce, rotated.ID); err != nil {
+			return nil, fmt.Errorf("failed to re-key tag: %w", err)
+		}
+	}
+
+	vault := &models.Vault{}
+	vaultQuery := `
+		UPDATE vaults
+		SET encryption_salt = $1, key_generation = key_generation + 1,
+		    kdf_algorithm = COALESCE(NULLIF($3, ''), kdf_algorithm),
+		    kdf_params = COALESCE($4::jsonb, kdf_params),
+		    updated_at = NOW()
+		WHERE id = $2
+		RETURNING id, user_id, name, encryption_salt, version_retention, key_generation, kdf_algorithm, kdf_params, created_at, updated_at
+	`
+	if err := tx.QueryRowxContext(ctx, vaultQuery, rotation.EncryptionSalt, id, rotation.KDFAlgorithm, rotation.KDFParams).StructScan(vault); err != nil {
+		return nil, fmt.Errorf("failed to rotate vault key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit key rotation: %w", err)
+	}
+
+	return vault, nil
+}
+
+// checkRotationComplete verifies that rotated contains each of the vault's
+// entries, folders or tags, named by kind, exactly once
+func checkRotationComplete(kind string, ids, rotated []uuid.UUID) error {
+	pending := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		pending[id] = true
+	}
+
+	for _, id := range rotated {
+		if !pending[id] {
+			return fmt.Errorf("%w: unknown or duplicate ID %s in %s", ErrIncompleteRotation, id, kind)
+		}
+		delete(pending, id)
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d %s missing", ErrIncompleteRotation, len(pending), kind)
+	}
+
 	return nil
 }
 
-// Delete deletes a vault (will cascade to entries)
+// Delete moves a vault and its entries to the trash
 func (r *VaultRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM vaults WHERE id = $1`
+	query := `UPDATE vaults SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
@@ -124,10 +375,127 @@ func (r *VaultRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-// CheckOwnership checks if a vault belongs to a user
+// GetTrashedByID retrieves a vault from the trash by ID
+func (r *VaultRepository) GetTrashedByID(ctx context.Context, id uuid.UUID) (*models.Vault, error) {
+	vault := &models.Vault{}
+
+	query := `
+		SELECT id, user_id, name, encryption_salt, version_retention, key_generation, kdf_algorithm, kdf_params, created_at, updated_at, deleted_at
+		FROM vaults
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	err := r.db.GetContext(ctx, vault, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("vault not found")
+		}
+		return nil, fmt.Errorf("failed to get vault: %w", err)
+	}
+
+	return vault, nil
+}
+
+// GetTrashedByUserID retrieves all vaults in a user's trash
+func (r *VaultRepository) GetTrashedByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Vault, error) {
+	vaults := []*models.Vault{}
+
+	query := `
+		SELECT id, user_id, name, encryption_salt, version_retention, key_generation, kdf_algorithm, kdf_params, created_at, updated_at, deleted_at
+		FROM vaults
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &vaults, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed vaults: %w", err)
+	}
+
+	return vaults, nil
+}
+
+// Restore moves a vault out of the trash
+func (r *VaultRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `UPDATE vaults SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore vault: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("vault not found")
+	}
+
+	// Sync clients dropped the entries together with the vault,
+	// so mark them as changed to have them fetched again
+	touchQuery := `UPDATE vault_entries SET change_seq = nextval('sync_change_seq') WHERE vault_id = $1`
+
+	if _, err := tx.ExecContext(ctx, touchQuery, id); err != nil {
+		return fmt.Errorf("failed to touch vault entries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit vault restore: %w", err)
+	}
+
+	return nil
+}
+
+// Purge permanently deletes a trashed vault (will cascade to entries)
+func (r *VaultRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM vaults WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to purge vault: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("vault not found")
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore permanently deletes all vaults trashed before the cutoff
+func (r *VaultRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `DELETE FROM vaults WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge vaults: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// CheckOwnership checks if a vault belongs to a user and is not in the trash
 func (r *VaultRepository) CheckOwnership(ctx context.Context, vaultID, userID uuid.UUID) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM vaults WHERE id = $1 AND user_id = $2)`
+	query := `SELECT EXISTS(SELECT 1 FROM vaults WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL)`
 
 	err := r.db.GetContext(ctx, &exists, query, vaultID, userID)
 	if err != nil {
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore stores opaque, client-encrypted blobs by key
+type BlobStore interface {
+	// Put stores exactly size bytes read from r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get opens the blob stored under key; callers must close the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key; missing blobs are not an error
+	Delete(ctx context.Context, key string) error
+}
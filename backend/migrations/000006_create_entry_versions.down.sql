@@ -0,0 +1,7 @@
+-- Drop table
+DROP TABLE IF EXISTS entry_versions;
+
+-- Drop columns
+ALTER TABLE vaults DROP CONSTRAINT IF EXISTS chk_version_retention;
+ALTER TABLE vaults DROP COLUMN IF EXISTS version_retention;
+ALTER TABLE vault_entries DROP COLUMN IF EXISTS version;
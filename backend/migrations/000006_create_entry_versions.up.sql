@@ -0,0 +1,24 @@
+-- Track the current revision number of each entry
+ALTER TABLE vault_entries ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- Number of revisions retained per entry, configurable per vault
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS version_retention INTEGER NOT NULL DEFAULT 10;
+ALTER TABLE vaults ADD CONSTRAINT chk_version_retention CHECK (version_retention BETWEEN 1 AND 100);
+
+-- Create entry_versions table
+CREATE TABLE IF NOT EXISTS entry_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID NOT NULL REFERENCES vault_entries(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    encrypted_data BYTEA NOT NULL,
+    nonce BYTEA NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_entry_versions_entry_version UNIQUE (entry_id, version),
+    CONSTRAINT chk_entry_versions_nonce_length CHECK (octet_length(nonce) = 12)
+);
+
+-- Record the current state of existing entries as their first revision
+INSERT INTO entry_versions (entry_id, version, encrypted_data, nonce, created_at)
+SELECT id, version, encrypted_data, nonce, updated_at
+FROM vault_entries;
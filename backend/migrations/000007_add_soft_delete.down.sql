@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_vault_entries_deleted_at;
+DROP INDEX IF EXISTS idx_vaults_deleted_at;
+
+-- Drop columns
+ALTER TABLE vault_entries DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE vaults DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,7 @@
+-- Add soft delete columns
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE vault_entries ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Create partial indexes for trash listing and scheduled purges
+CREATE INDEX idx_vaults_deleted_at ON vaults(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_vault_entries_deleted_at ON vault_entries(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,23 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS record_vault_entries_sync_tombstone ON vault_entries;
+DROP TRIGGER IF EXISTS record_vaults_sync_tombstone ON vaults;
+DROP TRIGGER IF EXISTS bump_vault_entries_change_seq ON vault_entries;
+DROP TRIGGER IF EXISTS bump_vaults_change_seq ON vaults;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS record_sync_tombstone();
+DROP FUNCTION IF EXISTS bump_change_seq();
+
+-- Drop table
+DROP TABLE IF EXISTS sync_tombstones;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_vault_entries_change_seq;
+DROP INDEX IF EXISTS idx_vaults_user_id_change_seq;
+
+-- Drop columns
+ALTER TABLE vault_entries DROP COLUMN IF EXISTS change_seq;
+ALTER TABLE vaults DROP COLUMN IF EXISTS change_seq;
+
+-- Drop sequence
+DROP SEQUENCE IF EXISTS sync_change_seq;
@@ -0,0 +1,70 @@
+-- Create a global sequence that orders all changes visible to sync clients
+CREATE SEQUENCE IF NOT EXISTS sync_change_seq;
+
+-- Add change sequence columns (existing rows receive initial values)
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT nextval('sync_change_seq');
+ALTER TABLE vault_entries ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT nextval('sync_change_seq');
+
+-- Create indexes for change lookups
+CREATE INDEX idx_vaults_user_id_change_seq ON vaults(user_id, change_seq);
+CREATE INDEX idx_vault_entries_change_seq ON vault_entries(change_seq);
+
+-- Create trigger to bump change_seq on every update (including soft delete and restore)
+CREATE OR REPLACE FUNCTION bump_change_seq()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.change_seq = nextval('sync_change_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bump_vaults_change_seq
+    BEFORE UPDATE ON vaults
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_change_seq();
+
+CREATE TRIGGER bump_vault_entries_change_seq
+    BEFORE UPDATE ON vault_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_change_seq();
+
+-- Create sync_tombstones table for permanently deleted rows.
+-- user_id has no foreign key so tombstones can be written while a user is deleted.
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    vault_id UUID NOT NULL,
+    entry_id UUID,
+    change_seq BIGINT NOT NULL DEFAULT nextval('sync_change_seq'),
+    deleted_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_sync_tombstones_user_id_change_seq ON sync_tombstones(user_id, change_seq);
+
+-- Create trigger to record tombstones on hard delete
+CREATE OR REPLACE FUNCTION record_sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'vaults' THEN
+        INSERT INTO sync_tombstones (user_id, vault_id)
+        VALUES (OLD.user_id, OLD.id);
+    ELSE
+        -- Entries removed by a vault cascade are covered by the vault tombstone
+        INSERT INTO sync_tombstones (user_id, vault_id, entry_id)
+        SELECT v.user_id, OLD.vault_id, OLD.id
+        FROM vaults v
+        WHERE v.id = OLD.vault_id;
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_vaults_sync_tombstone
+    AFTER DELETE ON vaults
+    FOR EACH ROW
+    EXECUTE FUNCTION record_sync_tombstone();
+
+CREATE TRIGGER record_vault_entries_sync_tombstone
+    AFTER DELETE ON vault_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION record_sync_tombstone();
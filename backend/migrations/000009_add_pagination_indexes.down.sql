@@ -0,0 +1,7 @@
+-- Drop keyset pagination indexes
+DROP INDEX IF EXISTS idx_audit_logs_user_timestamp_id;
+DROP INDEX IF EXISTS idx_vault_entries_vault_updated_id;
+DROP INDEX IF EXISTS idx_vault_entries_vault_created_id;
+DROP INDEX IF EXISTS idx_vaults_user_name_id;
+DROP INDEX IF EXISTS idx_vaults_user_updated_id;
+DROP INDEX IF EXISTS idx_vaults_user_created_id;
@@ -0,0 +1,7 @@
+-- Create composite indexes for keyset pagination of list endpoints
+CREATE INDEX idx_vaults_user_created_id ON vaults(user_id, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_vaults_user_updated_id ON vaults(user_id, updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_vaults_user_name_id ON vaults(user_id, name, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_vault_entries_vault_created_id ON vault_entries(vault_id, created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_vault_entries_vault_updated_id ON vault_entries(vault_id, updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_audit_logs_user_timestamp_id ON audit_logs(user_id, timestamp, id);
@@ -0,0 +1,2 @@
+-- Drop key generation counter
+ALTER TABLE vaults DROP COLUMN IF EXISTS key_generation;
@@ -0,0 +1,2 @@
+-- Add key generation counter, bumped on every vault key rotation
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS key_generation INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- Drop client key derivation settings
+ALTER TABLE vaults DROP COLUMN IF EXISTS kdf_params;
+ALTER TABLE vaults DROP COLUMN IF EXISTS kdf_algorithm;
@@ -0,0 +1,4 @@
+-- Add client key derivation settings; existing vaults use the former frontend default
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS kdf_algorithm VARCHAR(32) NOT NULL DEFAULT 'pbkdf2-sha256'
+    CHECK (kdf_algorithm IN ('pbkdf2-sha256', 'argon2id'));
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS kdf_params JSONB NOT NULL DEFAULT '{"iterations": 600000}';
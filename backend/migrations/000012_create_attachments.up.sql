@@ -0,0 +1,21 @@
+-- Create attachments table
+-- entry_id is cleared when the entry is purged; the trash purge job then
+-- deletes the blob and the row.
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID REFERENCES vault_entries(id) ON DELETE SET NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    encrypted_name BYTEA NOT NULL,
+    nonce BYTEA NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_attachments_nonce_length CHECK (octet_length(nonce) = 12),
+    CONSTRAINT chk_attachments_size CHECK (size > 0)
+);
+
+-- Create indexes
+CREATE INDEX idx_attachments_entry_id ON attachments(entry_id);
+CREATE INDEX idx_attachments_user_id ON attachments(user_id);
+CREATE INDEX idx_attachments_orphaned ON attachments(created_at) WHERE entry_id IS NULL OR completed_at IS NULL;
@@ -0,0 +1,10 @@
+-- Drop organisational columns
+DROP INDEX IF EXISTS idx_vault_entries_favorite;
+DROP INDEX IF EXISTS idx_vault_entries_folder_id;
+ALTER TABLE vault_entries DROP COLUMN IF EXISTS favorite;
+ALTER TABLE vault_entries DROP COLUMN IF EXISTS folder_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS entry_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS folders;
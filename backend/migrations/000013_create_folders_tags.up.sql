@@ -0,0 +1,44 @@
+-- Create folders table (tree per vault, names encrypted client-side)
+CREATE TABLE IF NOT EXISTS folders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    vault_id UUID NOT NULL REFERENCES vaults(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES folders(id) ON DELETE CASCADE,
+    encrypted_name BYTEA NOT NULL,
+    nonce BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_folders_nonce_length CHECK (octet_length(nonce) = 12),
+    CONSTRAINT chk_folders_not_own_parent CHECK (parent_id <> id)
+);
+
+CREATE INDEX idx_folders_vault_id ON folders(vault_id);
+CREATE INDEX idx_folders_parent_id ON folders(parent_id);
+
+-- Create tags table; blind_id is a client-computed keyed hash of the tag name
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    vault_id UUID NOT NULL REFERENCES vaults(id) ON DELETE CASCADE,
+    blind_id BYTEA NOT NULL,
+    encrypted_name BYTEA,
+    nonce BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_tags_vault_blind_id UNIQUE (vault_id, blind_id),
+    CONSTRAINT chk_tags_name_nonce CHECK ((encrypted_name IS NULL) = (nonce IS NULL)),
+    CONSTRAINT chk_tags_nonce_length CHECK (nonce IS NULL OR octet_length(nonce) = 12)
+);
+
+-- Create entry_tags join table
+CREATE TABLE IF NOT EXISTS entry_tags (
+    entry_id UUID NOT NULL REFERENCES vault_entries(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (entry_id, tag_id)
+);
+
+CREATE INDEX idx_entry_tags_tag_id ON entry_tags(tag_id);
+
+-- Add organisational columns to entries
+ALTER TABLE vault_entries ADD COLUMN IF NOT EXISTS folder_id UUID REFERENCES folders(id) ON DELETE SET NULL;
+ALTER TABLE vault_entries ADD COLUMN IF NOT EXISTS favorite BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_vault_entries_folder_id ON vault_entries(folder_id) WHERE folder_id IS NOT NULL;
+CREATE INDEX idx_vault_entries_favorite ON vault_entries(vault_id) WHERE favorite AND deleted_at IS NULL;
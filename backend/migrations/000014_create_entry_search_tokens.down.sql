@@ -0,0 +1,2 @@
+-- Drop entry_search_tokens table
+DROP TABLE IF EXISTS entry_search_tokens;
@@ -0,0 +1,11 @@
+-- Create entry_search_tokens table; tokens are client-computed HMACs keyed by a
+-- search key the server never sees
+CREATE TABLE IF NOT EXISTS entry_search_tokens (
+    entry_id UUID NOT NULL REFERENCES vault_entries(id) ON DELETE CASCADE,
+    vault_id UUID NOT NULL REFERENCES vaults(id) ON DELETE CASCADE,
+    token BYTEA NOT NULL,
+    PRIMARY KEY (entry_id, token),
+    CONSTRAINT chk_entry_search_tokens_length CHECK (octet_length(token) = 32)
+);
+
+CREATE INDEX idx_entry_search_tokens_vault_token ON entry_search_tokens(vault_id, token);
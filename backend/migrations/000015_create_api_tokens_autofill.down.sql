@@ -0,0 +1,3 @@
+-- Drop tables
+DROP TABLE IF EXISTS entry_autofill_hashes;
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,27 @@
+-- Create api_tokens table; only the SHA-256 hash of a token is stored
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash BYTEA NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_api_tokens_scopes CHECK (cardinality(scopes) > 0)
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- Create entry_autofill_hashes table; hashes are client-computed keyed hashes
+-- of the registrable domain (eTLD+1) of the entry's URLs
+CREATE TABLE IF NOT EXISTS entry_autofill_hashes (
+    entry_id UUID NOT NULL REFERENCES vault_entries(id) ON DELETE CASCADE,
+    vault_id UUID NOT NULL REFERENCES vaults(id) ON DELETE CASCADE,
+    domain_hash BYTEA NOT NULL,
+    PRIMARY KEY (entry_id, domain_hash),
+    CONSTRAINT chk_entry_autofill_hashes_length CHECK (octet_length(domain_hash) = 32)
+);
+
+CREATE INDEX idx_entry_autofill_hashes_domain_hash ON entry_autofill_hashes(domain_hash);
@@ -0,0 +1,2 @@
+-- Drop shares table
+DROP TABLE IF EXISTS shares;
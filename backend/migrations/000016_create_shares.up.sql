@@ -0,0 +1,22 @@
+-- Create shares table for one-time share links ("Send")
+-- The decryption key lives only in the link's URL fragment. encrypted_data is
+-- cleared once a share is revoked or its last view is used; the share purge
+-- job deletes rows after expiry.
+CREATE TABLE IF NOT EXISTS shares (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    encrypted_data BYTEA,
+    nonce BYTEA NOT NULL,
+    password_hash TEXT,
+    max_views INTEGER,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT chk_shares_nonce_length CHECK (octet_length(nonce) = 12),
+    CONSTRAINT chk_shares_max_views CHECK (max_views IS NULL OR max_views > 0)
+);
+
+-- Create indexes
+CREATE INDEX idx_shares_user_id ON shares(user_id);
+CREATE INDEX idx_shares_expires_at ON shares(expires_at);
@@ -0,0 +1,2 @@
+-- Drop emergency_access table
+DROP TABLE IF EXISTS emergency_access;
@@ -0,0 +1,23 @@
+-- Create emergency_access table
+-- wrapped_key is an opaque blob holding the grantor's vault keys wrapped for
+-- the grantee client-side; it is only released once status is recovery_approved.
+CREATE TABLE IF NOT EXISTS emergency_access (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    grantor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    grantee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL DEFAULT 'invited',
+    wait_days INTEGER NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    recovery_requested_at TIMESTAMP WITH TIME ZONE,
+    recovery_approved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT uq_emergency_access_pair UNIQUE (grantor_id, grantee_id),
+    CONSTRAINT chk_emergency_access_not_self CHECK (grantor_id <> grantee_id),
+    CONSTRAINT chk_emergency_access_status CHECK (status IN ('invited', 'accepted', 'recovery_requested', 'recovery_approved')),
+    CONSTRAINT chk_emergency_access_wait_days CHECK (wait_days BETWEEN 1 AND 90)
+);
+
+-- Create indexes
+CREATE INDEX idx_emergency_access_grantee_id ON emergency_access(grantee_id);
+CREATE INDEX idx_emergency_access_pending ON emergency_access(recovery_requested_at) WHERE status = 'recovery_requested';
@@ -0,0 +1,12 @@
+-- Drop usage counter triggers
+DROP TRIGGER IF EXISTS count_vault_entries_usage ON vault_entries;
+DROP TRIGGER IF EXISTS count_vaults_delete_usage ON vaults;
+DROP TRIGGER IF EXISTS count_vaults_insert_usage ON vaults;
+DROP TRIGGER IF EXISTS create_users_usage ON users;
+DROP FUNCTION IF EXISTS count_entry_usage();
+DROP FUNCTION IF EXISTS count_vault_usage();
+DROP FUNCTION IF EXISTS create_user_usage();
+
+-- Drop usage counter tables
+DROP TABLE IF EXISTS vault_usage;
+DROP TABLE IF EXISTS user_usage;
@@ -0,0 +1,108 @@
+-- Create usage counter tables.
+-- Counters include trashed vaults and entries; they are released on purge.
+CREATE TABLE IF NOT EXISTS user_usage (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    vault_count INTEGER NOT NULL DEFAULT 0,
+    entry_bytes BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS vault_usage (
+    vault_id UUID PRIMARY KEY REFERENCES vaults(id) ON DELETE CASCADE,
+    entry_count INTEGER NOT NULL DEFAULT 0
+);
+
+-- Backfill counters for existing data
+INSERT INTO user_usage (user_id, vault_count, entry_bytes)
+SELECT u.id,
+       (SELECT COUNT(*) FROM vaults v WHERE v.user_id = u.id),
+       (SELECT COALESCE(SUM(octet_length(e.encrypted_data)), 0)
+        FROM vault_entries e JOIN vaults v ON v.id = e.vault_id
+        WHERE v.user_id = u.id)
+FROM users u;
+
+INSERT INTO vault_usage (vault_id, entry_count)
+SELECT v.id, (SELECT COUNT(*) FROM vault_entries e WHERE e.vault_id = v.id)
+FROM vaults v;
+
+-- Create trigger to start counting for new users
+CREATE OR REPLACE FUNCTION create_user_usage()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO user_usage (user_id) VALUES (NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER create_users_usage
+    AFTER INSERT ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION create_user_usage();
+
+-- Create trigger to count vaults.
+-- Deletion runs before the row is removed so the bytes of the cascaded
+-- entries can be released here; their own triggers no longer see the vault.
+CREATE OR REPLACE FUNCTION count_vault_usage()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE user_usage SET vault_count = vault_count + 1 WHERE user_id = NEW.user_id;
+        INSERT INTO vault_usage (vault_id) VALUES (NEW.id);
+        RETURN NEW;
+    END IF;
+
+    UPDATE user_usage
+    SET vault_count = vault_count - 1,
+        entry_bytes = entry_bytes - (
+            SELECT COALESCE(SUM(octet_length(encrypted_data)), 0)
+            FROM vault_entries WHERE vault_id = OLD.id
+        )
+    WHERE user_id = OLD.user_id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER count_vaults_insert_usage
+    AFTER INSERT ON vaults
+    FOR EACH ROW
+    EXECUTE FUNCTION count_vault_usage();
+
+CREATE TRIGGER count_vaults_delete_usage
+    BEFORE DELETE ON vaults
+    FOR EACH ROW
+    EXECUTE FUNCTION count_vault_usage();
+
+-- Create trigger to count entries and their ciphertext bytes
+CREATE OR REPLACE FUNCTION count_entry_usage()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE user_usage u
+        SET entry_bytes = u.entry_bytes - octet_length(OLD.encrypted_data)
+        FROM vaults v
+        WHERE v.id = OLD.vault_id AND u.user_id = v.user_id;
+
+        IF TG_OP = 'DELETE' OR OLD.vault_id <> NEW.vault_id THEN
+            UPDATE vault_usage SET entry_count = entry_count - 1 WHERE vault_id = OLD.vault_id;
+        END IF;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE user_usage u
+        SET entry_bytes = u.entry_bytes + octet_length(NEW.encrypted_data)
+        FROM vaults v
+        WHERE v.id = NEW.vault_id AND u.user_id = v.user_id;
+
+        IF TG_OP = 'INSERT' OR OLD.vault_id <> NEW.vault_id THEN
+            UPDATE vault_usage SET entry_count = entry_count + 1 WHERE vault_id = NEW.vault_id;
+        END IF;
+        RETURN NEW;
+    END IF;
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER count_vault_entries_usage
+    AFTER INSERT OR DELETE OR UPDATE OF vault_id, encrypted_data ON vault_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION count_entry_usage();
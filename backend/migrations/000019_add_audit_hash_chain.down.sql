@@ -0,0 +1,15 @@
+-- Drop administrator flag
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
+
+-- Drop audit_checkpoints table
+DROP TABLE IF EXISTS audit_checkpoints;
+
+-- Restore the user foreign key; logs of deleted users lose their user ID
+UPDATE audit_logs SET user_id = NULL
+WHERE user_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = audit_logs.user_id);
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Drop hash chain columns
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS prev_hash;
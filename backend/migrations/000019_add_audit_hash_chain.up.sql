@@ -0,0 +1,25 @@
+-- Add hash chain columns to audit_logs.
+-- hash covers the canonical row content and prev_hash, the hash of the previous
+-- chained row. Rows written before this migration stay unchained (NULL).
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash BYTEA;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash BYTEA;
+
+-- Drop the user foreign key: ON DELETE SET NULL would rewrite chained rows.
+-- Audit logs keep the ID of deleted users, like sync_tombstones.
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_user_id_fkey;
+
+-- Create audit_checkpoints table holding signed chain heads
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id BIGSERIAL PRIMARY KEY,
+    log_id BIGINT NOT NULL,
+    hash BYTEA NOT NULL,
+    signature BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT chk_audit_checkpoint_hash_length CHECK (octet_length(hash) = 32),
+    CONSTRAINT chk_audit_checkpoint_signature_length CHECK (octet_length(signature) = 64)
+);
+
+CREATE INDEX idx_audit_checkpoints_log_id ON audit_checkpoints(log_id);
+
+-- Add administrator flag to users (grant with: UPDATE users SET is_admin = TRUE WHERE email = '...')
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
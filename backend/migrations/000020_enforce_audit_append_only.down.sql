@@ -0,0 +1,6 @@
+-- Drop append-only triggers
+DROP TRIGGER IF EXISTS reject_audit_checkpoints_truncate ON audit_checkpoints;
+DROP TRIGGER IF EXISTS reject_audit_checkpoints_change ON audit_checkpoints;
+DROP TRIGGER IF EXISTS reject_audit_logs_truncate ON audit_logs;
+DROP TRIGGER IF EXISTS reject_audit_logs_change ON audit_logs;
+DROP FUNCTION IF EXISTS reject_audit_change();
@@ -0,0 +1,37 @@
+-- Create trigger to make audit_logs and audit_checkpoints append-only.
+-- UPDATE and TRUNCATE are always rejected. DELETE is only allowed for members
+-- of the pwmanager_maintenance role (created by "migrate roles"), which runs
+-- retention purges through the maintenance command.
+CREATE OR REPLACE FUNCTION reject_audit_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pwmanager_maintenance') THEN
+        IF pg_has_role(current_user, 'pwmanager_maintenance', 'MEMBER') THEN
+            RETURN OLD;
+        END IF;
+    END IF;
+
+    RAISE EXCEPTION '% is append-only: % rejected', TG_TABLE_NAME, TG_OP
+        USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reject_audit_logs_change
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_audit_change();
+
+CREATE TRIGGER reject_audit_logs_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION reject_audit_change();
+
+CREATE TRIGGER reject_audit_checkpoints_change
+    BEFORE UPDATE OR DELETE ON audit_checkpoints
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_audit_change();
+
+CREATE TRIGGER reject_audit_checkpoints_truncate
+    BEFORE TRUNCATE ON audit_checkpoints
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION reject_audit_change();
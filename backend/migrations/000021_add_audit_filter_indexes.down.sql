@@ -0,0 +1,5 @@
+-- Drop audit log filter indexes
+DROP INDEX IF EXISTS idx_audit_logs_ip_address;
+DROP INDEX IF EXISTS idx_audit_logs_user_action_timestamp;
+DROP INDEX IF EXISTS idx_audit_logs_user_entry_timestamp_id;
+DROP INDEX IF EXISTS idx_audit_logs_user_vault_timestamp_id;
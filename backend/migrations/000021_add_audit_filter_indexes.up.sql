@@ -0,0 +1,12 @@
+-- Create indexes for filtered audit log queries.
+-- Detail filters compare the extracted text, so the expression indexes match
+-- details->>'vault_id' = $n and keep the keyset order within each value.
+CREATE INDEX idx_audit_logs_user_vault_timestamp_id ON audit_logs(user_id, (details->>'vault_id'), timestamp, id);
+CREATE INDEX idx_audit_logs_user_entry_timestamp_id ON audit_logs(user_id, (details->>'entry_id'), timestamp, id);
+
+-- Action prefix filters (vault.*) use LIKE 'vault.%', which needs pattern operators;
+-- the same index serves exact action matches
+CREATE INDEX idx_audit_logs_user_action_timestamp ON audit_logs(user_id, action varchar_pattern_ops, timestamp);
+
+-- IP filters match an address or network with <<=, which needs a GiST index
+CREATE INDEX idx_audit_logs_ip_address ON audit_logs USING gist (ip_address inet_ops);
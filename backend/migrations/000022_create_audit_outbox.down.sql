@@ -0,0 +1,6 @@
+-- Drop audit outbox trigger and tables
+DROP TRIGGER IF EXISTS enqueue_audit_log ON audit_logs;
+DROP FUNCTION IF EXISTS enqueue_audit_log();
+DROP INDEX IF EXISTS idx_audit_outbox_sink_log_id;
+DROP TABLE IF EXISTS audit_outbox;
+DROP TABLE IF EXISTS audit_sinks;
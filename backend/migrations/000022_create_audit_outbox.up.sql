@@ -0,0 +1,36 @@
+-- Create audit_sinks table registering the external sinks audit logs are forwarded to.
+-- The API replaces the registrations with its configured sinks on startup.
+CREATE TABLE IF NOT EXISTS audit_sinks (
+    name VARCHAR(50) PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Create audit_outbox table holding one pending delivery per audit log and sink.
+-- Rows are deleted once delivered; failed deliveries are retried with backoff.
+CREATE TABLE IF NOT EXISTS audit_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    log_id BIGINT NOT NULL,
+    sink VARCHAR(50) NOT NULL REFERENCES audit_sinks(name) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_outbox_sink_log_id ON audit_outbox(sink, log_id);
+
+-- Create trigger to enqueue every new audit log for each registered sink in
+-- the same transaction, so no event is lost when a sink is unavailable
+CREATE OR REPLACE FUNCTION enqueue_audit_log()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO audit_outbox (log_id, sink)
+    SELECT NEW.id, name FROM audit_sinks;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER enqueue_audit_log
+    AFTER INSERT ON audit_logs
+    FOR EACH ROW
+    EXECUTE FUNCTION enqueue_audit_log();
@@ -0,0 +1,52 @@
+-- Convert audit_logs back into a single table
+ALTER TABLE audit_logs RENAME TO audit_logs_partitioned;
+ALTER SEQUENCE audit_logs_id_seq OWNED BY NONE;
+
+CREATE TABLE audit_logs (
+    id BIGINT NOT NULL DEFAULT nextval('audit_logs_id_seq'),
+    user_id UUID,
+    action VARCHAR(100) NOT NULL,
+    ip_address INET,
+    user_agent TEXT,
+    details JSONB,
+    timestamp TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    prev_hash BYTEA,
+    hash BYTEA
+);
+
+INSERT INTO audit_logs (id, user_id, action, ip_address, user_agent, details, timestamp, prev_hash, hash)
+SELECT id, user_id, action, ip_address, user_agent, details, timestamp, prev_hash, hash
+FROM audit_logs_partitioned;
+
+DROP TABLE audit_logs_partitioned;
+ALTER SEQUENCE audit_logs_id_seq OWNED BY audit_logs.id;
+
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id);
+
+CREATE INDEX idx_audit_logs_user_id ON audit_logs(user_id);
+CREATE INDEX idx_audit_logs_timestamp ON audit_logs(timestamp DESC);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX idx_audit_logs_user_timestamp_id ON audit_logs(user_id, timestamp, id);
+CREATE INDEX idx_audit_logs_user_vault_timestamp_id ON audit_logs(user_id, (details->>'vault_id'), timestamp, id);
+CREATE INDEX idx_audit_logs_user_entry_timestamp_id ON audit_logs(user_id, (details->>'entry_id'), timestamp, id);
+CREATE INDEX idx_audit_logs_user_action_timestamp ON audit_logs(user_id, action varchar_pattern_ops, timestamp);
+CREATE INDEX idx_audit_logs_ip_address ON audit_logs USING gist (ip_address inet_ops);
+
+CREATE TRIGGER reject_audit_logs_change
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_audit_change();
+
+CREATE TRIGGER reject_audit_logs_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION reject_audit_change();
+
+CREATE TRIGGER enqueue_audit_log
+    AFTER INSERT ON audit_logs
+    FOR EACH ROW
+    EXECUTE FUNCTION enqueue_audit_log();
+
+-- Drop partition management functions
+DROP FUNCTION IF EXISTS drop_audit_log_partition(TEXT);
+DROP FUNCTION IF EXISTS create_audit_log_partition(DATE);
@@ -0,0 +1,113 @@
+-- Convert audit_logs into a table range-partitioned by month on timestamp, so
+-- retention drops whole partitions instead of deleting rows.
+-- The primary key must include the partition key, so timestamp becomes NOT NULL;
+-- it has always been set on insert.
+
+-- Create function creating the partition for the month containing month.
+-- Partitions are named audit_logs_YYYY_MM and cover a UTC calendar month.
+-- SECURITY DEFINER lets the API create partitions ahead of time without owning audit_logs.
+CREATE OR REPLACE FUNCTION create_audit_log_partition(month DATE)
+RETURNS BOOLEAN AS $$
+DECLARE
+    start_at DATE := date_trunc('month', month)::date;
+    partition_name TEXT := 'audit_logs_' || to_char(start_at, 'YYYY_MM');
+BEGIN
+    -- Serialise concurrent callers, e.g. several API instances
+    PERFORM pg_advisory_xact_lock(hashtext('create_audit_log_partition'));
+
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN FALSE;
+    END IF;
+
+    EXECUTE format('CREATE TABLE %I PARTITION OF audit_logs FOR VALUES FROM (%L) TO (%L)',
+        partition_name, start_at::text || ' 00:00:00+00', (start_at + INTERVAL '1 month')::date::text || ' 00:00:00+00');
+    RETURN TRUE;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- Create function dropping an audit_logs partition for retention purges.
+-- Like deletes, this is only allowed for members of the pwmanager_maintenance role.
+CREATE OR REPLACE FUNCTION drop_audit_log_partition(partition_name TEXT)
+RETURNS VOID AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pwmanager_maintenance')
+        AND NOT pg_has_role(session_user, 'pwmanager_maintenance', 'MEMBER') THEN
+        RAISE EXCEPTION 'audit_logs is append-only: DROP PARTITION rejected'
+            USING ERRCODE = 'insufficient_privilege';
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_inherits
+        WHERE inhparent = 'audit_logs'::regclass AND inhrelid = to_regclass(partition_name)
+    ) THEN
+        RAISE EXCEPTION '% is not a partition of audit_logs', partition_name;
+    END IF;
+
+    EXECUTE format('DROP TABLE %I', partition_name);
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public;
+
+-- EXECUTE is granted to the API and maintenance roles by "migrate roles"
+REVOKE ALL ON FUNCTION create_audit_log_partition(DATE) FROM PUBLIC;
+REVOKE ALL ON FUNCTION drop_audit_log_partition(TEXT) FROM PUBLIC;
+
+-- Keep the ID sequence; it continues in the partitioned table
+ALTER TABLE audit_logs RENAME TO audit_logs_unpartitioned;
+ALTER SEQUENCE audit_logs_id_seq OWNED BY NONE;
+
+CREATE TABLE audit_logs (
+    id BIGINT NOT NULL DEFAULT nextval('audit_logs_id_seq'),
+    user_id UUID,
+    action VARCHAR(100) NOT NULL,
+    ip_address INET,
+    user_agent TEXT,
+    details JSONB,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    prev_hash BYTEA,
+    hash BYTEA
+) PARTITION BY RANGE (timestamp);
+
+-- Create partitions for the existing logs and the next three months
+SELECT create_audit_log_partition(month::date)
+FROM generate_series(
+    date_trunc('month', COALESCE((SELECT MIN(timestamp) FROM audit_logs_unpartitioned), NOW()) AT TIME ZONE 'UTC'),
+    date_trunc('month', NOW() AT TIME ZONE 'UTC') + INTERVAL '3 months',
+    INTERVAL '1 month'
+) AS month;
+
+-- Copy the logs before creating the triggers so they are not enqueued again
+INSERT INTO audit_logs (id, user_id, action, ip_address, user_agent, details, timestamp, prev_hash, hash)
+SELECT id, user_id, action, ip_address, user_agent, details, timestamp, prev_hash, hash
+FROM audit_logs_unpartitioned;
+
+DROP TABLE audit_logs_unpartitioned;
+ALTER SEQUENCE audit_logs_id_seq OWNED BY audit_logs.id;
+
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id, timestamp);
+
+-- Recreate the indexes of migrations 000005, 000009 and 000021 on the partitioned table
+CREATE INDEX idx_audit_logs_user_id ON audit_logs(user_id);
+CREATE INDEX idx_audit_logs_timestamp ON audit_logs(timestamp DESC);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX idx_audit_logs_user_timestamp_id ON audit_logs(user_id, timestamp, id);
+CREATE INDEX idx_audit_logs_user_vault_timestamp_id ON audit_logs(user_id, (details->>'vault_id'), timestamp, id);
+CREATE INDEX idx_audit_logs_user_entry_timestamp_id ON audit_logs(user_id, (details->>'entry_id'), timestamp, id);
+CREATE INDEX idx_audit_logs_user_action_timestamp ON audit_logs(user_id, action varchar_pattern_ops, timestamp);
+CREATE INDEX idx_audit_logs_ip_address ON audit_logs USING gist (ip_address inet_ops);
+
+-- Recreate the append-only and outbox triggers of migrations 000020 and 000022;
+-- row triggers are cloned to every partition
+CREATE TRIGGER reject_audit_logs_change
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW
+    EXECUTE FUNCTION reject_audit_change();
+
+CREATE TRIGGER reject_audit_logs_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION reject_audit_change();
+
+CREATE TRIGGER enqueue_audit_log
+    AFTER INSERT ON audit_logs
+    FOR EACH ROW
+    EXECUTE FUNCTION enqueue_audit_log();
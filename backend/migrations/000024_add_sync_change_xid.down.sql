@@ -0,0 +1,21 @@
+-- Restore the change_seq trigger without transaction stamps
+CREATE OR REPLACE FUNCTION bump_change_seq()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.change_seq = nextval('sync_change_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Restore the change_seq indexes
+DROP INDEX IF EXISTS idx_sync_tombstones_user_id_change_xid_seq;
+DROP INDEX IF EXISTS idx_vault_entries_change_xid_seq;
+DROP INDEX IF EXISTS idx_vaults_user_id_change_xid_seq;
+CREATE INDEX idx_vaults_user_id_change_seq ON vaults(user_id, change_seq);
+CREATE INDEX idx_vault_entries_change_seq ON vault_entries(change_seq);
+CREATE INDEX idx_sync_tombstones_user_id_change_seq ON sync_tombstones(user_id, change_seq);
+
+-- Drop columns
+ALTER TABLE sync_tombstones DROP COLUMN IF EXISTS change_xid;
+ALTER TABLE vault_entries DROP COLUMN IF EXISTS change_xid;
+ALTER TABLE vaults DROP COLUMN IF EXISTS change_xid;
@@ -0,0 +1,30 @@
+-- Record the transaction that made each change. change_seq is taken before
+-- commit, so a slow transaction can commit a lower change_seq after a client
+-- synced past it. Sync only hands out changes of transactions that finished
+-- before the oldest running one, ordered by (change_xid, change_seq), so a
+-- change can no longer appear behind a cursor.
+-- Existing rows are marked as changed by a transaction that has long finished.
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS change_xid xid8 NOT NULL DEFAULT '0';
+ALTER TABLE vaults ALTER COLUMN change_xid SET DEFAULT pg_current_xact_id();
+ALTER TABLE vault_entries ADD COLUMN IF NOT EXISTS change_xid xid8 NOT NULL DEFAULT '0';
+ALTER TABLE vault_entries ALTER COLUMN change_xid SET DEFAULT pg_current_xact_id();
+ALTER TABLE sync_tombstones ADD COLUMN IF NOT EXISTS change_xid xid8 NOT NULL DEFAULT '0';
+ALTER TABLE sync_tombstones ALTER COLUMN change_xid SET DEFAULT pg_current_xact_id();
+
+-- Replace the change_seq indexes with indexes in sync order
+DROP INDEX IF EXISTS idx_vaults_user_id_change_seq;
+DROP INDEX IF EXISTS idx_vault_entries_change_seq;
+DROP INDEX IF EXISTS idx_sync_tombstones_user_id_change_seq;
+CREATE INDEX idx_vaults_user_id_change_xid_seq ON vaults(user_id, change_xid, change_seq);
+CREATE INDEX idx_vault_entries_change_xid_seq ON vault_entries(change_xid, change_seq);
+CREATE INDEX idx_sync_tombstones_user_id_change_xid_seq ON sync_tombstones(user_id, change_xid, change_seq);
+
+-- Stamp the transaction on every update (including soft delete and restore)
+CREATE OR REPLACE FUNCTION bump_change_seq()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.change_xid = pg_current_xact_id();
+    NEW.change_seq = nextval('sync_change_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,32 @@
+-- Drop revision and attachment usage triggers
+DROP TRIGGER IF EXISTS count_attachments_usage ON attachments;
+DROP TRIGGER IF EXISTS count_entry_versions_usage ON entry_versions;
+DROP TRIGGER IF EXISTS release_vault_entries_version_usage ON vault_entries;
+DROP FUNCTION IF EXISTS count_attachment_usage();
+DROP FUNCTION IF EXISTS count_version_usage();
+DROP FUNCTION IF EXISTS release_entry_version_usage();
+
+-- Restore vault counting without revision bytes
+CREATE OR REPLACE FUNCTION count_vault_usage()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE user_usage SET vault_count = vault_count + 1 WHERE user_id = NEW.user_id;
+        INSERT INTO vault_usage (vault_id) VALUES (NEW.id);
+        RETURN NEW;
+    END IF;
+
+    UPDATE user_usage
+    SET vault_count = vault_count - 1,
+        entry_bytes = entry_bytes - (
+            SELECT COALESCE(SUM(octet_length(encrypted_data)), 0)
+            FROM vault_entries WHERE vault_id = OLD.id
+        )
+    WHERE user_id = OLD.user_id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE user_usage
+    DROP COLUMN IF EXISTS attachment_bytes,
+    DROP COLUMN IF EXISTS version_bytes;
@@ -0,0 +1,122 @@
+-- Count entry revisions and attachments in the per-user storage usage.
+-- Like entries, revisions of trashed entries and attachments of purged
+-- entries count until their rows are deleted.
+ALTER TABLE user_usage
+    ADD COLUMN IF NOT EXISTS version_bytes BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS attachment_bytes BIGINT NOT NULL DEFAULT 0;
+
+-- Backfill counters for existing data
+UPDATE user_usage u
+SET version_bytes = (
+        SELECT COALESCE(SUM(octet_length(ev.encrypted_data)), 0)
+        FROM entry_versions ev
+        JOIN vault_entries e ON e.id = ev.entry_id
+        JOIN vaults v ON v.id = e.vault_id
+        WHERE v.user_id = u.user_id
+    ),
+    attachment_bytes = (
+        SELECT COALESCE(SUM(a.size), 0)
+        FROM attachments a
+        WHERE a.user_id = u.user_id
+    );
+
+-- Release the revision bytes of the cascaded entries on vault deletion too
+CREATE OR REPLACE FUNCTION count_vault_usage()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE user_usage SET vault_count = vault_count + 1 WHERE user_id = NEW.user_id;
+        INSERT INTO vault_usage (vault_id) VALUES (NEW.id);
+        RETURN NEW;
+    END IF;
+
+    UPDATE user_usage
+    SET vault_count = vault_count - 1,
+        entry_bytes = entry_bytes - (
+            SELECT COALESCE(SUM(octet_length(encrypted_data)), 0)
+            FROM vault_entries WHERE vault_id = OLD.id
+        ),
+        version_bytes = version_bytes - (
+            SELECT COALESCE(SUM(octet_length(ev.encrypted_data)), 0)
+            FROM entry_versions ev
+            JOIN vault_entries e ON e.id = ev.entry_id
+            WHERE e.vault_id = OLD.id
+        )
+    WHERE user_id = OLD.user_id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create trigger to release the revision bytes of a deleted entry.
+-- Like vault deletion it runs before the row is removed, since the triggers
+-- of the cascaded revisions no longer see the entry.
+CREATE OR REPLACE FUNCTION release_entry_version_usage()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE user_usage u
+    SET version_bytes = u.version_bytes - (
+            SELECT COALESCE(SUM(octet_length(encrypted_data)), 0)
+            FROM entry_versions WHERE entry_id = OLD.id
+        )
+    FROM vaults v
+    WHERE v.id = OLD.vault_id AND u.user_id = v.user_id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER release_vault_entries_version_usage
+    BEFORE DELETE ON vault_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION release_entry_version_usage();
+
+-- Create trigger to count revision bytes
+CREATE OR REPLACE FUNCTION count_version_usage()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE user_usage u
+        SET version_bytes = u.version_bytes - octet_length(OLD.encrypted_data)
+        FROM vault_entries e
+        JOIN vaults v ON v.id = e.vault_id
+        WHERE e.id = OLD.entry_id AND u.user_id = v.user_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE user_usage u
+        SET version_bytes = u.version_bytes + octet_length(NEW.encrypted_data)
+        FROM vault_entries e
+        JOIN vaults v ON v.id = e.vault_id
+        WHERE e.id = NEW.entry_id AND u.user_id = v.user_id;
+        RETURN NEW;
+    END IF;
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER count_entry_versions_usage
+    AFTER INSERT OR DELETE OR UPDATE OF encrypted_data ON entry_versions
+    FOR EACH ROW
+    EXECUTE FUNCTION count_version_usage();
+
+-- Create trigger to count attachment bytes, including uploads in progress
+CREATE OR REPLACE FUNCTION count_attachment_usage()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE user_usage SET attachment_bytes = attachment_bytes - OLD.size WHERE user_id = OLD.user_id;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE user_usage SET attachment_bytes = attachment_bytes + NEW.size WHERE user_id = NEW.user_id;
+        RETURN NEW;
+    END IF;
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER count_attachments_usage
+    AFTER INSERT OR DELETE OR UPDATE OF user_id, size ON attachments
+    FOR EACH ROW
+    EXECUTE FUNCTION count_attachment_usage();
@@ -0,0 +1,22 @@
+-- Let the API create audit_logs partitions again
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pwmanager_app') THEN
+        GRANT EXECUTE ON FUNCTION create_audit_log_partition(DATE) TO pwmanager_app;
+    END IF;
+END $$;
+
+-- Restore the append-only trigger of migration 000020
+CREATE OR REPLACE FUNCTION reject_audit_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pwmanager_maintenance') THEN
+        IF pg_has_role(current_user, 'pwmanager_maintenance', 'MEMBER') THEN
+            RETURN OLD;
+        END IF;
+    END IF;
+
+    RAISE EXCEPTION '% is append-only: % rejected', TG_TABLE_NAME, TG_OP
+        USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,38 @@
+-- Check the session user instead of the current user in the append-only
+-- trigger. Inside a SECURITY DEFINER function current_user is the function
+-- owner, so any caller of such a function deleting from audit_logs passed the
+-- check of migration 000020; session_user is the role that logged in, as
+-- already checked by drop_audit_log_partition.
+-- Superusers and the table owner can still disable the triggers or drop the
+-- partitions directly; the checks only bind the least-privilege roles of
+-- "migrate roles", so the API must not connect as either.
+CREATE OR REPLACE FUNCTION reject_audit_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pwmanager_maintenance') THEN
+        IF pg_has_role(session_user, 'pwmanager_maintenance', 'MEMBER') THEN
+            RETURN OLD;
+        END IF;
+    END IF;
+
+    RAISE EXCEPTION '% is append-only: % rejected', TG_TABLE_NAME, TG_OP
+        USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+-- Only the maintenance role manages audit_logs partitions; the API no longer
+-- creates them ahead of time (see the partition-audit maintenance command)
+REVOKE ALL ON FUNCTION create_audit_log_partition(DATE) FROM PUBLIC;
+REVOKE ALL ON FUNCTION drop_audit_log_partition(TEXT) FROM PUBLIC;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pwmanager_app') THEN
+        REVOKE ALL ON FUNCTION create_audit_log_partition(DATE) FROM pwmanager_app;
+        REVOKE ALL ON FUNCTION drop_audit_log_partition(TEXT) FROM pwmanager_app;
+    END IF;
+    IF EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'pwmanager_maintenance') THEN
+        GRANT EXECUTE ON FUNCTION create_audit_log_partition(DATE) TO pwmanager_maintenance;
+        GRANT EXECUTE ON FUNCTION drop_audit_log_partition(TEXT) TO pwmanager_maintenance;
+    END IF;
+END $$;
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS resolve_users_emergency_access ON users;
+DROP FUNCTION IF EXISTS resolve_emergency_access_invites();
+
+-- Invitations of unregistered addresses cannot be kept
+DELETE FROM emergency_access WHERE grantee_id IS NULL;
+
+DROP INDEX IF EXISTS idx_emergency_access_unresolved;
+ALTER TABLE emergency_access DROP CONSTRAINT IF EXISTS chk_emergency_access_grantee;
+ALTER TABLE emergency_access DROP CONSTRAINT IF EXISTS uq_emergency_access_grantee_email;
+ALTER TABLE emergency_access ADD CONSTRAINT uq_emergency_access_pair UNIQUE (grantor_id, grantee_id);
+ALTER TABLE emergency_access ALTER COLUMN grantee_id SET NOT NULL;
+ALTER TABLE emergency_access DROP COLUMN IF EXISTS grantee_email;
@@ -0,0 +1,31 @@
+-- Let emergency access be granted to an email address nobody has registered
+-- yet, so inviting does not reveal whether an account exists. Such an
+-- invitation has no grantee until a user registers with the address.
+ALTER TABLE emergency_access ADD COLUMN IF NOT EXISTS grantee_email VARCHAR(255);
+UPDATE emergency_access ea SET grantee_email = u.email FROM users u WHERE u.id = ea.grantee_id;
+ALTER TABLE emergency_access ALTER COLUMN grantee_email SET NOT NULL;
+ALTER TABLE emergency_access ALTER COLUMN grantee_id DROP NOT NULL;
+
+-- A grantor invites each address once; only invitations can lack a grantee
+ALTER TABLE emergency_access DROP CONSTRAINT IF EXISTS uq_emergency_access_pair;
+ALTER TABLE emergency_access ADD CONSTRAINT uq_emergency_access_grantee_email UNIQUE (grantor_id, grantee_email);
+ALTER TABLE emergency_access ADD CONSTRAINT chk_emergency_access_grantee
+    CHECK (grantee_id IS NOT NULL OR status = 'invited');
+
+CREATE INDEX idx_emergency_access_unresolved ON emergency_access(grantee_email) WHERE grantee_id IS NULL;
+
+-- Create function linking pending invitations to a new user with their address
+CREATE OR REPLACE FUNCTION resolve_emergency_access_invites()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE emergency_access
+    SET grantee_id = NEW.id, updated_at = NOW()
+    WHERE grantee_id IS NULL AND grantee_email = NEW.email;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER resolve_users_emergency_access
+    AFTER INSERT ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION resolve_emergency_access_invites();
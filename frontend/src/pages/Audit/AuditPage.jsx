@@ -57,12 +57,12 @@ export default function AuditPage() {
       const user = await getCurrentUser()
 
       // Get audit logs
-      const data = await getAuditLogs({ limit: 500 })
+      const data = await getAuditLogs({ limit: 200 })
       console.log('Loaded audit logs:', data)
       
       // Filter logs to only show current user's actions
-      const userLogs = Array.isArray(data) 
-        ? data.filter(log => log.user_id === user.id || !log.user_id)
+      const userLogs = Array.isArray(data?.items)
+        ? data.items.filter(log => log.user_id === user.id || !log.user_id)
         : []
       
       setLogs(userLogs)
@@ -1,7 +1,7 @@
 import React, { useState, useEffect, useCallback } from 'react'
 import { useParams, useNavigate } from 'react-router-dom'
-import { getVault, getVaultEntries, createVaultEntry, updateVaultEntry, deleteVaultEntry } from '../../services/api/endpoints'
-import { deriveKey, encryptData, decryptData } from '../../utils/crypto'
+import { getVault, getVaultEntries, createVaultEntry, updateVaultEntry, deleteVaultEntry, setEntrySearchTokens, setEntryAutofillHashes } from '../../services/api/endpoints'
+import { deriveKey, deriveSearchKey, deriveAutofillKey, computeSearchTokens, computeAutofillHashes, encryptData, decryptData } from '../../utils/crypto'
 import { storeMasterPassword } from '../../utils/masterPassword'
 import VaultEntryModal from '../../components/VaultEntryModal'
 
@@ -28,6 +28,8 @@ export default function VaultPage() {
   const [modalMode, setModalMode] = useState('create')
   
   const [encryptionKey, setEncryptionKey] = useState(null)
+  const [searchKey, setSearchKey] = useState(null)
+  const [autofillKey, setAutofillKey] = useState(null)
   const [showMasterPasswordPrompt, setShowMasterPasswordPrompt] = useState(true)
 
   const [clipboardCountdown, setClipboardCountdown] = useState(0)
@@ -112,7 +114,7 @@ export default function VaultPage() {
 
   // Unlock vault with master password
   async function handleUnlock(password) {
-    const key = await deriveKey(password, vault.encryption_salt)
+    const key = await deriveKey(password, vault.encryption_salt, vault.kdf_algorithm, vault.kdf_params)
     
     // Verify password by attempting to decrypt at least one entry
     // This is the Zero-Knowledge verification: decryption will fail if password is wrong
@@ -140,6 +142,12 @@ export default function VaultPage() {
     
     // Password verification passed
     setEncryptionKey(key)
+    const [derivedSearchKey, derivedAutofillKey] = await Promise.all([
+      deriveSearchKey(password, vault.encryption_salt, vault.kdf_algorithm, vault.kdf_params),
+      deriveAutofillKey(password, vault.encryption_salt, vault.kdf_algorithm, vault.kdf_params)
+    ])
+    setSearchKey(derivedSearchKey)
+    setAutofillKey(derivedAutofillKey)
     storeMasterPassword(vaultId, password)
     setShowMasterPasswordPrompt(false)
     
@@ -176,6 +184,20 @@ export default function VaultPage() {
     }
   }, [entries, encryptionKey])
 
+  // Upload blind index tokens and autofill hashes so other clients can find
+  // entries without decrypting everything
+  async function updateSearchIndex(entryId, formData) {
+    if (!searchKey || !autofillKey) {
+      return
+    }
+    try {
+      await setEntrySearchTokens(entryId, await computeSearchTokens(formData, searchKey))
+      await setEntryAutofillHashes(entryId, await computeAutofillHashes(formData.url, autofillKey))
+    } catch (err) {
+      console.error('Failed to update search index:', entryId, err)
+    }
+  }
+
   // Create entry
   async function handleCreateEntry(formData) {
     if (!encryptionKey) {
@@ -184,7 +206,8 @@ export default function VaultPage() {
 
     const { encrypted_data, nonce } = await encryptData(formData, encryptionKey)
     
-    await createVaultEntry(vaultId, { encrypted_data, nonce })
+    const created = await createVaultEntry(vaultId, { encrypted_data, nonce })
+    await updateSearchIndex(created.id, formData)
     
     // Just reload entries without re-prompting for master password
     const entriesData = await getVaultEntries(vaultId)
@@ -205,6 +228,7 @@ export default function VaultPage() {
     const { encrypted_data, nonce } = await encryptData(formData, encryptionKey)
     
     await updateVaultEntry(editingEntry.id, { encrypted_data, nonce })
+    await updateSearchIndex(editingEntry.id, formData)
     
     // Just reload entries without re-prompting for master password
     const entriesData = await getVaultEntries(vaultId)
@@ -136,10 +136,18 @@ export async function patch(endpoint, body, options = {}) {
   })
 }
 
+/**
+ * Open a Server-Sent Events stream; the session cookie is sent along
+ */
+export function eventSource(endpoint) {
+  return new EventSource(`${API_BASE_URL}${endpoint}`, { withCredentials: true })
+}
+
 export default {
   get,
   post,
   put,
   del,
   patch,
+  eventSource,
 }
@@ -4,6 +4,23 @@
 
 import * as api from './client'
 
+/**
+ * Fetch every page of a paginated list endpoint
+ * @param {string} endpoint - List endpoint
+ * @returns {Promise} Array of all items
+ */
+const getAllPages = async (endpoint) => {
+  const items = []
+  let cursor = ''
+  do {
+    const params = new URLSearchParams(cursor ? { cursor } : {})
+    const page = await api.get(`${endpoint}?${params.toString()}`)
+    items.push(...page.items)
+    cursor = page.next_cursor
+  } while (cursor)
+  return items
+}
+
 // ========================
 // Vault Endpoints
 // ========================
@@ -13,7 +30,7 @@ import * as api from './client'
  * @returns {Promise} Array of vaults
  */
 export const getVaults = () => {
-  return api.get('/vaults')
+  return getAllPages('/vaults')
 }
 
 /**
@@ -53,6 +70,26 @@ export const deleteVault = (vaultId) => {
   return api.del(`/vaults/${vaultId}`)
 }
 
+/**
+ * Rotate vault key with every entry, folder name and tag re-encrypted under the new key
+ * @param {string} vaultId - Vault ID
+ * @param {object} rotation - New encryption_salt, entries, folders, tags and optional expected_key_generation
+ * @returns {Promise} Updated vault
+ */
+export const rotateVaultKey = (vaultId, rotation) => {
+  return api.post(`/vaults/${vaultId}/rotate-key`, rotation)
+}
+
+/**
+ * Upgrade vault KDF with every entry, folder name and tag re-encrypted under the newly derived key
+ * @param {string} vaultId - Vault ID
+ * @param {object} upgrade - kdf_algorithm, kdf_params, encryption_salt, entries, folders and tags
+ * @returns {Promise} Updated vault
+ */
+export const upgradeVaultKDF = (vaultId, upgrade) => {
+  return api.post(`/vaults/${vaultId}/kdf`, upgrade)
+}
+
 // ========================
 // Vault Entry Endpoints
 // ========================
@@ -63,7 +100,7 @@ export const deleteVault = (vaultId) => {
  * @returns {Promise} Array of vault entries
  */
 export const getVaultEntries = (vaultId) => {
-  return api.get(`/vaults/${vaultId}/entries`)
+  return getAllPages(`/vaults/${vaultId}/entries`)
 }
 
 /**
@@ -95,14 +132,243 @@ export const deleteVaultEntry = (entryId) => {
   return api.del(`/entries/${entryId}`)
 }
 
+/**
+ * Move an entry into another vault
+ * @param {string} entryId - Entry ID
+ * @param {object} transferData - target_vault_id plus encrypted_data and nonce re-encrypted for the target vault
+ * @returns {Promise} Moved entry
+ */
+export const moveVaultEntry = (entryId, transferData) => {
+  return api.post(`/entries/${entryId}/move`, transferData)
+}
+
+/**
+ * Copy an entry into another vault
+ * @param {string} entryId - Entry ID
+ * @param {object} transferData - target_vault_id plus encrypted_data and nonce re-encrypted for the target vault
+ * @returns {Promise} Created copy
+ */
+export const copyVaultEntry = (entryId, transferData) => {
+  return api.post(`/entries/${entryId}/copy`, transferData)
+}
+
+/**
+ * Replace the blind index search tokens of an entry
+ * @param {string} entryId - Entry ID
+ * @param {string[]} tokens - Hex-encoded tokens from computeSearchTokens
+ * @returns {Promise} Update response
+ */
+export const setEntrySearchTokens = (entryId, tokens) => {
+  return api.put(`/entries/${entryId}/search-tokens`, { tokens })
+}
+
+/**
+ * Search a vault by blind index tokens
+ * @param {string} vaultId - Vault ID
+ * @param {string[]} tokens - Hex-encoded tokens from computeQueryTokens
+ * @param {string} match - "all" or "any"
+ * @returns {Promise} Results with entry_id and matches
+ */
+export const searchVault = (vaultId, tokens, match = 'all') => {
+  return api.post(`/vaults/${vaultId}/search`, { tokens, match })
+}
+
+/**
+ * Replace the autofill domain hashes of an entry
+ * @param {string} entryId - Entry ID
+ * @param {string[]} domainHashes - Hex-encoded hashes from computeAutofillHashes
+ * @returns {Promise} Update response
+ */
+export const setEntryAutofillHashes = (entryId, domainHashes) => {
+  return api.put(`/entries/${entryId}/autofill`, { domain_hashes: domainHashes })
+}
+
+// ========================
+// Share Link Endpoints
+// ========================
+
+/**
+ * Get the current user's share links
+ * @returns {Promise} Array of share links
+ */
+export const getShares = () => {
+  return api.get('/shares')
+}
+
+/**
+ * Create a share link from data encrypted with encryptShare
+ * @param {object} shareData - encrypted_data, nonce, expires_in_hours, optional max_views and password
+ * @returns {Promise} Created share; build the link as /send/{id}#{key}
+ */
+export const createShare = (shareData) => {
+  return api.post('/shares', shareData)
+}
+
+/**
+ * Revoke a share link
+ * @param {string} shareId - Share ID
+ * @returns {Promise} Revoke response
+ */
+export const revokeShare = (shareId) => {
+  return api.del(`/shares/${shareId}`)
+}
+
+/**
+ * Check whether a share link is available and needs a password
+ * @param {string} shareId - Share ID
+ * @returns {Promise} has_password and expires_at
+ */
+export const getShareInfo = (shareId) => {
+  return api.get(`/public/shares/${shareId}`)
+}
+
+/**
+ * Retrieve share link content; uses up one view
+ * @param {string} shareId - Share ID
+ * @param {string} password - Access password, if the share has one
+ * @returns {Promise} Hex ciphertext and nonce for decryptShare
+ */
+export const accessShare = (shareId, password) => {
+  return api.post(`/public/shares/${shareId}`, password ? { password } : {})
+}
+
+// ========================
+// Emergency Access Endpoints
+// ========================
+
+/**
+ * Get the trusted contacts the current user has granted emergency access
+ * @returns {Promise} Array of grants, including pending recovery requests
+ */
+export const getGrantedEmergencyAccess = () => {
+  return api.get('/emergency-access/granted')
+}
+
+/**
+ * Get the users who named the current user as trusted contact
+ * @returns {Promise} Array of grants
+ */
+export const getTrustedEmergencyAccess = () => {
+  return api.get('/emergency-access/trusted')
+}
+
+/**
+ * Invite a trusted contact
+ * @param {object} inviteData - grantee_email, wait_days and hex wrapped_key
+ * @returns {Promise} Created grant
+ */
+export const inviteEmergencyContact = (inviteData) => {
+  return api.post('/emergency-access', inviteData)
+}
+
+/**
+ * Change wait period or wrapped key of a grant (grantor)
+ * @param {string} grantId - Emergency access ID
+ * @param {object} updateData - wait_days and/or wrapped_key
+ * @returns {Promise} Updated grant
+ */
+export const updateEmergencyAccess = (grantId, updateData) => {
+  return api.put(`/emergency-access/${grantId}`, updateData)
+}
+
+/**
+ * Revoke (grantor) or decline (grantee) a grant
+ * @param {string} grantId - Emergency access ID
+ * @returns {Promise} Delete response
+ */
+export const revokeEmergencyAccess = (grantId) => {
+  return api.del(`/emergency-access/${grantId}`)
+}
+
+/**
+ * Apply a state transition to a grant
+ * @param {string} grantId - Emergency access ID
+ * @param {string} transition - accept or request (grantee), approve or reject (grantor)
+ * @returns {Promise} Updated grant
+ */
+export const transitionEmergencyAccess = (grantId, transition) => {
+  return api.post(`/emergency-access/${grantId}/${transition}`, {})
+}
+
+/**
+ * Get the released wrapped key (grantee, after approval)
+ * @param {string} grantId - Emergency access ID
+ * @returns {Promise} Hex wrapped_key
+ */
+export const getEmergencyAccessKey = (grantId) => {
+  return api.get(`/emergency-access/${grantId}/key`)
+}
+
+/**
+ * Get all of the grantor's vaults (grantee, after approval)
+ * @param {string} grantId - Emergency access ID
+ * @returns {Promise} Array of vaults
+ */
+export const getEmergencyAccessVaults = (grantId) => {
+  return getAllPages(`/emergency-access/${grantId}/vaults`)
+}
+
+/**
+ * Get all entries of one of the grantor's vaults (grantee, after approval)
+ * @param {string} grantId - Emergency access ID
+ * @param {string} vaultId - Vault ID
+ * @returns {Promise} Array of encrypted entries
+ */
+export const getEmergencyAccessEntries = (grantId, vaultId) => {
+  return getAllPages(`/emergency-access/${grantId}/vaults/${vaultId}/entries`)
+}
+
+// ========================
+// API Token Endpoints
+// ========================
+
+/**
+ * Get the current user's API tokens
+ * @returns {Promise} Array of tokens without secrets
+ */
+export const getAPITokens = () => {
+  return api.get('/tokens')
+}
+
+/**
+ * Create an API token, e.g. for the browser extension
+ * @param {object} tokenData - Name, scopes and optional expires_in_days
+ * @returns {Promise} Created token including the secret, shown only once
+ */
+export const createAPIToken = (tokenData) => {
+  return api.post('/tokens', tokenData)
+}
+
+/**
+ * Revoke an API token
+ * @param {string} tokenId - Token ID
+ * @returns {Promise} Revoke response
+ */
+export const revokeAPIToken = (tokenId) => {
+  return api.del(`/tokens/${tokenId}`)
+}
+
+// ========================
+// Usage Endpoints
+// ========================
+
+/**
+ * Get storage usage and quota limits of the current user
+ * @returns {Promise} Usage with vaults, storage_bytes (entries, revisions and attachments) and per-vault entry counts
+ */
+export const getUsage = () => {
+  return api.get('/usage')
+}
+
 // ========================
 // Audit Log Endpoints
 // ========================
 
 /**
- * Get audit logs
- * @param {object} options - Query options (page, limit, filter, etc.)
- * @returns {Promise} Audit logs
+ * Get a page of audit logs
+ * @param {object} options - Query options (cursor, limit, sort, order) and filters
+ *   (action, e.g. 'vault.*' or 'entry.created,entry.updated'; from, to as RFC 3339; vault_id, entry_id, ip)
+ * @returns {Promise} Page with items, next_cursor and total
  */
 export const getAuditLogs = (options = {}) => {
   const params = new URLSearchParams(options)
@@ -118,6 +384,62 @@ export const getAuditLogDetails = (logId) => {
   return api.get(`/audit/logs/${logId}`)
 }
 
+/**
+ * Stream the current user's new audit events, e.g. logins and failed logins.
+ * The browser reconnects automatically and resumes after the last event received.
+ * @param {function} onEvent - Called with each audit log
+ * @returns {EventSource} Stream; call close() to stop
+ */
+export const streamAuditEvents = (onEvent) => {
+  const source = api.eventSource('/audit/stream')
+  source.addEventListener('audit', (event) => onEvent(JSON.parse(event.data)))
+  return source
+}
+
+/**
+ * Get the JSON Schema of audit logs, with the details of each action under $defs
+ * @returns {Promise} JSON Schema (draft 2020-12)
+ */
+export const getAuditEventSchema = () => {
+  return api.get('/audit/schema')
+}
+
+/**
+ * Get the asynchronous audit writer metrics (admin only)
+ * @returns {Promise} Queue depth and written, spilled and dropped event counts
+ */
+export const getAuditWriterMetrics = () => {
+  return api.get('/admin/audit/metrics')
+}
+
+/**
+ * Export the current user's audit logs
+ * @param {object} options - format ('csv', 'ndjson' or 'cef') and the same filters as getAuditLogs
+ * @returns {Promise} Exported file contents as text
+ */
+export const exportAuditLogs = (options = {}) => {
+  const params = new URLSearchParams(options)
+  return api.get(`/audit/export?${params.toString()}`)
+}
+
+/**
+ * Export the audit logs of all users (admin only)
+ * @param {object} options - format ('csv', 'ndjson' or 'cef') and the same filters as getAuditLogs
+ * @returns {Promise} Exported file contents as text
+ */
+export const exportAllAuditLogs = (options = {}) => {
+  const params = new URLSearchParams(options)
+  return api.get(`/admin/audit/export?${params.toString()}`)
+}
+
+/**
+ * Verify the audit hash chain and signed checkpoints (admin only)
+ * @returns {Promise} Verification report with valid flag and first_break
+ */
+export const verifyAuditChain = () => {
+  return api.get('/admin/audit/verify')
+}
+
 // ========================
 // MFA Endpoints
 // ========================
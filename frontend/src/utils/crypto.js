@@ -7,9 +7,15 @@
  * Derive encryption key from master password and vault salt
  * @param {string} masterPassword - User's master password
  * @param {string} saltHex - Vault encryption salt (hex string)
+ * @param {string} kdfAlgorithm - Vault kdf_algorithm (only pbkdf2-sha256 is supported in the browser)
+ * @param {object} kdfParams - Vault kdf_params
  * @returns {Promise<CryptoKey>} Derived encryption key
  */
-export async function deriveKey(masterPassword, saltHex) {
+export async function deriveKey(masterPassword, saltHex, kdfAlgorithm = 'pbkdf2-sha256', kdfParams = { iterations: 600000 }) {
+  if (kdfAlgorithm !== 'pbkdf2-sha256') {
+    throw new Error(`Unsupported KDF: ${kdfAlgorithm}`)
+  }
+
   const encoder = new TextEncoder()
   const saltBytes = hexToBytes(saltHex)
   
@@ -27,7 +33,7 @@ export async function deriveKey(masterPassword, saltHex) {
     {
       name: 'PBKDF2',
       salt: saltBytes,
-      iterations: 600000,
+      iterations: kdfParams.iterations,
       hash: 'SHA-256'
     },
     keyMaterial,
@@ -89,6 +95,246 @@ export async function decryptData(encryptedHex, nonceHex, key) {
   }
 }
 
+/**
+ * Derive the blind index search key from master password and vault salt.
+ * The salt is domain-separated so the search key is independent of the
+ * encryption key; it never leaves the client.
+ * @param {string} masterPassword - User's master password
+ * @param {string} saltHex - Vault encryption salt (hex string)
+ * @param {string} kdfAlgorithm - Vault kdf_algorithm (only pbkdf2-sha256 is supported in the browser)
+ * @param {object} kdfParams - Vault kdf_params
+ * @returns {Promise<CryptoKey>} HMAC-SHA256 search key
+ */
+export async function deriveSearchKey(masterPassword, saltHex, kdfAlgorithm = 'pbkdf2-sha256', kdfParams = { iterations: 600000 }) {
+  return await deriveHmacKey(masterPassword, saltHex, 'pwmanager-search', kdfAlgorithm, kdfParams)
+}
+
+/**
+ * Derive the autofill key from master password and vault salt. The browser
+ * extension derives the same key to hash the site it is looking up.
+ * @param {string} masterPassword - User's master password
+ * @param {string} saltHex - Vault encryption salt (hex string)
+ * @param {string} kdfAlgorithm - Vault kdf_algorithm (only pbkdf2-sha256 is supported in the browser)
+ * @param {object} kdfParams - Vault kdf_params
+ * @returns {Promise<CryptoKey>} HMAC-SHA256 autofill key
+ */
+export async function deriveAutofillKey(masterPassword, saltHex, kdfAlgorithm = 'pbkdf2-sha256', kdfParams = { iterations: 600000 }) {
+  return await deriveHmacKey(masterPassword, saltHex, 'pwmanager-autofill', kdfAlgorithm, kdfParams)
+}
+
+/**
+ * Derive an HMAC-SHA256 key with the vault salt extended by a purpose label
+ */
+async function deriveHmacKey(masterPassword, saltHex, label, kdfAlgorithm, kdfParams) {
+  if (kdfAlgorithm !== 'pbkdf2-sha256') {
+    throw new Error(`Unsupported KDF: ${kdfAlgorithm}`)
+  }
+
+  const encoder = new TextEncoder()
+  const saltBytes = hexToBytes(saltHex)
+  const labelBytes = encoder.encode(label)
+  const hmacSalt = new Uint8Array(saltBytes.length + labelBytes.length)
+  hmacSalt.set(saltBytes)
+  hmacSalt.set(labelBytes, saltBytes.length)
+
+  const keyMaterial = await window.crypto.subtle.importKey(
+    'raw',
+    encoder.encode(masterPassword),
+    'PBKDF2',
+    false,
+    ['deriveKey']
+  )
+
+  return await window.crypto.subtle.deriveKey(
+    {
+      name: 'PBKDF2',
+      salt: hmacSalt,
+      iterations: kdfParams.iterations,
+      hash: 'SHA-256'
+    },
+    keyMaterial,
+    { name: 'HMAC', hash: 'SHA-256', length: 256 },
+    false,
+    ['sign']
+  )
+}
+
+/**
+ * Compute blind index tokens for an entry: the normalised domain, username
+ * words and title trigrams, each as HMAC-SHA256(searchKey, field:value)
+ * @param {object} data - Plain entry data (title, username, url)
+ * @param {CryptoKey} searchKey - Search key from deriveSearchKey
+ * @returns {Promise<string[]>} Hex-encoded tokens
+ */
+export async function computeSearchTokens(data, searchKey) {
+  const terms = new Set()
+
+  const domain = normaliseDomain(data.url)
+  if (domain) {
+    terms.add(`d:${domain}`)
+  }
+  for (const word of splitWords(data.username)) {
+    terms.add(`u:${word}`)
+  }
+  for (const trigram of trigrams(data.title)) {
+    terms.add(`t:${trigram}`)
+  }
+
+  return await hmacTerms([...terms], searchKey)
+}
+
+/**
+ * Compute the tokens for a search query. Every query trigram must match a
+ * title trigram; use match "all" when searching with these tokens.
+ * @param {string} query - Search text
+ * @param {CryptoKey} searchKey - Search key from deriveSearchKey
+ * @returns {Promise<string[]>} Hex-encoded tokens
+ */
+export async function computeQueryTokens(query, searchKey) {
+  return await hmacTerms(trigrams(query).map(t => `t:${t}`), searchKey)
+}
+
+/**
+ * Compute the autofill hash of a URL: HMAC-SHA256(autofillKey, eTLD+1)
+ * @param {string} url - Site or entry URL
+ * @param {CryptoKey} autofillKey - Key from deriveAutofillKey
+ * @returns {Promise<string[]>} Hex-encoded hash, or an empty list for invalid URLs
+ */
+export async function computeAutofillHashes(url, autofillKey) {
+  const domain = registrableDomain(url)
+  return domain ? await hmacTerms([domain], autofillKey) : []
+}
+
+/**
+ * HMAC each term with the given key
+ */
+async function hmacTerms(terms, searchKey) {
+  const encoder = new TextEncoder()
+  const tokens = []
+  for (const term of terms) {
+    const mac = await window.crypto.subtle.sign('HMAC', searchKey, encoder.encode(term))
+    tokens.push(bytesToHex(new Uint8Array(mac)))
+  }
+  return tokens
+}
+
+/**
+ * Reduce a URL to its lower-case host without a leading "www."
+ */
+function normaliseDomain(url) {
+  if (!url) {
+    return null
+  }
+  try {
+    return new URL(url).hostname.toLowerCase().replace(/^www\./, '')
+  } catch {
+    return null
+  }
+}
+
+// Public suffixes with two labels that are common enough to matter for autofill.
+// A full public suffix list is not bundled; other hosts fall back to their last two labels.
+const MULTI_LABEL_SUFFIXES = new Set([
+  'co.uk', 'org.uk', 'ac.uk', 'gov.uk', 'co.jp', 'ne.jp', 'or.jp',
+  'com.au', 'net.au', 'org.au', 'co.nz', 'com.br', 'com.cn', 'com.mx',
+  'co.at', 'or.at', 'co.za', 'com.tr', 'co.in', 'co.kr'
+])
+
+/**
+ * Reduce a URL to its registrable domain (eTLD+1); IP addresses are kept whole
+ */
+function registrableDomain(url) {
+  const host = normaliseDomain(url)
+  if (!host) {
+    return null
+  }
+  if (/^[\d.]+$/.test(host) || host.includes(':')) {
+    return host
+  }
+  const labels = host.split('.')
+  const suffixLength = labels.length > 2 && MULTI_LABEL_SUFFIXES.has(labels.slice(-2).join('.')) ? 3 : 2
+  return labels.slice(-suffixLength).join('.')
+}
+
+/**
+ * Split text into lower-case alphanumeric words
+ */
+function splitWords(text) {
+  return (text || '').toLowerCase().split(/[^\p{L}\p{N}]+/u).filter(Boolean)
+}
+
+/**
+ * Unique trigrams of each word; words shorter than three characters are kept whole
+ */
+function trigrams(text) {
+  const result = new Set()
+  for (const word of splitWords(text)) {
+    if (word.length < 3) {
+      result.add(word)
+      continue
+    }
+    for (let i = 0; i + 3 <= word.length; i++) {
+      result.add(word.slice(i, i + 3))
+    }
+  }
+  return [...result]
+}
+
+/**
+ * Encrypt data for a share link with a fresh random key. The key is meant for
+ * the URL fragment (#...) and must never be sent to the server.
+ * @param {object} data - Plain data object to share
+ * @returns {Promise<{encrypted_data: string, nonce: string, key: string}>} Hex ciphertext and nonce, base64url key
+ */
+export async function encryptShare(data) {
+  const key = await window.crypto.subtle.generateKey(
+    { name: 'AES-GCM', length: 256 },
+    true,
+    ['encrypt', 'decrypt']
+  )
+  const rawKey = new Uint8Array(await window.crypto.subtle.exportKey('raw', key))
+
+  const { encrypted_data, nonce } = await encryptData(data, key)
+  return { encrypted_data, nonce, key: bytesToBase64Url(rawKey) }
+}
+
+/**
+ * Decrypt share link content with the key from the URL fragment
+ * @param {string} encryptedHex - Hex-encoded encrypted data
+ * @param {string} nonceHex - Hex-encoded nonce (12 bytes)
+ * @param {string} keyBase64Url - Key from the URL fragment
+ * @returns {Promise<object>} Decrypted data object
+ */
+export async function decryptShare(encryptedHex, nonceHex, keyBase64Url) {
+  const key = await window.crypto.subtle.importKey(
+    'raw',
+    base64UrlToBytes(keyBase64Url),
+    { name: 'AES-GCM' },
+    false,
+    ['decrypt']
+  )
+  return await decryptData(encryptedHex, nonceHex, key)
+}
+
+/**
+ * Convert byte array to unpadded base64url string
+ */
+function bytesToBase64Url(bytes) {
+  return btoa(String.fromCharCode(...bytes))
+    .replace(/\+/g, '-')
+    .replace(/\//g, '_')
+    .replace(/=+$/, '')
+}
+
+/**
+ * Convert unpadded base64url string to byte array
+ */
+function base64UrlToBytes(text) {
+  const base64 = text.replace(/-/g, '+').replace(/_/g, '/')
+  const binary = atob(base64 + '='.repeat((4 - base64.length % 4) % 4))
+  return Uint8Array.from(binary, c => c.charCodeAt(0))
+}
+
 /**
  * Convert hex string to byte array
  */
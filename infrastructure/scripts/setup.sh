@@ -8,6 +8,9 @@ echo "Generating unique secrets..."
 PG_PASS=$(openssl rand -base64 48 | tr -dc 'a-zA-Z0-9' | head -c 32)
 SESS_SEC=$(openssl rand -hex 32)
 MEK=$(openssl rand -hex 32)
+AUDIT_KEY=$(openssl rand -hex 32)
+APP_DB_PASS=$(openssl rand -base64 48 | tr -dc 'a-zA-Z0-9' | head -c 32)
+MAINT_DB_PASS=$(openssl rand -base64 48 | tr -dc 'a-zA-Z0-9' | head -c 32)
 
 echo "Applying manifests..."
 
@@ -31,6 +34,9 @@ kubectl create secret tls pwmanager-tls \
 sed -e "s/POSTGRES_PASSWORD_PLACEHOLDER/$PG_PASS/g" \
     -e "s/SESSION_SECRET_PLACEHOLDER/$SESS_SEC/g" \
     -e "s/MASTER_ENCRYPTION_KEY_PLACEHOLDER/$MEK/g" \
+    -e "s/AUDIT_SIGNING_KEY_PLACEHOLDER/$AUDIT_KEY/g" \
+    -e "s/APP_DB_PASSWORD_PLACEHOLDER/$APP_DB_PASS/g" \
+    -e "s/MAINTENANCE_DB_PASSWORD_PLACEHOLDER/$MAINT_DB_PASS/g" \
     infrastructure/01-config.yaml | kubectl apply -f -
 
 # Apply data layer (Postgres, Redis)